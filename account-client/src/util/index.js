@@ -6,10 +6,12 @@ import { ref, onMounted } from 'vue'
 export const doRequest = async (requestOptions) => {
   let error
   let data
+  let headers
 
   try {
     const response = await axios.request(requestOptions)
     data = response.data
+    headers = response.headers
   } catch (err) {
     if (err.response) {
       error = err.response.data.error
@@ -23,6 +25,7 @@ export const doRequest = async (requestOptions) => {
   return {
     data,
     error,
+    headers,
   }
 }
 
@@ -30,23 +33,39 @@ export const useRequest = (requestOptions, options) => {
   const { execOnMounted } = options || {}
   const error = ref(null)
   const data = ref(null)
+  const headers = ref(null)
   const loading = ref(false)
 
-  const exec = async (requestData) => {
+  // requestHeaders are added to headers of this request only, like If-Match
+  const exec = async (requestData, requestHeaders) => {
     data.value = null
+    headers.value = null
     loading.value = true
     error.value = null
 
+    let options = requestOptions
+
     if (requestData) {
-      requestOptions = {
-        ...requestOptions,
+      options = {
+        ...options,
         data: requestData,
       }
     }
 
-    const response = await doRequest(requestOptions)
+    if (requestHeaders) {
+      options = {
+        ...options,
+        headers: {
+          ...options.headers,
+          ...requestHeaders,
+        },
+      }
+    }
+
+    const response = await doRequest(options)
 
     data.value = response.data
+    headers.value = response.headers
     error.value = response.error
     loading.value = false
   }
@@ -61,6 +80,7 @@ export const useRequest = (requestOptions, options) => {
     exec,
     error,
     data,
+    headers,
     loading,
   }
 }
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"memorize/inject"
+	"memorize/models"
+	"os"
+	"sort"
+	"strings"
+)
+
+// a subcommand of the account binary
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"migrate": {
+		usage: migrateUsage,
+		run:   migrate,
+	},
+	"user": {
+		usage: userUsage,
+		run:   user,
+	},
+	"sessions": {
+		usage: sessionsUsage,
+		run:   sessions,
+	},
+	"keys": {
+		usage: keysUsage,
+		run:   keys,
+	},
+	"token": {
+		usage: tokenUsage,
+		run:   token,
+	},
+	"client": {
+		usage: clientUsage,
+		run:   client,
+	},
+}
+
+// connected data sources and services used by commands
+type environment struct {
+	dataSources  *inject.DataSources
+	repositories *inject.Repositories
+	services     *inject.Services
+}
+
+// connect data sources and inject them the same way the server does
+func initEnvironment() (*environment, error) {
+	dataSources, err := inject.InitDataSources()
+	if err != nil {
+		return nil, err
+	}
+
+	repositories := inject.InitRepositories(dataSources)
+
+	services, err := inject.InitServices(repositories)
+	if err != nil {
+		dataSources.Close()
+		return nil, err
+	}
+
+	return &environment{
+		dataSources:  dataSources,
+		repositories: repositories,
+		services:     services,
+	}, nil
+}
+
+func (e *environment) Close() {
+	if err := e.dataSources.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to close data sources: %v\n", err)
+	}
+}
+
+// find user by login, commands address users by login
+func (e *environment) findUser(ctx context.Context, login string) (*models.User, error) {
+	return e.repositories.UserRepository.FindByLogin(ctx, login)
+}
+
+// password from args or from first line of stdin so it stays out of shell history
+func readPassword(args []string, i int) (string, error) {
+	var password string
+
+	if len(args) > i {
+		password = args[i]
+	} else {
+		fmt.Fprint(os.Stderr, "Password: ")
+
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("could not read password: %w", err)
+		}
+
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	// same limits as signup request
+	if len(password) < 6 || len(password) > 30 {
+		return "", fmt.Errorf("password must be between 6 and 30 characters")
+	}
+
+	return password, nil
+}
+
+// Run executes subcommand given in args, args do not include program name
+func Run(args []string) error {
+	if len(args) == 0 {
+		printUsage()
+		return fmt.Errorf("no command given")
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		printUsage()
+		return fmt.Errorf("unknown command: %v", args[0])
+	}
+
+	return cmd.run(args[1:])
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  account                 start the server")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  account %v\n", commands[name].usage)
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"memorize/models"
+	"strings"
+)
+
+const clientUsage = "client create -name NAME (-redirect-uri URI... [-public] | -service -audience AUDIENCE...) | client list | client delete CLIENT_ID"
+
+// stringList collects repeated flag values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// register OpenID Connect clients
+func client(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: %v", clientUsage)
+	}
+
+	env, err := initEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "create":
+		var redirectURIs, audiences stringList
+
+		flags := flag.NewFlagSet("client create", flag.ContinueOnError)
+		name := flags.String("name", "", "client name")
+		public := flags.Bool("public", false, "client without secret, must use PKCE")
+		service := flags.Bool("service", false, "backend service using client credentials grant")
+		flags.Var(&redirectURIs, "redirect-uri", "allowed redirect uri, can be repeated")
+		flags.Var(&audiences, "audience", "audience service may request tokens for, can be repeated")
+
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		newClient := &models.OAuthClient{
+			Name:         *name,
+			RedirectURIs: redirectURIs,
+			Service:      *service,
+			Audiences:    audiences,
+		}
+
+		secret, err := env.services.OAuthService.RegisterClient(ctx, newClient, !*public)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("client_id:     %v\n", newClient.ClientID)
+		if secret != "" {
+			fmt.Printf("client_secret: %v\n", secret)
+			fmt.Println("Store the secret now, it cannot be shown again")
+		}
+
+	case "list":
+		clients, err := env.services.OAuthService.ListClients(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range clients {
+			kind := "confidential"
+			targets := c.RedirectURIs
+
+			if c.Public() {
+				kind = "public"
+			}
+
+			if c.Service {
+				kind = "service"
+				targets = c.Audiences
+			}
+
+			fmt.Printf("%v  %-12v %v  %v\n", c.ClientID, kind, c.Name, strings.Join(targets, " "))
+		}
+
+	case "delete":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: %v", clientUsage)
+		}
+
+		if err := env.services.OAuthService.DeleteClient(ctx, args[1]); err != nil {
+			return err
+		}
+
+		fmt.Printf("Client %v deleted\n", args[1])
+
+	default:
+		return fmt.Errorf("unknown client command: %v", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io/ioutil"
+)
+
+const keysUsage = "keys generate [-bits 2048] -private FILE -public FILE"
+
+// generate RSA key pair used to sign access tokens
+func keys(args []string) error {
+	if len(args) < 1 || args[0] != "generate" {
+		return fmt.Errorf("usage: %v", keysUsage)
+	}
+
+	flags := flag.NewFlagSet("keys generate", flag.ContinueOnError)
+	bits := flags.Int("bits", 2048, "key size in bits")
+	privateKeyFile := flags.String("private", "", "private key pem file")
+	publicKeyFile := flags.String("public", "", "public key pem file")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *privateKeyFile == "" || *publicKeyFile == "" {
+		return fmt.Errorf("usage: %v", keysUsage)
+	}
+
+	privatePEM, publicPEM, err := generateKeyPair(*bits)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(*privateKeyFile, privatePEM, 0600); err != nil {
+		return fmt.Errorf("could not write private key: %w", err)
+	}
+
+	if err := ioutil.WriteFile(*publicKeyFile, publicPEM, 0644); err != nil {
+		return fmt.Errorf("could not write public key: %w", err)
+	}
+
+	fmt.Printf("Created %v and %v\n", *privateKeyFile, *publicKeyFile)
+	return nil
+}
+
+// returns PKCS#8 private key and PKIX public key, same formats openssl genpkey produces
+func generateKeyPair(bits int) ([]byte, []byte, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not generate key: %w", err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not encode private key: %w", err)
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not encode public key: %w", err)
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	return privatePEM, publicPEM, nil
+}
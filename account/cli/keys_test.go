@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKeyPair(test *testing.T) {
+	privatePEM, publicPEM, err := generateKeyPair(2048)
+	assert.NoError(test, err)
+
+	// keys must be readable the same way inject reads them
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+	assert.NoError(test, err)
+
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+	assert.NoError(test, err)
+
+	assert.True(test, privateKey.PublicKey.Equal(publicKey))
+	assert.Equal(test, 2048, privateKey.N.BitLen())
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"memorize/inject"
+	"memorize/migrations"
+	"strconv"
+)
+
+const migrateUsage = "migrate up|down [N]|status|to N"
+
+// manage database schema with embedded migrations
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %v", migrateUsage)
+	}
+
+	db, err := inject.InitPostgres()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %v", args[1])
+			}
+		}
+
+		return migrator.Down(ctx, steps)
+
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: migrate to N")
+		}
+
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version: %v", args[1])
+		}
+
+		return migrator.To(ctx, uint(version))
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		printStatus(status)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate command: %v", args[0])
+	}
+}
+
+func printStatus(status *migrations.Status) {
+	fmt.Printf("version: %v, dirty: %v\n", status.Version, status.Dirty)
+
+	for _, migration := range status.Migrations {
+		state := "pending"
+		if migration.Applied {
+			state = "applied"
+		}
+
+		fmt.Printf("%04d  %-8v %v\n", migration.Version, state, migration.Name)
+	}
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const sessionsUsage = "sessions list LOGIN | sessions revoke LOGIN [SESSION_ID]"
+
+// list and revoke refresh tokens of a user
+func sessions(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: %v", sessionsUsage)
+	}
+
+	env, err := initEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	ctx := context.Background()
+
+	existingUser, err := env.findUser(ctx, args[1])
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		sessions, err := env.services.TokenService.ListSessions(ctx, existingUser.UID)
+		if err != nil {
+			return err
+		}
+
+		for _, session := range sessions {
+			fmt.Printf("%v  expires in %v\n", session.ID, time.Duration(session.ExpiresIn)*time.Second)
+		}
+
+	case "revoke":
+		if len(args) > 2 {
+			if err := env.services.TokenService.RevokeSession(ctx, existingUser.UID, args[2]); err != nil {
+				return err
+			}
+
+			fmt.Printf("Session %v revoked\n", args[2])
+			return nil
+		}
+
+		if err := env.services.TokenService.Signout(ctx, existingUser.UID); err != nil {
+			return err
+		}
+
+		fmt.Printf("All sessions of user %v revoked\n", args[1])
+
+	default:
+		return fmt.Errorf("unknown sessions command: %v", args[0])
+	}
+
+	return nil
+}
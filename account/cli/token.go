@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const tokenUsage = "token mint LOGIN"
+
+// mint token pair for a user without password, useful for testing
+func token(args []string) error {
+	if len(args) < 2 || args[0] != "mint" {
+		return fmt.Errorf("usage: %v", tokenUsage)
+	}
+
+	env, err := initEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	ctx := context.Background()
+
+	existingUser, err := env.findUser(ctx, args[1])
+	if err != nil {
+		return err
+	}
+
+	tokens, err := env.services.TokenService.NewPairFromUser(ctx, existingUser, "")
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(map[string]interface{}{
+		"tokens": tokens,
+	})
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"memorize/models"
+)
+
+const userUsage = "user create|reset-password LOGIN [PASSWORD] | user disable|enable LOGIN"
+
+// manage user accounts
+func user(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: %v", userUsage)
+	}
+
+	env, err := initEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	ctx := context.Background()
+	login := args[1]
+
+	if args[0] == "create" {
+		password, err := readPassword(args, 2)
+		if err != nil {
+			return err
+		}
+
+		newUser := &models.User{
+			Login:    login,
+			Password: password,
+		}
+
+		if err := env.services.UserService.Signup(ctx, newUser); err != nil {
+			return err
+		}
+
+		fmt.Printf("Created user %v with uid %v\n", newUser.Login, newUser.UID)
+		return nil
+	}
+
+	existingUser, err := env.findUser(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "reset-password":
+		password, err := readPassword(args, 2)
+		if err != nil {
+			return err
+		}
+
+		if err := env.services.UserService.UpdatePassword(ctx, existingUser.UID, password); err != nil {
+			return err
+		}
+
+		// sessions opened with the old password should not survive
+		if err := env.services.TokenService.Signout(ctx, existingUser.UID); err != nil {
+			return err
+		}
+
+		fmt.Printf("Password of user %v reset, all sessions revoked\n", login)
+
+	case "disable":
+		if err := env.services.UserService.SetDisabled(ctx, existingUser.UID, true); err != nil {
+			return err
+		}
+
+		if err := env.services.TokenService.Signout(ctx, existingUser.UID); err != nil {
+			return err
+		}
+
+		fmt.Printf("User %v disabled, all sessions revoked\n", login)
+
+	case "enable":
+		if err := env.services.UserService.SetDisabled(ctx, existingUser.UID, false); err != nil {
+			return err
+		}
+
+		fmt.Printf("User %v enabled\n", login)
+
+	default:
+		return fmt.Errorf("unknown user command: %v", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type tokensResponse struct {
+	Tokens *Tokens `json:"tokens"`
+}
+
+type signinResponse struct {
+	Tokens       *Tokens         `json:"tokens"`
+	SecondFactor json.RawMessage `json:"secondFactor"`
+}
+
+type userResponse struct {
+	User *User `json:"user"`
+}
+
+// setETag sets version of user from its entity tag, updates send it back in If-Match
+func (r *userResponse) setETag(etag string) {
+	if r.User != nil {
+		r.User.Version, _ = strconv.ParseInt(strings.Trim(etag, `"`), 10, 64)
+	}
+}
+
+// If-Match of update, zero version updates user whatever its version is
+func ifMatch(version int64) string {
+	if version == 0 {
+		return "*"
+	}
+
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// SecondFactorRequiredError is returned by Signin for users who confirm password signin with a passkey
+// Options are WebAuthn request options as JSON, pass them to navigator.credentials.get
+// and send the result to /webauthn/signin/finish
+type SecondFactorRequiredError struct {
+	Options json.RawMessage
+}
+
+func (e *SecondFactorRequiredError) Error() string {
+	return "client: passkey is required to finish signin"
+}
+
+// Details that can be changed with UpdateDetails
+type Details struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Website string `json:"website"`
+	Locale  string `json:"locale"`
+}
+
+// Signup creates user and signs it in
+func (c *Client) Signup(ctx context.Context, login string, password string) (*Tokens, error) {
+	return c.signin(ctx, "/signup", login, password)
+}
+
+// Signin signs user in with login and password
+func (c *Client) Signin(ctx context.Context, login string, password string) (*Tokens, error) {
+	return c.signin(ctx, "/signin", login, password)
+}
+
+func (c *Client) signin(ctx context.Context, path string, login string, password string) (*Tokens, error) {
+	body, err := jsonBody(map[string]string{
+		"login":    login,
+		"password": password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result signinResponse
+	if err := c.do(ctx, http.MethodPost, path, body, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.SecondFactor) > 0 && string(result.SecondFactor) != "null" {
+		return nil, &SecondFactorRequiredError{Options: result.SecondFactor}
+	}
+
+	if result.Tokens == nil {
+		return nil, fmt.Errorf("client: response of %v has no tokens", path)
+	}
+
+	if err := c.tokenStore.Save(ctx, result.Tokens); err != nil {
+		return nil, err
+	}
+
+	return result.Tokens, nil
+}
+
+// Refresh exchanges stored refresh token for new tokens, requests do it on their own when access token expires
+func (c *Client) Refresh(ctx context.Context) (*Tokens, error) {
+	tokens, err := c.tokenStore.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokens == nil {
+		return nil, ErrNotSignedIn
+	}
+
+	return c.refresh(ctx, tokens.AccessToken)
+}
+
+// Me returns signed in user, its Version is what updates need
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	var result userResponse
+	if err := c.doAuthorized(ctx, http.MethodGet, "/me", nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result.User, nil
+}
+
+// UpdateDetails replaces name, email, website and locale of signed in user, empty ones are cleared
+// version is Version of user from Me or previous update, API answers 412 when user has changed since,
+// zero overwrites user whatever its version is
+func (c *Client) UpdateDetails(ctx context.Context, details *Details, version int64) (*User, error) {
+	body, err := jsonBody(details)
+	if err != nil {
+		return nil, err
+	}
+	body.ifMatch = ifMatch(version)
+
+	var result userResponse
+	if err := c.doAuthorized(ctx, http.MethodPut, "/details", body, &result); err != nil {
+		return nil, err
+	}
+
+	return result.User, nil
+}
+
+// PatchDetails changes only details in fields, like "name", nil value clears detail
+// version is checked like by UpdateDetails
+func (c *Client) PatchDetails(ctx context.Context, fields map[string]*string, version int64) (*User, error) {
+	body, err := jsonBody(fields)
+	if err != nil {
+		return nil, err
+	}
+	body.contentType = "application/merge-patch+json"
+	body.ifMatch = ifMatch(version)
+
+	var result userResponse
+	if err := c.doAuthorized(ctx, http.MethodPatch, "/details", body, &result); err != nil {
+		return nil, err
+	}
+
+	return result.User, nil
+}
+
+// ChangeLogin renames signed in user, stored tokens are replaced by reissued ones that carry new login
+func (c *Client) ChangeLogin(ctx context.Context, login string) (*Tokens, error) {
+	tokens, err := c.tokenStore.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokens == nil {
+		return nil, ErrNotSignedIn
+	}
+
+	body, err := jsonBody(map[string]string{
+		"login":        login,
+		"refreshToken": tokens.RefreshToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result tokensResponse
+	if err := c.doAuthorized(ctx, http.MethodPut, "/login", body, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Tokens == nil {
+		return nil, fmt.Errorf("client: response of /login has no tokens")
+	}
+
+	if err := c.tokenStore.Save(ctx, result.Tokens); err != nil {
+		return nil, err
+	}
+
+	return result.Tokens, nil
+}
+
+// Signout revokes every session of signed in user and forgets stored tokens
+func (c *Client) Signout(ctx context.Context) error {
+	if err := c.doAuthorized(ctx, http.MethodPost, "/signout", nil, nil); err != nil {
+		return err
+	}
+
+	return c.tokenStore.Clear(ctx)
+}
+
+// UploadImage sends profile image of signed in user as multipart form field imageFile
+func (c *Client) UploadImage(ctx context.Context, filename string, image io.Reader) error {
+	var data bytes.Buffer
+	writer := multipart.NewWriter(&data)
+
+	part, err := writer.CreateFormFile("imageFile", filename)
+	if err != nil {
+		return fmt.Errorf("client: could not encode image: %w", err)
+	}
+
+	if _, err := io.Copy(part, image); err != nil {
+		return fmt.Errorf("client: could not read image: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("client: could not encode image: %w", err)
+	}
+
+	body := &requestBody{
+		contentType: writer.FormDataContentType(),
+		data:        data.Bytes(),
+	}
+
+	return c.doAuthorized(ctx, http.MethodPost, "/image", body, nil)
+}
+
+// DeleteImage removes profile image of signed in user
+func (c *Client) DeleteImage(ctx context.Context) error {
+	return c.doAuthorized(ctx, http.MethodDelete, "/image", nil, nil)
+}
@@ -0,0 +1,296 @@
+// Package client is a Go client for the account HTTP API.
+//
+// It is a module of its own with no dependency on the service, so other services can use it:
+//
+//	go get github.com/TheTenzou/memorize/account/client
+//
+// Client keeps tokens of the signed in user in a TokenStore, adds the access token
+// to requests that need it and refreshes it with the refresh token when the API
+// answers 401. Errors of the API are returned as *Error.
+//
+//	accounts := client.New(&client.Config{BaseURL: "http://localhost/api/account"})
+//
+//	if _, err := accounts.Signin(ctx, "alice", "password"); err != nil {
+//		if client.Status(err) == http.StatusUnauthorized {
+//			// wrong login or password
+//		}
+//	}
+//
+//	user, err := accounts.Me(ctx)
+//
+// When the API runs with SESSION_COOKIES, it moves the refresh token from the response
+// body to an HttpOnly cookie, but only for requests with an Origin header or with
+// X-Session-Cookies: true. Client sends neither, so it gets the refresh token in the
+// body and keeps it in its TokenStore.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config of client, only BaseURL is required
+type Config struct {
+	// url the API is served at, e.g. http://localhost/api/account
+	BaseURL    string
+	HTTPClient *http.Client
+	// where tokens are kept, in memory by default
+	TokenStore TokenStore
+}
+
+// Client of the account API, safe for concurrent use
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	tokenStore TokenStore
+
+	// serializes refreshes, so concurrent 401s use one refresh token exchange
+	refreshMutex sync.Mutex
+}
+
+// New creates client for API at config.BaseURL
+func New(config *Config) *Client {
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	tokenStore := config.TokenStore
+	if tokenStore == nil {
+		tokenStore = NewMemoryTokenStore()
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(config.BaseURL, "/"),
+		httpClient: httpClient,
+		tokenStore: tokenStore,
+	}
+}
+
+// Tokens returns tokens of signed in user, nil if nobody is signed in
+func (c *Client) Tokens(ctx context.Context) (*Tokens, error) {
+	return c.tokenStore.Load(ctx)
+}
+
+// body of request, built again when request is retried after refresh
+type requestBody struct {
+	contentType string
+	data        []byte
+	// entity tag resource must have for update to succeed
+	ifMatch string
+}
+
+// result that takes entity tag of response
+type etagResult interface {
+	setETag(etag string)
+}
+
+func jsonBody(value interface{}) (*requestBody, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("client: could not encode request: %w", err)
+	}
+
+	return &requestBody{contentType: "application/json", data: data}, nil
+}
+
+// do sends request without tokens and decodes response into result
+func (c *Client) do(ctx context.Context, method string, path string, body *requestBody, result interface{}) error {
+	response, err := c.send(ctx, method, path, body, "")
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return decodeResponse(response, result)
+}
+
+// doAuthorized sends request with access token, refreshes tokens once if API answers 401
+func (c *Client) doAuthorized(ctx context.Context, method string, path string, body *requestBody, result interface{}) error {
+	tokens, err := c.tokenStore.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	if tokens == nil {
+		return ErrNotSignedIn
+	}
+
+	response, err := c.send(ctx, method, path, body, tokens.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode == http.StatusUnauthorized {
+		response.Body.Close()
+
+		tokens, err = c.refresh(ctx, tokens.AccessToken)
+		if err != nil {
+			return err
+		}
+
+		response, err = c.send(ctx, method, path, body, tokens.AccessToken)
+		if err != nil {
+			return err
+		}
+	}
+	defer response.Body.Close()
+
+	return decodeResponse(response, result)
+}
+
+// refresh exchanges refresh token for new tokens
+// rejected is access token the API refused, if stored one differs another request already refreshed it
+func (c *Client) refresh(ctx context.Context, rejected string) (*Tokens, error) {
+	c.refreshMutex.Lock()
+	defer c.refreshMutex.Unlock()
+
+	tokens, err := c.tokenStore.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokens == nil {
+		return nil, ErrNotSignedIn
+	}
+
+	if tokens.AccessToken != rejected {
+		return tokens, nil
+	}
+
+	refreshed, err := c.exchangeRefreshToken(ctx, tokens.RefreshToken)
+	if err != nil {
+		// refresh token is expired or revoked, user has to sign in again
+		if Status(err) == http.StatusUnauthorized {
+			if clearErr := c.tokenStore.Clear(ctx); clearErr != nil {
+				return nil, clearErr
+			}
+		}
+
+		return nil, err
+	}
+
+	return refreshed, nil
+}
+
+func (c *Client) exchangeRefreshToken(ctx context.Context, refreshToken string) (*Tokens, error) {
+	body, err := jsonBody(map[string]string{"refreshToken": refreshToken})
+	if err != nil {
+		return nil, err
+	}
+
+	var result tokensResponse
+	if err := c.do(ctx, http.MethodPost, "/tokens", body, &result); err != nil {
+		return nil, err
+	}
+
+	if err := c.tokenStore.Save(ctx, result.Tokens); err != nil {
+		return nil, err
+	}
+
+	return result.Tokens, nil
+}
+
+func (c *Client) send(ctx context.Context, method string, path string, body *requestBody, accessToken string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body.data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("client: could not create request: %w", err)
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", body.contentType)
+
+		if body.ifMatch != "" {
+			request.Header.Set("If-Match", body.ifMatch)
+		}
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("client: %v %v failed: %w", method, path, err)
+	}
+
+	return response, nil
+}
+
+// error responses of the API
+type errorResponse struct {
+	Error *Error `json:"error"`
+}
+
+// RFC 7807 error responses of the API, code and details are extension members
+type problemResponse struct {
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail"`
+	Code       string                 `json:"code"`
+	Details    map[string]interface{} `json:"details"`
+	IncidentID string                 `json:"incidentId"`
+}
+
+// decodeResponse decodes successful response into result and error response into *Error
+func decodeResponse(response *http.Response, result interface{}) error {
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("client: could not read response: %w", err)
+	}
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		if result == nil || len(data) == 0 {
+			return nil
+		}
+
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("client: could not decode response: %w", err)
+		}
+
+		if tagged, ok := result.(etagResult); ok {
+			tagged.setETag(response.Header.Get("ETag"))
+		}
+
+		return nil
+	}
+
+	if strings.HasPrefix(response.Header.Get("Content-Type"), problemContentType) {
+		var problem problemResponse
+		if err := json.Unmarshal(data, &problem); err == nil && problem.Code != "" {
+			return &Error{
+				Status:     response.StatusCode,
+				Code:       problem.Code,
+				Message:    problem.Detail,
+				Details:    problem.Details,
+				IncidentID: problem.IncidentID,
+			}
+		}
+	}
+
+	var body errorResponse
+	if err := json.Unmarshal(data, &body); err == nil && body.Error != nil && body.Error.Code != "" {
+		body.Error.Status = response.StatusCode
+		return body.Error
+	}
+
+	// response of a proxy or a panic, not of a handler
+	return &Error{
+		Status:  response.StatusCode,
+		Message: fmt.Sprintf("client: unexpected response status %v", response.StatusCode),
+	}
+}
@@ -0,0 +1,367 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAPI accepts only current access token and issues next one on refresh
+type fakeAPI struct {
+	*httptest.Server
+	mutex        sync.Mutex
+	accessToken  string
+	refreshToken string
+	refreshes    int32
+}
+
+func newFakeAPI() *fakeAPI {
+	api := &fakeAPI{accessToken: "access-1", refreshToken: "refresh-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/signin", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+
+		if body["password"] != "password" {
+			writeJSON(w, http.StatusUnauthorized, map[string]interface{}{
+				"error": apiError("AUTHORIZATION", "INVALID_CREDENTIALS", "Invalid login and password combination"),
+			})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tokens": api.tokens()})
+	})
+	mux.HandleFunc("/tokens", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+
+		// slow refresh lets concurrent requests pile up
+		time.Sleep(20 * time.Millisecond)
+
+		api.mutex.Lock()
+		defer api.mutex.Unlock()
+
+		if body["refreshToken"] != api.refreshToken {
+			writeJSON(w, http.StatusUnauthorized, map[string]interface{}{
+				"error": apiError("AUTHORIZATION", "TOKEN_REUSED", "Invalid refresh token"),
+			})
+			return
+		}
+
+		refreshes := atomic.AddInt32(&api.refreshes, 1)
+		api.accessToken = "access-" + string(rune('1'+refreshes))
+		api.refreshToken = "refresh-" + string(rune('1'+refreshes))
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"tokens": &Tokens{AccessToken: api.accessToken, RefreshToken: api.refreshToken},
+		})
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		if !api.authorized(r) {
+			writeJSON(w, http.StatusUnauthorized, map[string]interface{}{
+				"error": apiError("AUTHORIZATION", "INVALID_TOKEN", "Provided token is invalid"),
+			})
+			return
+		}
+
+		w.Header().Set("ETag", `"3"`)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"user": &User{Login: "alice"}})
+	})
+	mux.HandleFunc("/details", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Match") != `"3"` {
+			writeJSON(w, http.StatusPreconditionFailed, map[string]interface{}{
+				"error": apiError("PRECONDITION_FAILED", "PRECONDITION_FAILED", "User has changed since it was read"),
+			})
+			return
+		}
+
+		w.Header().Set("ETag", `"4"`)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"user": &User{Login: "alice", Name: "Alice"}})
+	})
+	mux.HandleFunc("/signout", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"message": "user signed out successfully!"})
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := r.FormFile("imageFile"); err != nil || !api.authorized(r) {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	})
+
+	api.Server = httptest.NewServer(mux)
+
+	return api
+}
+
+func (a *fakeAPI) tokens() *Tokens {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return &Tokens{AccessToken: a.accessToken, RefreshToken: a.refreshToken}
+}
+
+func (a *fakeAPI) authorized(r *http.Request) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return r.Header.Get("Authorization") == "Bearer "+a.accessToken
+}
+
+// expire makes API reject current access token, like its lifetime ended
+func (a *fakeAPI) expire() {
+	a.mutex.Lock()
+	a.accessToken = "expired"
+	a.mutex.Unlock()
+}
+
+// error like the API responds with
+func apiError(errorType string, code string, message string) map[string]interface{} {
+	return map[string]interface{}{"type": errorType, "code": code, "message": message}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestClient(test *testing.T) {
+	ctx := context.Background()
+
+	test.Run("Signin and me", func(test *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		accounts := New(&Config{BaseURL: api.URL + "/"})
+
+		tokens, err := accounts.Signin(ctx, "alice", "password")
+		assert.NoError(test, err)
+		assert.Equal(test, "access-1", tokens.AccessToken)
+		assert.Equal(test, "refresh-1", tokens.RefreshToken)
+
+		user, err := accounts.Me(ctx)
+		assert.NoError(test, err)
+		assert.Equal(test, "alice", user.Login)
+	})
+
+	test.Run("Updates details of version from me", func(test *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		accounts := New(&Config{BaseURL: api.URL})
+
+		_, err := accounts.Signin(ctx, "alice", "password")
+		assert.NoError(test, err)
+
+		user, err := accounts.Me(ctx)
+		assert.NoError(test, err)
+		assert.Equal(test, int64(3), user.Version)
+
+		updated, err := accounts.UpdateDetails(ctx, &Details{Name: "Alice"}, user.Version)
+		assert.NoError(test, err)
+		assert.Equal(test, int64(4), updated.Version)
+
+		_, err = accounts.PatchDetails(ctx, map[string]*string{"name": nil}, updated.Version)
+		assert.Equal(test, http.StatusPreconditionFailed, Status(err))
+	})
+
+	test.Run("Decodes API errors", func(test *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		accounts := New(&Config{BaseURL: api.URL})
+
+		tokens, err := accounts.Signin(ctx, "alice", "wrongpassword")
+		assert.Nil(test, tokens)
+		assert.Equal(test, &Error{
+			Status:  http.StatusUnauthorized,
+			Code:    "INVALID_CREDENTIALS",
+			Message: "Invalid login and password combination",
+		}, err)
+		assert.Equal(test, http.StatusUnauthorized, Status(err))
+	})
+
+	test.Run("Decodes problem details", func(test *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", problemContentType)
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"type":     "urn:memorize:problem:login-taken",
+				"title":    "Conflict",
+				"status":   http.StatusConflict,
+				"detail":   "Login is taken",
+				"instance": r.URL.Path,
+				"code":     "LOGIN_TAKEN",
+				"details":  map[string]interface{}{"value": "alice"},
+			})
+		}))
+		defer server.Close()
+
+		_, err := New(&Config{BaseURL: server.URL}).Signup(ctx, "alice", "password")
+		assert.Equal(test, http.StatusConflict, Status(err))
+		assert.Equal(test, "LOGIN_TAKEN", err.(*Error).Code)
+		assert.Equal(test, "alice", err.(*Error).Details["value"])
+	})
+
+	test.Run("Decodes stale version problem", func(test *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", problemContentType)
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"type":   "urn:memorize:problem:precondition-failed",
+				"title":  "Precondition Failed",
+				"status": http.StatusPreconditionFailed,
+				"detail": "User has changed since it was read",
+				"code":   "PRECONDITION_FAILED",
+			})
+		}))
+		defer server.Close()
+
+		accounts := New(&Config{BaseURL: server.URL})
+		accounts.tokenStore.Save(ctx, &Tokens{AccessToken: "access", RefreshToken: "refresh"})
+
+		_, err := accounts.UpdateDetails(ctx, &Details{Name: "Alice"}, 3)
+		assert.Equal(test, http.StatusPreconditionFailed, Status(err))
+		assert.Equal(test, "PRECONDITION_FAILED", err.(*Error).Code)
+	})
+
+	test.Run("Not signed in", func(test *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		user, err := New(&Config{BaseURL: api.URL}).Me(ctx)
+		assert.Nil(test, user)
+		assert.True(test, errors.Is(err, ErrNotSignedIn))
+	})
+
+	test.Run("Refreshes expired access token once for concurrent requests", func(test *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		accounts := New(&Config{BaseURL: api.URL})
+
+		_, err := accounts.Signin(ctx, "alice", "password")
+		assert.NoError(test, err)
+
+		api.expire()
+
+		var wait sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+
+				user, err := accounts.Me(ctx)
+				assert.NoError(test, err)
+				assert.Equal(test, "alice", user.Login)
+			}()
+		}
+		wait.Wait()
+
+		assert.Equal(test, int32(1), atomic.LoadInt32(&api.refreshes))
+
+		tokens, _ := accounts.Tokens(ctx)
+		assert.Equal(test, "access-2", tokens.AccessToken)
+		assert.Equal(test, "refresh-2", tokens.RefreshToken)
+	})
+
+	test.Run("Rejected refresh token signs out", func(test *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		store := NewMemoryTokenStore()
+		store.Save(ctx, &Tokens{AccessToken: "expired", RefreshToken: "revoked"})
+
+		accounts := New(&Config{BaseURL: api.URL, TokenStore: store})
+
+		user, err := accounts.Me(ctx)
+		assert.Nil(test, user)
+		assert.Equal(test, http.StatusUnauthorized, Status(err))
+
+		tokens, _ := store.Load(ctx)
+		assert.Nil(test, tokens)
+	})
+
+	test.Run("Image and unexpected response", func(test *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		accounts := New(&Config{BaseURL: api.URL})
+		accounts.tokenStore.Save(ctx, api.tokens())
+
+		err := accounts.UploadImage(ctx, "avatar.png", strings.NewReader(""))
+		assert.NoError(test, err)
+
+		err = accounts.DeleteImage(ctx)
+		assert.Equal(test, http.StatusBadGateway, Status(err))
+		assert.Empty(test, err.(*Error).Code)
+	})
+
+	test.Run("Signout forgets tokens", func(test *testing.T) {
+		api := newFakeAPI()
+		defer api.Close()
+
+		accounts := New(&Config{BaseURL: api.URL})
+
+		_, err := accounts.Signin(ctx, "alice", "password")
+		assert.NoError(test, err)
+
+		assert.NoError(test, accounts.Signout(ctx))
+
+		tokens, _ := accounts.Tokens(ctx)
+		assert.Nil(test, tokens)
+	})
+
+	test.Run("Second factor", func(test *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"secondFactor": map[string]interface{}{
+					"publicKey": map[string]interface{}{"rpId": "localhost"},
+				},
+			})
+		}))
+		defer server.Close()
+
+		tokens, err := New(&Config{BaseURL: server.URL}).Signin(ctx, "alice", "password")
+		assert.Nil(test, tokens)
+
+		secondFactor, ok := err.(*SecondFactorRequiredError)
+		assert.True(test, ok)
+		assert.JSONEq(test, `{"publicKey": {"rpId": "localhost"}}`, string(secondFactor.Options))
+	})
+}
+
+func TestFileTokenStore(test *testing.T) {
+	ctx := context.Background()
+	store := NewFileTokenStore(filepath.Join(test.TempDir(), "tokens.json"))
+
+	tokens, err := store.Load(ctx)
+	assert.NoError(test, err)
+	assert.Nil(test, tokens)
+
+	saved := &Tokens{AccessToken: "access", RefreshToken: "refresh"}
+	assert.NoError(test, store.Save(ctx, saved))
+
+	tokens, err = store.Load(ctx)
+	assert.NoError(test, err)
+	assert.Equal(test, saved, tokens)
+
+	assert.NoError(test, store.Clear(ctx))
+
+	tokens, err = store.Load(ctx)
+	assert.NoError(test, err)
+	assert.Nil(test, tokens)
+}
@@ -0,0 +1,14 @@
+module github.com/TheTenzou/memorize/account/client
+
+go 1.19
+
+require (
+	github.com/google/uuid v1.6.0
+	github.com/stretchr/testify v1.7.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// TokenStore persists tokens of signed in user between requests, or between runs of a program
+type TokenStore interface {
+	// tokens of signed in user, nil if nobody is signed in
+	Load(ctx context.Context) (*Tokens, error)
+	// replace tokens after signin or refresh
+	Save(ctx context.Context, tokens *Tokens) error
+	// forget tokens after signout or when refresh token is rejected
+	Clear(ctx context.Context) error
+}
+
+type memoryTokenStore struct {
+	mutex  sync.RWMutex
+	tokens *Tokens
+}
+
+// NewMemoryTokenStore keeps tokens only while program runs
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{}
+}
+
+func (s *memoryTokenStore) Load(ctx context.Context) (*Tokens, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.tokens, nil
+}
+
+func (s *memoryTokenStore) Save(ctx context.Context, tokens *Tokens) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tokens = tokens
+	return nil
+}
+
+func (s *memoryTokenStore) Clear(ctx context.Context) error {
+	return s.Save(ctx, nil)
+}
+
+type fileTokenStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileTokenStore keeps tokens in JSON file readable only by its owner, e.g. for command line tools
+func NewFileTokenStore(path string) TokenStore {
+	return &fileTokenStore{
+		path: path,
+	}
+}
+
+func (s *fileTokenStore) Load(ctx context.Context) (*Tokens, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("client: could not read tokens: %w", err)
+	}
+
+	tokens := &Tokens{}
+	if err := json.Unmarshal(data, tokens); err != nil {
+		return nil, fmt.Errorf("client: could not decode tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Save writes temporary file and renames it, so a crash does not leave half written tokens
+func (s *fileTokenStore) Save(ctx context.Context, tokens *Tokens) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if tokens == nil {
+		return s.clearLocked()
+	}
+
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("client: could not encode tokens: %w", err)
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("client: could not write tokens: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("client: could not write tokens: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("client: could not write tokens: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), s.path); err != nil {
+		return fmt.Errorf("client: could not write tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (s *fileTokenStore) Clear(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.clearLocked()
+}
+
+func (s *fileTokenStore) clearLocked() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("client: could not remove tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// media type of RFC 7807 error responses
+const problemContentType = "application/problem+json"
+
+// ErrNotSignedIn is returned by requests that need tokens when TokenStore has none
+var ErrNotSignedIn = errors.New("client: not signed in")
+
+// Tokens of signed in user
+type Tokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+// User of the account service
+type User struct {
+	UID          uuid.UUID `json:"uid"`
+	Login        string    `json:"login"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	ImageURL     string    `json:"imageUrl"`
+	Website      string    `json:"website"`
+	SecondFactor bool      `json:"secondFactor"`
+	Locale       string    `json:"locale"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	// entity tag of user, updates send it back so they do not overwrite changes made since
+	Version int64 `json:"-"`
+}
+
+// Error is error response of the API
+type Error struct {
+	// HTTP status of response
+	Status int `json:"-"`
+	// stable reason of error, like LOGIN_TAKEN, empty for responses that are not of the API, like of a proxy
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// values the message is about, like name of conflicting resource
+	Details map[string]interface{} `json:"details,omitempty"`
+	// identifies internal error in logs of the service
+	IncidentID string `json:"incidentId,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Status returns HTTP status of API error in err chain, 0 for errors of other kinds
+func Status(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Status
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"memorize/controller/middleware"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authorizeRequest is login form of the authorization endpoint
+type authorizeRequest struct {
+	Login    string `json:"login" binding:"required"`
+	Password string `json:"password" binding:"required,gte=6,lte=30"`
+	models.AuthorizationRequest
+}
+
+// Authorize validates authorization request and sends user agent to login page
+func (c *controller) Authorize(ctx *gin.Context) {
+	var request models.AuthorizationRequest
+
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		respondBindError(ctx, err)
+		return
+	}
+
+	requestCtx := ctx.Request.Context()
+
+	if err := c.OAuthService.ValidateAuthorizationRequest(requestCtx, &request); err != nil {
+		redirectURL, ok := authorizationErrorRedirect(err, request.State)
+		if !ok {
+			respondOAuthError(ctx, err)
+			return
+		}
+
+		ctx.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, c.LoginURL+"?"+ctx.Request.URL.RawQuery)
+}
+
+// AuthorizeSignin signs user in and issues authorization code
+// responds with uri the login page should redirect user agent to
+func (c *controller) AuthorizeSignin(ctx *gin.Context) {
+	var request authorizeRequest
+
+	if ok := bindData(ctx, &request); !ok {
+		return
+	}
+
+	requestCtx := ctx.Request.Context()
+
+	if err := c.OAuthService.ValidateAuthorizationRequest(requestCtx, &request.AuthorizationRequest); err != nil {
+		redirectURL, ok := authorizationErrorRedirect(err, request.State)
+		if !ok {
+			respondOAuthError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"redirectUrl": redirectURL,
+		})
+		return
+	}
+
+	user, err := c.UserService.Signin(requestCtx, &models.User{
+		Login:    request.Login,
+		Password: request.Password,
+	})
+
+	if err != nil {
+		log.Printf("Failed to sign in user: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	// login page cannot run passkey ceremony, user signs in with passkey instead
+	if user.SecondFactor {
+		err := apperrors.NewAuthorization("Passkey is required to sign in").WithCode(apperrors.SecondFactorRequired)
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	redirectURL, err := c.OAuthService.Authorize(requestCtx, &request.AuthorizationRequest, user)
+
+	if err != nil {
+		log.Printf("Failed to authorize client %v: %v\n", request.ClientID, err.Error())
+		respondOAuthError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"redirectUrl": redirectURL,
+	})
+}
+
+// builds client redirect uri carrying the error, false if error must be shown to user instead
+func authorizationErrorRedirect(err error, state string) (string, bool) {
+	var oauthErr *apperrors.OAuthError
+	if !errors.As(err, &oauthErr) || oauthErr.RedirectURI == "" {
+		return "", false
+	}
+
+	redirectURL, err := service.RedirectURIWithParams(oauthErr.RedirectURI, map[string]string{
+		"error":             oauthErr.Code,
+		"error_description": oauthErr.Description,
+		"state":             state,
+	})
+
+	return redirectURL, err == nil
+}
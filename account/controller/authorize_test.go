@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthorize(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockOAuthService := new(mocks.MockOAuthService)
+
+	router := gin.Default()
+
+	NewController(&Config{
+		Router:       router,
+		OAuthService: mockOAuthService,
+		LoginURL:     "https://accounts.example.com/login",
+	})
+
+	test.Run("Redirects to login page", func(test *testing.T) {
+		query := "response_type=code&client_id=valid&state=xyz"
+
+		mockOAuthService.
+			On("ValidateAuthorizationRequest", mock.Anything, &models.AuthorizationRequest{
+				ResponseType: "code",
+				ClientID:     "valid",
+				State:        "xyz",
+			}).
+			Return(nil)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/authorize?"+query, http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusFound, recorder.Code)
+		assert.Equal(test, "https://accounts.example.com/login?"+query, recorder.Header().Get("Location"))
+	})
+
+	test.Run("Redirects error to client", func(test *testing.T) {
+		mockOAuthService.
+			On("ValidateAuthorizationRequest", mock.Anything, &models.AuthorizationRequest{
+				ResponseType: "token",
+				ClientID:     "valid",
+				State:        "xyz",
+			}).
+			Return(apperrors.NewOAuthRedirectError(
+				apperrors.UnsupportedResponseType,
+				"Only code",
+				"https://app.example.com/callback",
+			))
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/authorize?response_type=token&client_id=valid&state=xyz", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusFound, recorder.Code)
+		assert.Equal(
+			test,
+			"https://app.example.com/callback?error=unsupported_response_type&error_description=Only+code&state=xyz",
+			recorder.Header().Get("Location"),
+		)
+	})
+
+	test.Run("Unknown client is not redirected", func(test *testing.T) {
+		mockOAuthService.
+			On("ValidateAuthorizationRequest", mock.Anything, &models.AuthorizationRequest{
+				ResponseType: "code",
+				ClientID:     "unknown",
+			}).
+			Return(apperrors.NewOAuthError(apperrors.InvalidRequest, "Unknown client"))
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/authorize?response_type=code&client_id=unknown", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		assert.Empty(test, recorder.Header().Get("Location"))
+	})
+}
+
+func TestAuthorizeSignin(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockOAuthService := new(mocks.MockOAuthService)
+	mockUserService := new(mocks.MockUserService)
+
+	router := gin.Default()
+
+	NewController(&Config{
+		Router:       router,
+		UserService:  mockUserService,
+		OAuthService: mockOAuthService,
+	})
+
+	authorizationRequest := &models.AuthorizationRequest{
+		ResponseType: "code",
+		ClientID:     "valid",
+		RedirectURI:  "https://app.example.com/callback",
+		State:        "xyz",
+	}
+
+	mockOAuthService.On("ValidateAuthorizationRequest", mock.Anything, authorizationRequest).Return(nil)
+
+	newRequest := func(password string) *http.Request {
+		requestBody, _ := json.Marshal(gin.H{
+			"login":         "alice",
+			"password":      password,
+			"response_type": "code",
+			"client_id":     "valid",
+			"redirect_uri":  "https://app.example.com/callback",
+			"state":         "xyz",
+		})
+
+		request, _ := http.NewRequest(http.MethodPost, "/authorize", bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+
+		return request
+	}
+
+	test.Run("Success", func(test *testing.T) {
+		uid, _ := uuid.NewRandom()
+		user := &models.User{UID: uid, Login: "alice"}
+		redirectURL := "https://app.example.com/callback?code=abc&state=xyz"
+
+		mockUserService.
+			On("Signin", mock.Anything, &models.User{Login: "alice", Password: "rightpassword"}).
+			Return(user, nil)
+		mockOAuthService.
+			On("Authorize", mock.Anything, authorizationRequest, user).
+			Return(redirectURL, nil)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest("rightpassword"))
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"redirectUrl": redirectURL,
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+
+	test.Run("Invalid credentials", func(test *testing.T) {
+		mockError := apperrors.NewAuthorization("Invalid login and password combination").WithCode(apperrors.InvalidCredentials)
+
+		mockUserService.
+			On("Signin", mock.Anything, &models.User{Login: "alice", Password: "wrongpassword"}).
+			Return(nil, mockError)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest("wrongpassword"))
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+		mockOAuthService.AssertNotCalled(test, "Authorize", mock.Anything, authorizationRequest, nil)
+	})
+
+	test.Run("Second factor required", func(test *testing.T) {
+		uid, _ := uuid.NewRandom()
+		user := &models.User{UID: uid, Login: "alice", SecondFactor: true}
+
+		mockUserService.
+			On("Signin", mock.Anything, &models.User{Login: "alice", Password: "passkeyuser"}).
+			Return(user, nil)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest("passkeyuser"))
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+		mockOAuthService.AssertNotCalled(test, "Authorize", mock.Anything, authorizationRequest, user)
+	})
+}
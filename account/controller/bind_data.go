@@ -3,19 +3,13 @@ package controller
 import (
 	"fmt"
 	"log"
+	"memorize/controller/middleware"
 	"memorize/models/apperrors"
+	"memorize/validation"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
-type invalidArgument struct {
-	Field string `json:"field"`
-	Value string `json:"value"`
-	Tag   string `json:"tag"`
-	Param string `json:"param"`
-}
-
 // bindData return false if data is not bound
 // if not bound set response with error
 func bindData(ctx *gin.Context, request interface{}) bool {
@@ -25,17 +19,15 @@ func bindData(ctx *gin.Context, request interface{}) bool {
 
 		err := apperrors.NewUnsupportedMediaType(message)
 
-		ctx.JSON(err.Status(), gin.H{
-			"error": err,
-		})
+		middleware.RespondError(ctx, err)
 
 		return false
 	}
 
-	if err := ctx.ShouldBind(request); err != nil {
+	if err := ctx.ShouldBindJSON(request); err != nil {
 		log.Printf("Error binding data: %v\n", err)
 
-		handelError(ctx, err)
+		respondBindError(ctx, err)
 
 		return false
 	}
@@ -43,32 +35,7 @@ func bindData(ctx *gin.Context, request interface{}) bool {
 	return true
 }
 
-func handelError(ctx *gin.Context, err error) {
-	if errs, ok := err.(validator.ValidationErrors); ok {
-		var invalidArgs []invalidArgument
-
-		for _, err := range errs {
-			invalidArgs = append(invalidArgs, invalidArgument{
-				err.Field(),
-				err.Value().(string),
-				err.Tag(),
-				err.Param(),
-			})
-		}
-
-		err := apperrors.NewBadRequest("Invalid request parametrs. See invalidArgs")
-
-		ctx.JSON(err.Status(), gin.H{
-			"error":       err,
-			"invalidArgs": invalidArgs,
-		})
-
-		return
-	}
-
-	fallBack := apperrors.NewInternal()
-
-	ctx.JSON(fallBack.Status(), gin.H{
-		"error": fallBack,
-	})
+// responds with invalid fields of request, or with malformed JSON error
+func respondBindError(ctx *gin.Context, err error) {
+	middleware.RespondError(ctx, validation.Error(err, middleware.Locale(ctx)))
 }
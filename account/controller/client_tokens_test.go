@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// tokens of third-party OAuth clients go through auth middleware, which is registered outside test mode only
+func TestClientTokens(test *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice", Email: "alice@mail.com"}
+
+	mockTokenService := new(mocks.MockTokenService)
+	mockTokenService.On("ValidateAccessToken", "client").Return(user, nil)
+	mockTokenService.On("AccessTokenGrant", "client").Return(&models.ClientGrant{ClientID: "third-party", Scope: "openid"}, nil)
+
+	mockUserService := new(mocks.MockUserService)
+	mockUserService.On("GetUser", mock.Anything, uid).Return(user, nil)
+
+	router := gin.New()
+
+	NewController(&Config{
+		Router:          router,
+		TokenService:    mockTokenService,
+		UserService:     mockUserService,
+		TImeoutDuration: time.Second,
+	})
+
+	serve := func(method string, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, path, http.NoBody)
+		request.Header.Set("Authorization", "Bearer client")
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("Account security cannot be managed", func(test *testing.T) {
+		routes := []struct {
+			method string
+			path   string
+		}{
+			{http.MethodGet, "/me/tokens"},
+			{http.MethodPost, "/me/tokens"},
+			{http.MethodDelete, "/me/tokens/" + uid.String()},
+			{http.MethodPut, "/login"},
+			{http.MethodGet, "/login/availability?login=bob"},
+			{http.MethodPut, "/me/second-factor"},
+			{http.MethodPost, "/webauthn/register/begin"},
+			{http.MethodPost, "/webauthn/register/finish"},
+			{http.MethodGet, "/me/passkeys"},
+			{http.MethodDelete, "/me/passkeys/" + uid.String()},
+			{http.MethodPost, "/oauth/github/link"},
+			{http.MethodDelete, "/oauth/github/link"},
+		}
+
+		for _, route := range routes {
+			recorder := serve(route.method, route.path)
+
+			assert.Equal(test, http.StatusForbidden, recorder.Code, "%v %v", route.method, route.path)
+			assert.Contains(test, recorder.Body.String(), string(apperrors.InsufficientScope), "%v %v", route.method, route.path)
+		}
+	})
+
+	test.Run("Scopes not granted are rejected", func(test *testing.T) {
+		routes := []struct {
+			method string
+			path   string
+		}{
+			{http.MethodGet, "/me"},
+			{http.MethodGet, "/me/identities"},
+			{http.MethodPut, "/details"},
+			{http.MethodPatch, "/details"},
+			{http.MethodPost, "/signout"},
+		}
+
+		for _, route := range routes {
+			recorder := serve(route.method, route.path)
+
+			assert.Equal(test, http.StatusForbidden, recorder.Code, "%v %v", route.method, route.path)
+			assert.Contains(test, recorder.Body.String(), string(apperrors.InsufficientScope), "%v %v", route.method, route.path)
+		}
+	})
+
+	test.Run("Userinfo with openid scope", func(test *testing.T) {
+		recorder := serve(http.MethodGet, "/userinfo")
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.NotContains(test, recorder.Body.String(), "alice@mail.com")
+	})
+}
@@ -7,50 +7,245 @@ import (
 	"memorize/controller/middleware"
 	"memorize/models"
 	"memorize/models/apperrors"
+	"memorize/openapi"
+	"memorize/validation"
 
 	"github.com/gin-gonic/gin"
 )
 
+// LatestAPIVersion is served under /v2, older versions can be deprecated
+const LatestAPIVersion = 2
+
 type controller struct {
-	UserService  models.UserService
-	TokenService models.TokenService
+	UserService                models.UserService
+	TokenService               models.TokenService
+	OAuthService               models.OAuthService
+	SocialService              models.SocialService
+	PersonalAccessTokenService models.PersonalAccessTokenService
+	WebAuthnService            models.WebAuthnService
+	MagicLinkService           models.MagicLinkService
+	Issuer                     string
+	LoginURL                   string
+	SocialSigninURL            string
+	ServiceAudience            string
+	BaseURL                    string
+	SessionCookies             bool
+	CookieSameSite             http.SameSite
+	// OpenAPI documents by base path of version
+	APIDocuments map[string]*openapi.Document
 }
 
 // hold services that will eventually be injected into this handler layer on handler initialization
 type Config struct {
-	Router          *gin.Engine
-	UserService     models.UserService
-	TokenService    models.TokenService
-	BaseURL         string
-	TImeoutDuration time.Duration
+	Router                     *gin.Engine
+	UserService                models.UserService
+	TokenService               models.TokenService
+	OAuthService               models.OAuthService
+	SocialService              models.SocialService
+	PersonalAccessTokenService models.PersonalAccessTokenService
+	WebAuthnService            models.WebAuthnService
+	MagicLinkService           models.MagicLinkService
+	BaseURL                    string
+	Issuer                     string
+	LoginURL                   string
+	SocialSigninURL            string
+	ServiceAudience            string
+	TImeoutDuration            time.Duration
+	// set refresh token as HttpOnly cookie and require CSRF token from browser clients
+	SessionCookies bool
+	CookieSameSite http.SameSite
+	// CORS and security headers are set for all routes of Router when configured
+	CORS            *middleware.CORSConfig
+	SecurityHeaders *middleware.SecurityHeadersConfig
+	// respond errors as application/problem+json even to clients that did not ask for it
+	ProblemJSON bool
+	// check requests, and responses, against OpenAPI document, for contract tests
+	ValidateRequests  bool
+	ValidateResponses bool
+	// Deprecation and Sunset headers of old API versions, by version
+	Deprecations map[int]*middleware.Deprecation
 }
 
 // initializes the handler with required injected services along with http routes
 func NewController(config *Config) {
 
+	validation.Setup()
+
 	ctrl := &controller{
-		UserService:  config.UserService,
-		TokenService: config.TokenService,
+		UserService:                config.UserService,
+		TokenService:               config.TokenService,
+		OAuthService:               config.OAuthService,
+		SocialService:              config.SocialService,
+		PersonalAccessTokenService: config.PersonalAccessTokenService,
+		WebAuthnService:            config.WebAuthnService,
+		MagicLinkService:           config.MagicLinkService,
+		Issuer:                     config.Issuer,
+		LoginURL:                   config.LoginURL,
+		SocialSigninURL:            config.SocialSigninURL,
+		ServiceAudience:            config.ServiceAudience,
+		BaseURL:                    config.BaseURL,
+		SessionCookies:             config.SessionCookies,
+		CookieSameSite:             config.CookieSameSite,
+		APIDocuments:               map[string]*openapi.Document{},
+	}
+
+	if ctrl.CookieSameSite == 0 {
+		ctrl.CookieSameSite = http.SameSiteStrictMode
+	}
+
+	if config.SecurityHeaders != nil {
+		config.Router.Use(middleware.SecurityHeaders(config.SecurityHeaders))
+	}
+
+	if config.CORS != nil {
+		config.Router.Use(middleware.CORS(config.CORS))
+	}
+
+	if config.ProblemJSON {
+		config.Router.Use(middleware.ProblemJSON())
 	}
 
-	group := config.Router.Group(config.BaseURL)
+	// OpenID Connect and external provider endpoints are registered at fixed URLs, so they are not versioned
+	ctrl.protocolRoutes(config.Router.Group(config.BaseURL), config)
+
+	// unversioned routes are version 1 for clients shipped before versioning
+	versions := []struct {
+		path    string
+		version int
+	}{
+		{"", 1},
+		{"/v1", 1},
+		{"/v2", LatestAPIVersion},
+	}
+
+	for _, version := range versions {
+		basePath := config.BaseURL + version.path
+		ctrl.routes(config.Router.Group(basePath), basePath, version.version, config)
+	}
+}
+
+// middleware shared by all route groups, document describes routes of group
+func (c *controller) use(group *gin.RouterGroup, document *openapi.Document, config *Config) {
+	if c.SessionCookies {
+		group.Use(middleware.CSRF(refreshTokenCookie))
+	}
+
+	if config.ValidateRequests || config.ValidateResponses {
+		group.Use(middleware.OpenAPIValidator(document, config.ValidateRequests, config.ValidateResponses))
+	}
 
 	if gin.Mode() != gin.TestMode {
 		group.Use(middleware.Timeout(config.TImeoutDuration, apperrors.NewServiceUnavailable()))
-		group.GET("/me", middleware.AuthUser(ctrl.TokenService), ctrl.Me)
-		group.POST("/signout", middleware.AuthUser(ctrl.TokenService), ctrl.Signout)
-		group.PUT("/details", middleware.AuthUser(ctrl.TokenService), ctrl.Details)
+	}
+}
+
+// routes registers handlers of API version on group, versions share services
+// handlers that differ by version read it with middleware.Version
+func (c *controller) routes(group *gin.RouterGroup, basePath string, version int, config *Config) {
+	document := apiDocument(basePath, version)
+	if basePath == c.BaseURL {
+		// unversioned document also describes protocol endpoints
+		protocolOperations(document)
+	}
+	c.APIDocuments[basePath] = document
+
+	group.Use(middleware.APIVersion(version, basePath, config.Deprecations[version]))
+	c.use(group, document, config)
+
+	// responses with tokens must not be cached
+	noStore := middleware.NoStore()
+
+	if gin.Mode() != gin.TestMode {
+		// personal access tokens are limited by scopes and cannot manage account security
+		authUser := middleware.AuthUser(c.TokenService, c.PersonalAccessTokenService)
+		authSession := middleware.AuthSession(c.TokenService)
+		profileRead := middleware.RequireScope(models.ScopeProfileRead)
+		profileWrite := middleware.RequireScope(models.ScopeProfileWrite)
+		sessions := middleware.RequireScope(models.ScopeSessions)
+		// OAuth clients read user with userinfo, its claims are limited to their scope
+		userinfo := middleware.RequireScope(models.ScopeProfileRead, "openid")
+		authService := middleware.AuthService(c.TokenService, c.ServiceAudience)
+
+		group.GET("/me", authUser, profileRead, c.Me)
+		group.POST("/signout", authUser, sessions, c.Signout)
+		group.PUT("/details", authUser, profileWrite, c.Details)
+		group.PATCH("/details", authUser, profileWrite, c.PatchDetails)
+		group.GET("/userinfo", authUser, userinfo, c.Userinfo)
+		group.GET("/me/identities", authUser, profileRead, c.Identities)
+		group.POST("/oauth/:provider/link", authSession, c.SocialLink)
+		group.DELETE("/oauth/:provider/link", authSession, c.SocialUnlink)
+		group.GET("/me/tokens", authSession, c.PersonalAccessTokens)
+		group.POST("/me/tokens", authSession, noStore, c.CreatePersonalAccessToken)
+		group.DELETE("/me/tokens/:id", authSession, c.RevokePersonalAccessToken)
+		group.POST("/webauthn/register/begin", authSession, c.BeginPasskeyRegistration)
+		group.POST("/webauthn/register/finish", authSession, c.FinishPasskeyRegistration)
+		group.GET("/me/passkeys", authSession, c.Passkeys)
+		group.DELETE("/me/passkeys/:id", authSession, c.DeletePasskey)
+		group.PUT("/me/second-factor", authSession, c.SecondFactor)
+		group.PUT("/login", authSession, noStore, c.ChangeLogin)
+		group.GET("/login/availability", authSession, c.LoginAvailability)
+		group.GET("/users/:uid", authService, c.User)
+		group.GET("/logins/:login", authService, c.UserByLogin)
 	} else {
-		group.GET("/me", ctrl.Me)
-		group.POST("/signout", ctrl.Signout)
-		group.PUT("/details", ctrl.Details)
+		group.GET("/me", c.Me)
+		group.POST("/signout", c.Signout)
+		group.PUT("/details", c.Details)
+		group.PATCH("/details", c.PatchDetails)
+		group.GET("/userinfo", c.Userinfo)
+		group.GET("/me/identities", c.Identities)
+		group.POST("/oauth/:provider/link", c.SocialLink)
+		group.DELETE("/oauth/:provider/link", c.SocialUnlink)
+		group.GET("/me/tokens", c.PersonalAccessTokens)
+		group.POST("/me/tokens", noStore, c.CreatePersonalAccessToken)
+		group.DELETE("/me/tokens/:id", c.RevokePersonalAccessToken)
+		group.POST("/webauthn/register/begin", c.BeginPasskeyRegistration)
+		group.POST("/webauthn/register/finish", c.FinishPasskeyRegistration)
+		group.GET("/me/passkeys", c.Passkeys)
+		group.DELETE("/me/passkeys/:id", c.DeletePasskey)
+		group.PUT("/me/second-factor", c.SecondFactor)
+		group.PUT("/login", noStore, c.ChangeLogin)
+		group.GET("/login/availability", c.LoginAvailability)
+		group.GET("/users/:uid", c.User)
+		group.GET("/logins/:login", c.UserByLogin)
 	}
 
-	group.POST("/signup", ctrl.Signup)
-	group.POST("/signin", ctrl.Signin)
-	group.POST("/tokens", ctrl.Tokens)
-	group.POST("/image", ctrl.Image)
-	group.DELETE("/image", ctrl.DeleteImage)
+	group.POST("/signup", noStore, c.Signup)
+	group.POST("/signin", noStore, c.Signin)
+	group.POST("/signin/link", c.MagicLink)
+	group.POST("/signin/link/verify", noStore, c.VerifyMagicLink)
+	group.POST("/signin/social", noStore, c.SocialSignin)
+	group.POST("/tokens", noStore, c.Tokens)
+	group.POST("/image", c.Image)
+	group.DELETE("/image", c.DeleteImage)
+
+	// passkeys
+	group.POST("/webauthn/signin/begin", c.BeginPasskeySignin)
+	group.POST("/webauthn/signin/finish", noStore, c.FinishPasskeySignin)
+
+	// API documentation
+	group.GET("/openapi.json", c.OpenAPI)
+	group.Group("/docs", middleware.ContentSecurityPolicy(docsContentSecurityPolicy)).StaticFS("/", docsFileSystem())
+}
+
+// protocolRoutes registers endpoints defined by OAuth and OpenID Connect, and sign in with external providers
+func (c *controller) protocolRoutes(group *gin.RouterGroup, config *Config) {
+	c.use(group, protocolDocument(c.BaseURL), config)
+
+	noStore := middleware.NoStore()
+
+	// OpenID Connect provider
+	group.GET("/.well-known/openid-configuration", c.OpenIDConfiguration)
+	group.GET("/jwks", c.JWKS)
+	group.GET("/authorize", c.Authorize)
+	group.POST("/authorize", c.AuthorizeSignin)
+	group.POST("/token", noStore, c.OAuthToken)
+	group.POST("/introspect", noStore, c.Introspect)
+	group.POST("/revoke", c.Revoke)
+
+	// sign in with external identity providers
+	group.GET("/oauth", c.SocialProviders)
+	group.GET("/oauth/:provider/start", c.SocialStart)
+	group.GET("/oauth/:provider/callback", noStore, c.SocialCallback)
 }
 
 func (c *controller) Image(ctx *gin.Context) {
@@ -1,23 +1,38 @@
 package controller
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
+	"memorize/controller/middleware"
 	"memorize/models"
 	"memorize/models/apperrors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
+// mergePatchContentType is media type of JSON Merge Patch defined by RFC 7396
+const mergePatchContentType = "application/merge-patch+json"
+
 type detailsRequset struct {
 	Name    string `json:"name" binding:"omitempty,max=50"`
 	Email   string `json:"email" binding:"omitempty,email"`
 	Website string `json:"website" binding:"omitempty,url"`
+	Locale  string `json:"locale" binding:"omitempty,oneof=en de"`
 }
 
+// Details replaces all details of user, fields missing in request are cleared
+// user must still have version of If-Match header
 func (c *controller) Details(ctx *gin.Context) {
 	authUser := ctx.MustGet("user").(*models.User)
 
+	version, ok := ifMatchVersion(ctx, authUser.UID.String())
+	if !ok {
+		return
+	}
+
 	var request detailsRequset
 
 	if ok := bindData(ctx, &request); !ok {
@@ -29,6 +44,8 @@ func (c *controller) Details(ctx *gin.Context) {
 		Name:    request.Name,
 		Email:   request.Email,
 		Website: request.Website,
+		Locale:  request.Locale,
+		Version: version,
 	}
 
 	requestCtx := ctx.Request.Context()
@@ -37,12 +54,69 @@ func (c *controller) Details(ctx *gin.Context) {
 	if err != nil {
 		log.Printf("Failed to update user: %v\n", err.Error())
 
-		ctx.JSON(apperrors.Status(err), gin.H{
-			"error": err,
-		})
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", userETag(user))
+	ctx.JSON(http.StatusOK, gin.H{
+		"user": user,
+	})
+}
+
+// PatchDetails changes details that are in request with JSON Merge Patch semantics,
+// absent fields keep their value and null clears them, user must still have version of If-Match header
+func (c *controller) PatchDetails(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	version, ok := ifMatchVersion(ctx, authUser.UID.String())
+	if !ok {
+		return
+	}
+
+	if contentType := ctx.ContentType(); contentType != mergePatchContentType && contentType != "application/json" {
+		message := fmt.Sprintf("%s only accepts Content-Type %s or application/json", ctx.FullPath(), mergePatchContentType)
+		middleware.RespondError(ctx, apperrors.NewUnsupportedMediaType(message))
+		return
+	}
+
+	// fields tell absent from present ones, request has their values, null ones are empty
+	var fields map[string]json.RawMessage
+	var request detailsRequset
+
+	if err := ctx.ShouldBindBodyWith(&fields, binding.JSON); err != nil {
+		respondBindError(ctx, err)
+		return
+	}
+
+	if err := ctx.ShouldBindBodyWith(&request, binding.JSON); err != nil {
+		respondBindError(ctx, err)
+		return
+	}
+
+	patch := &models.UserPatch{UID: authUser.UID, Version: version}
+
+	for _, field := range []string{models.UserName, models.UserEmail, models.UserWebsite, models.UserLocale} {
+		if _, ok := fields[field]; ok {
+			patch.Fields = append(patch.Fields, field)
+		}
+	}
+
+	patch.Name = request.Name
+	patch.Email = request.Email
+	patch.Website = request.Website
+	patch.Locale = request.Locale
+
+	user, err := c.UserService.PatchDetails(ctx.Request.Context(), patch)
+
+	if err != nil {
+		log.Printf("Failed to patch user: %v\n", err.Error())
+
+		middleware.RespondError(ctx, err)
 		return
 	}
 
+	ctx.Header("ETag", userETag(user))
 	ctx.JSON(http.StatusOK, gin.H{
 		"user": user,
 	})
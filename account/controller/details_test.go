@@ -147,3 +147,193 @@ func TestDetails(test *testing.T) {
 		mockUserService.AssertCalled(test, "UpdateDetails", updateArguments...)
 	})
 }
+
+func TestPatchDetails(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+	ctxUser := &models.User{
+		UID: uid,
+	}
+
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", ctxUser)
+	})
+
+	mockUserService := new(mocks.MockUserService)
+
+	NewController(&Config{
+		Router:      router,
+		UserService: mockUserService,
+	})
+
+	patch := func(contentType string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+
+		request, _ := http.NewRequest(http.MethodPatch, "/details", bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", contentType)
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("Absent, null and empty fields", func(test *testing.T) {
+		expectedPatch := &models.UserPatch{
+			UID:    uid,
+			Fields: []string{models.UserName, models.UserEmail, models.UserWebsite},
+			Name:   "alice",
+		}
+
+		updatedUser := &models.User{UID: uid, Name: "alice", Locale: "de"}
+
+		mockUserService.
+			On("PatchDetails", mock.Anything, expectedPatch).
+			Return(updatedUser, nil).
+			Once()
+
+		recorder := patch(mergePatchContentType, `{"name":"alice","email":null,"website":""}`)
+
+		responseBody, _ := json.Marshal(gin.H{
+			"user": updatedUser,
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, responseBody, recorder.Body.Bytes())
+		mockUserService.AssertCalled(test, "PatchDetails", mock.Anything, expectedPatch)
+	})
+
+	test.Run("Invalid field", func(test *testing.T) {
+		recorder := patch("application/json", `{"email":"notanemail"}`)
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), `"field":"email"`)
+	})
+
+	test.Run("Malformed JSON", func(test *testing.T) {
+		recorder := patch(mergePatchContentType, `{"name":`)
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), string(apperrors.MalformedJSON))
+	})
+
+	test.Run("Unsupported media type", func(test *testing.T) {
+		recorder := patch("text/plain", `{"name":"alice"}`)
+
+		assert.Equal(test, http.StatusUnsupportedMediaType, recorder.Code)
+	})
+
+	test.Run("Update failure", func(test *testing.T) {
+		mockError := apperrors.NewNotFound("uid", uid.String())
+
+		mockUserService.
+			On("PatchDetails", mock.Anything, mock.AnythingOfType("*models.UserPatch")).
+			Return(nil, mockError).
+			Once()
+
+		recorder := patch("application/json", `{"locale":"en"}`)
+
+		assert.Equal(test, http.StatusNotFound, recorder.Code)
+	})
+
+	mockUserService.AssertNumberOfCalls(test, "PatchDetails", 2)
+}
+
+func TestDetailsPreconditions(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{UID: uid})
+	})
+
+	mockUserService := new(mocks.MockUserService)
+
+	NewController(&Config{
+		Router:      router,
+		UserService: mockUserService,
+	})
+
+	update := func(method string, path string, ifMatch string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+
+		request, _ := http.NewRequest(method, path, bytes.NewBufferString(`{"name":"alice"}`))
+		request.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			request.Header.Set("If-Match", ifMatch)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("If-Match is required since version 2", func(test *testing.T) {
+		for _, method := range []string{http.MethodPut, http.MethodPatch} {
+			recorder := update(method, "/v2/details", "")
+
+			assert.Equal(test, http.StatusPreconditionRequired, recorder.Code)
+			assert.Contains(test, recorder.Body.String(), string(apperrors.PreconditionRequired))
+		}
+
+		mockUserService.AssertNotCalled(test, "UpdateDetails", mock.Anything, mock.Anything)
+		mockUserService.AssertNotCalled(test, "PatchDetails", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Version of If-Match is passed to update", func(test *testing.T) {
+		expectedPatch := &models.UserPatch{
+			UID:     uid,
+			Fields:  []string{models.UserName},
+			Name:    "alice",
+			Version: 3,
+		}
+
+		mockUserService.
+			On("PatchDetails", mock.Anything, expectedPatch).
+			Return(&models.User{UID: uid, Name: "alice", Version: 4}, nil).
+			Once()
+
+		recorder := update(http.MethodPatch, "/v2/details", `"3"`)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, `"4"`, recorder.Header().Get("ETag"))
+		mockUserService.AssertCalled(test, "PatchDetails", mock.Anything, expectedPatch)
+	})
+
+	test.Run("Any version", func(test *testing.T) {
+		mockUserService.
+			On("UpdateDetails", mock.Anything, mock.MatchedBy(func(user *models.User) bool { return user.Version == 0 })).
+			Return(nil).
+			Twice()
+
+		assert.Equal(test, http.StatusOK, update(http.MethodPut, "/v2/details", "*").Code)
+		assert.Equal(test, http.StatusOK, update(http.MethodPut, "/v1/details", "").Code)
+	})
+
+	test.Run("Tag that cannot match", func(test *testing.T) {
+		for _, ifMatch := range []string{`W/"3"`, `"abc"`, "3"} {
+			recorder := update(http.MethodPut, "/v2/details", ifMatch)
+
+			assert.Equal(test, http.StatusPreconditionFailed, recorder.Code, ifMatch)
+		}
+
+		assert.Equal(test, http.StatusBadRequest, update(http.MethodPut, "/v2/details", `"3", "4"`).Code)
+	})
+
+	test.Run("User has changed", func(test *testing.T) {
+		mockUserService.
+			On("PatchDetails", mock.Anything, mock.AnythingOfType("*models.UserPatch")).
+			Return(nil, apperrors.NewPreconditionFailed("uid", uid.String())).
+			Once()
+
+		recorder := update(http.MethodPatch, "/v2/details", `"2"`)
+
+		assert.Equal(test, http.StatusPreconditionFailed, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), string(apperrors.PreconditionFailed))
+	})
+
+	mockUserService.AssertExpectations(test)
+}
@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenIDConfiguration serves OpenID Connect discovery document
+func (c *controller) OpenIDConfiguration(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"issuer":                                c.Issuer,
+		"authorization_endpoint":                c.Issuer + "/authorize",
+		"token_endpoint":                        c.Issuer + "/token",
+		"userinfo_endpoint":                     c.Issuer + "/userinfo",
+		"jwks_uri":                              c.Issuer + "/jwks",
+		"introspection_endpoint":                c.Issuer + "/introspect",
+		"revocation_endpoint":                   c.Issuer + "/revoke",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token", "client_credentials"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{"openid", "profile", "email"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported": []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"preferred_username", "name", "email", "website", "picture", "locale",
+		},
+	})
+}
+
+// JWKS serves public keys that verify access and id tokens
+func (c *controller) JWKS(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.TokenService.JWKS())
+}
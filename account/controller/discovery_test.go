@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscovery(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockTokenService := new(mocks.MockTokenService)
+
+	router := gin.Default()
+
+	NewController(&Config{
+		Router:       router,
+		TokenService: mockTokenService,
+		Issuer:       "https://accounts.example.com",
+	})
+
+	test.Run("OpenID configuration", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/.well-known/openid-configuration", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		var configuration map[string]interface{}
+		json.Unmarshal(recorder.Body.Bytes(), &configuration)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, "https://accounts.example.com", configuration["issuer"])
+		assert.Equal(test, "https://accounts.example.com/jwks", configuration["jwks_uri"])
+	})
+
+	test.Run("JWKS", func(test *testing.T) {
+		keySet := &models.JSONWebKeySet{
+			Keys: []models.JSONWebKey{{KeyType: "RSA", KeyID: "kid"}},
+		}
+		mockTokenService.On("JWKS").Return(keySet)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/jwks", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		expectedBody, _ := json.Marshal(keySet)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+}
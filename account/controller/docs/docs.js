@@ -0,0 +1,102 @@
+// renders operations of openapi.json grouped by tag, schemas are shown resolved
+const schemaRefPrefix = "#/components/schemas/";
+
+function resolve(document, schema, seen) {
+  if (!schema) {
+    return schema;
+  }
+
+  if (schema.$ref) {
+    const name = schema.$ref.slice(schemaRefPrefix.length);
+    if (seen.includes(name)) {
+      return { $ref: name };
+    }
+    return resolve(document, document.components.schemas[name], seen.concat(name));
+  }
+
+  const resolved = Object.assign({}, schema);
+  if (schema.properties) {
+    resolved.properties = {};
+    for (const [name, property] of Object.entries(schema.properties)) {
+      resolved.properties[name] = resolve(document, property, seen);
+    }
+  }
+  if (schema.items) {
+    resolved.items = resolve(document, schema.items, seen);
+  }
+  if (schema.additionalProperties) {
+    resolved.additionalProperties = resolve(document, schema.additionalProperties, seen);
+  }
+
+  return resolved;
+}
+
+function element(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text) {
+    node.textContent = text;
+  }
+  if (className) {
+    node.className = className;
+  }
+  return node;
+}
+
+function content(api, title, body) {
+  const section = element("div");
+  section.appendChild(element("h4", title));
+
+  for (const [type, media] of Object.entries(body.content || {})) {
+    section.appendChild(element("p", type));
+    section.appendChild(element("pre", JSON.stringify(resolve(api, media.schema, []), null, 2)));
+  }
+
+  return section;
+}
+
+function operation(api, method, path, op) {
+  const details = element("details");
+  const summary = element("summary");
+  summary.appendChild(element("span", method, "method " + method));
+  summary.appendChild(element("code", path));
+  summary.appendChild(document.createTextNode(" " + (op.summary || "")));
+  details.appendChild(summary);
+
+  const body = element("div", null, "operation");
+  if (op.security) {
+    body.appendChild(element("p", "Security: " + op.security.map((scheme) => Object.keys(scheme).join(", ")).join(" or ")));
+  }
+  if (op.requestBody) {
+    body.appendChild(content(api, "Request", op.requestBody));
+  }
+  for (const [status, response] of Object.entries(op.responses)) {
+    body.appendChild(content(api, status + " " + response.description, response));
+  }
+  details.appendChild(body);
+
+  return details;
+}
+
+async function render() {
+  const response = await fetch("../openapi.json");
+  const api = await response.json();
+
+  document.getElementById("title").textContent = api.info.title + " " + api.info.version;
+
+  const tags = {};
+  for (const [path, item] of Object.entries(api.paths)) {
+    for (const [method, op] of Object.entries(item)) {
+      const tag = (op.tags && op.tags[0]) || "other";
+      tags[tag] = tags[tag] || [];
+      tags[tag].push(operation(api, method, path, op));
+    }
+  }
+
+  const operations = document.getElementById("operations");
+  for (const tag of Object.keys(tags).sort()) {
+    operations.appendChild(element("h2", tag));
+    tags[tag].forEach((node) => operations.appendChild(node));
+  }
+}
+
+render();
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"fmt"
+	"memorize/controller/middleware"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userETag is strong entity tag of user, it changes with every update of user
+func userETag(user *models.User) string {
+	return fmt.Sprintf(`"%d"`, user.Version)
+}
+
+// ifMatchVersion reads version user must have to be updated from If-Match header, zero allows any version.
+// Header is required since version 2, older clients update without it.
+// Error is responded and false returned when header is missing or cannot match
+func ifMatchVersion(ctx *gin.Context, uid string) (int64, bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+
+	if header == "" {
+		if middleware.Version(ctx) < 2 {
+			return 0, true
+		}
+
+		middleware.RespondError(ctx, apperrors.NewPreconditionRequired("If-Match"))
+		return 0, false
+	}
+
+	if header == "*" {
+		return 0, true
+	}
+
+	tags := entityTags(header)
+	if len(tags) > 1 {
+		middleware.RespondError(ctx, apperrors.NewBadRequest("If-Match must have single entity tag of user"))
+		return 0, false
+	}
+
+	// weak tags never match for updates, neither do tags of other resources
+	if len(tags) == 1 && strings.HasPrefix(tags[0], `"`) && strings.HasSuffix(tags[0], `"`) {
+		if version, err := strconv.ParseInt(strings.Trim(tags[0], `"`), 10, 64); err == nil && version > 0 {
+			return version, true
+		}
+	}
+
+	middleware.RespondError(ctx, apperrors.NewPreconditionFailed("uid", uid))
+	return 0, false
+}
+
+// noneMatch tells if If-None-Match header does not have etag, so response must be sent.
+// It uses weak comparison, as GET requests do
+func noneMatch(ctx *gin.Context, etag string) bool {
+	header := strings.TrimSpace(ctx.GetHeader("If-None-Match"))
+
+	if header == "*" {
+		return false
+	}
+
+	for _, tag := range entityTags(header) {
+		if strings.TrimPrefix(tag, "W/") == etag {
+			return false
+		}
+	}
+
+	return true
+}
+
+// entity tags of comma separated list like `"1", W/"2"`
+func entityTags(header string) []string {
+	var tags []string
+
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"log"
+	"memorize/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Introspect is the OAuth 2.0 token introspection endpoint defined by RFC 7662
+func (c *controller) Introspect(ctx *gin.Context) {
+	var request models.TokenIntrospectionRequest
+
+	if ok := bindOAuthForm(ctx, &request, &request.ClientID, &request.ClientSecret); !ok {
+		return
+	}
+
+	response, err := c.OAuthService.Introspect(ctx.Request.Context(), &request)
+
+	if err != nil {
+		log.Printf("Failed to introspect token for client %v: %v\n", request.ClientID, err.Error())
+		respondOAuthError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIntrospect(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockOAuthService := new(mocks.MockOAuthService)
+
+	router := gin.Default()
+
+	NewController(&Config{
+		Router:       router,
+		OAuthService: mockOAuthService,
+	})
+
+	test.Run("Active token", func(test *testing.T) {
+		introspection := &models.TokenIntrospectionResponse{
+			Active:    true,
+			TokenType: "Bearer",
+			Subject:   "uid",
+			Username:  "alice",
+		}
+
+		mockOAuthService.
+			On("Introspect", mock.Anything, &models.TokenIntrospectionRequest{
+				Token:         "access",
+				TokenTypeHint: "access_token",
+				ClientID:      "resource",
+				ClientSecret:  "secret",
+			}).
+			Return(introspection, nil)
+
+		form := url.Values{"token": {"access"}, "token_type_hint": {"access_token"}}
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/introspect", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.SetBasicAuth("resource", "secret")
+		router.ServeHTTP(recorder, request)
+
+		expectedBody, _ := json.Marshal(introspection)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, "no-store", recorder.Header().Get("Cache-Control"))
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+
+	test.Run("Client authentication failed", func(test *testing.T) {
+		mockOAuthService.
+			On("Introspect", mock.Anything, &models.TokenIntrospectionRequest{
+				Token:        "access",
+				ClientID:     "resource",
+				ClientSecret: "wrong",
+			}).
+			Return(nil, apperrors.NewOAuthError(apperrors.InvalidClient, "Client authentication failed"))
+
+		form := url.Values{"token": {"access"}, "client_id": {"resource"}, "client_secret": {"wrong"}}
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/introspect", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), apperrors.InvalidClient)
+	})
+
+	test.Run("Requires form", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/introspect", strings.NewReader(`{"token":"access"}`))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		mockOAuthService.AssertNumberOfCalls(test, "Introspect", 2)
+	})
+}
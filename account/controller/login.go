@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"log"
+	"memorize/controller/middleware"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/normalize"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+type loginRequest struct {
+	Login string `json:"login" binding:"required,login"`
+	// refresh token of this session, it is replaced by reissued one instead of staying valid
+	RefreshToken string `json:"refreshToken"`
+}
+
+type loginAvailabilityQuery struct {
+	Login string `form:"login" binding:"required,login"`
+}
+
+type loginAvailabilityResponse struct {
+	Login     string `json:"login"`
+	Available bool   `json:"available"`
+}
+
+// ChangeLogin renames user, tokens are reissued since access tokens carry login
+// previous login stays reserved for user, so nobody can impersonate them with it
+func (c *controller) ChangeLogin(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	var request loginRequest
+
+	if ok := bindData(ctx, &request); !ok {
+		return
+	}
+
+	requestCtx := ctx.Request.Context()
+
+	// refresh token is checked before login changes, so invalid one does not leave user renamed without tokens
+	previousToken := ""
+	if request.RefreshToken != "" {
+		refreshToken, err := c.validateRefreshToken(request.RefreshToken)
+		if err == nil && refreshToken.UserID != authUser.UID {
+			err = apperrors.NewAuthorization("Refresh token belongs to other user").WithCode(apperrors.InvalidRefreshToken)
+		}
+
+		if err != nil {
+			middleware.RespondError(ctx, err)
+			return
+		}
+
+		previousToken = refreshToken.ID.String()
+	}
+
+	user, err := c.UserService.ChangeLogin(requestCtx, authUser.UID, request.Login)
+
+	if err != nil {
+		log.Printf("Failed to change login of user: %v. Reason: %v\n", authUser.UID, err.Error())
+
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	tokens, err := c.TokenService.NewPairFromUser(requestCtx, user, previousToken)
+
+	if err != nil {
+		log.Printf("Failed to reissue tokens for user: %v. Reason: %v\n", user.UID, err.Error())
+
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	c.respondTokens(ctx, http.StatusOK, tokens)
+}
+
+// LoginAvailability tells signed in user if they can change login to one in query
+func (c *controller) LoginAvailability(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	var query loginAvailabilityQuery
+
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		respondBindError(ctx, err)
+		return
+	}
+
+	available, err := c.UserService.LoginAvailable(ctx.Request.Context(), authUser.UID, query.Login)
+
+	if err != nil {
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginAvailabilityResponse{
+		Login:     query.Login,
+		Available: available,
+	})
+}
+
+// UserByLogin resolves login for backend services, previous logins redirect to current one while reserved
+func (c *controller) UserByLogin(ctx *gin.Context) {
+	login := ctx.Param("login")
+
+	user, err := c.UserService.GetUserByLogin(ctx.Request.Context(), login)
+
+	if err != nil {
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	// same login in other case or width is not redirected, only previous one
+	// redirect is temporary, reservation ends and login can be taken by other user
+	if key, _ := normalize.LoginKey(login); key != user.NormalizedLogin {
+		ctx.Redirect(http.StatusTemporaryRedirect, middleware.BasePath(ctx)+"/logins/"+url.PathEscape(user.Login))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"user": user,
+	})
+}
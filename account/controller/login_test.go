@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChangeLogin(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice2"}
+	tokens := &models.TokenPair{
+		AccessToken:  models.AccessToken{Token: "access"},
+		RefreshToken: models.RefreshToken{Token: "refresh"},
+	}
+
+	setup := func() (*gin.Engine, *mocks.MockUserService, *mocks.MockTokenService) {
+		router := gin.Default()
+		router.Use(func(c *gin.Context) {
+			c.Set("user", &models.User{UID: uid, Login: "alice"})
+		})
+
+		mockUserService := new(mocks.MockUserService)
+		mockTokenService := new(mocks.MockTokenService)
+
+		NewController(&Config{
+			Router:       router,
+			UserService:  mockUserService,
+			TokenService: mockTokenService,
+		})
+
+		return router, mockUserService, mockTokenService
+	}
+
+	put := func(router *gin.Engine, path string, body gin.H) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+
+		requestBody, _ := json.Marshal(body)
+		request, _ := http.NewRequest(http.MethodPut, path, bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("Tokens are reissued", func(test *testing.T) {
+		router, mockUserService, mockTokenService := setup()
+
+		tokenID, _ := uuid.NewRandom()
+		mockTokenService.On("ValidateRefreshToken", "old-refresh").Return(&models.RefreshToken{ID: tokenID, UserID: uid}, nil)
+		mockUserService.On("ChangeLogin", mock.Anything, uid, "alice2").Return(user, nil)
+		mockTokenService.On("NewPairFromUser", mock.Anything, user, tokenID.String()).Return(tokens, nil)
+
+		recorder := put(router, "/v2/login", gin.H{"login": "alice2", "refreshToken": "old-refresh"})
+
+		expectedBody, _ := json.Marshal(tokens)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+		assert.Equal(test, "no-store", recorder.Header().Get("Cache-Control"))
+		mockUserService.AssertExpectations(test)
+		mockTokenService.AssertExpectations(test)
+	})
+
+	test.Run("New session without refresh token", func(test *testing.T) {
+		router, mockUserService, mockTokenService := setup()
+
+		mockUserService.On("ChangeLogin", mock.Anything, uid, "alice2").Return(user, nil)
+		mockTokenService.On("NewPairFromUser", mock.Anything, user, "").Return(tokens, nil)
+
+		recorder := put(router, "/login", gin.H{"login": "alice2"})
+
+		expectedBody, _ := json.Marshal(gin.H{"tokens": tokens})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+
+	test.Run("Refresh token of other user", func(test *testing.T) {
+		router, mockUserService, mockTokenService := setup()
+
+		otherUID, _ := uuid.NewRandom()
+		mockTokenService.On("ValidateRefreshToken", "other-refresh").Return(&models.RefreshToken{UserID: otherUID}, nil)
+
+		recorder := put(router, "/login", gin.H{"login": "alice2", "refreshToken": "other-refresh"})
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), string(apperrors.InvalidRefreshToken))
+		mockUserService.AssertNotCalled(test, "ChangeLogin", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Invalid login", func(test *testing.T) {
+		router, mockUserService, _ := setup()
+
+		recorder := put(router, "/login", gin.H{"login": "alice smith"})
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		mockUserService.AssertNotCalled(test, "ChangeLogin", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Login taken", func(test *testing.T) {
+		router, mockUserService, mockTokenService := setup()
+
+		mockUserService.
+			On("ChangeLogin", mock.Anything, uid, "bob").
+			Return(nil, apperrors.NewConflict("login", "bob").WithCode(apperrors.LoginTaken))
+
+		recorder := put(router, "/login", gin.H{"login": "bob"})
+
+		assert.Equal(test, http.StatusConflict, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), string(apperrors.LoginTaken))
+		mockTokenService.AssertNotCalled(test, "NewPairFromUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestLoginAvailability(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{UID: uid})
+	})
+
+	mockUserService := new(mocks.MockUserService)
+	mockUserService.On("LoginAvailable", mock.Anything, uid, "alice2").Return(true, nil)
+
+	NewController(&Config{
+		Router:      router,
+		UserService: mockUserService,
+	})
+
+	get := func(query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+
+		request, _ := http.NewRequest(http.MethodGet, "/login/availability"+query, nil)
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("Available", func(test *testing.T) {
+		recorder := get("?login=alice2")
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.JSONEq(test, `{"login":"alice2","available":true}`, recorder.Body.String())
+	})
+
+	test.Run("Invalid login", func(test *testing.T) {
+		for _, query := range []string{"", "?login=alice%20smith"} {
+			assert.Equal(test, http.StatusBadRequest, get(query).Code, query)
+		}
+
+		mockUserService.AssertNumberOfCalls(test, "LoginAvailable", 1)
+	})
+}
+
+func TestUserByLogin(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice2", NormalizedLogin: "alice2"}
+
+	router := gin.Default()
+
+	mockUserService := new(mocks.MockUserService)
+	mockUserService.On("GetUserByLogin", mock.Anything, "Alice2").Return(user, nil)
+	mockUserService.On("GetUserByLogin", mock.Anything, "alice").Return(user, nil)
+	mockUserService.On("GetUserByLogin", mock.Anything, "bob").Return(nil, apperrors.NewNotFound("login", "bob"))
+
+	NewController(&Config{
+		Router:      router,
+		UserService: mockUserService,
+		BaseURL:     "/api/account",
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+
+		request, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("Current login in any case", func(test *testing.T) {
+		recorder := get("/api/account/logins/Alice2")
+
+		expectedBody, _ := json.Marshal(gin.H{"user": user})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+
+	test.Run("Previous login redirects", func(test *testing.T) {
+		recorder := get("/api/account/v2/logins/alice")
+
+		assert.Equal(test, http.StatusTemporaryRedirect, recorder.Code)
+		assert.Equal(test, "/api/account/v2/logins/alice2", recorder.Header().Get("Location"))
+	})
+
+	test.Run("Unknown login", func(test *testing.T) {
+		assert.Equal(test, http.StatusNotFound, get("/api/account/logins/bob").Code)
+	})
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"log"
+	"memorize/controller/middleware"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type magicLinkRequest struct {
+	Login string `json:"login" binding:"required"`
+}
+
+// nonce is the one returned to device that requested the link
+type magicLinkVerifyRequest struct {
+	Token string `json:"token" binding:"required"`
+	Nonce string `json:"nonce" binding:"required"`
+}
+
+// MagicLink mails signin link, response is the same whether login exists or not
+func (c *controller) MagicLink(ctx *gin.Context) {
+	var request magicLinkRequest
+
+	if ok := bindData(ctx, &request); !ok {
+		return
+	}
+
+	nonce, err := c.MagicLinkService.Send(ctx.Request.Context(), request.Login, ctx.ClientIP())
+
+	if err != nil {
+		log.Printf("Failed to send magic link: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"nonce": nonce,
+	})
+}
+
+// VerifyMagicLink exchanges signin link for tokens
+func (c *controller) VerifyMagicLink(ctx *gin.Context) {
+	var request magicLinkVerifyRequest
+
+	if ok := bindData(ctx, &request); !ok {
+		return
+	}
+
+	user, err := c.MagicLinkService.Verify(ctx.Request.Context(), request.Token, request.Nonce)
+
+	if err != nil {
+		log.Printf("Failed to verify magic link: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	c.completeSignin(ctx, user)
+}
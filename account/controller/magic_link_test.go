@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMagicLink(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice"}
+
+	newRequest := func(target string, body interface{}) *http.Request {
+		requestBody, _ := json.Marshal(body)
+
+		request, _ := http.NewRequest(http.MethodPost, target, bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		request.RemoteAddr = "10.0.0.1:1234"
+
+		return request
+	}
+
+	test.Run("Send", func(test *testing.T) {
+		mockService := new(mocks.MockMagicLinkService)
+		mockService.On("Send", mock.Anything, "alice", "10.0.0.1").Return("nonce", nil)
+
+		router := gin.Default()
+		NewController(&Config{Router: router, MagicLinkService: mockService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest("/signin/link", gin.H{"login": "alice"}))
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"nonce": "nonce",
+		})
+
+		assert.Equal(test, http.StatusAccepted, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+
+	test.Run("Send rate limited", func(test *testing.T) {
+		mockService := new(mocks.MockMagicLinkService)
+		mockService.On("Send", mock.Anything, "alice", "10.0.0.1").Return("", apperrors.NewTooManyRequests())
+
+		router := gin.Default()
+		NewController(&Config{Router: router, MagicLinkService: mockService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest("/signin/link", gin.H{"login": "alice"}))
+
+		assert.Equal(test, http.StatusTooManyRequests, recorder.Code)
+	})
+
+	test.Run("Verify", func(test *testing.T) {
+		tokens := &models.TokenPair{
+			AccessToken:  models.AccessToken{Token: "access"},
+			RefreshToken: models.RefreshToken{Token: "refresh"},
+		}
+
+		mockService := new(mocks.MockMagicLinkService)
+		mockService.On("Verify", mock.Anything, "token", "nonce").Return(user, nil)
+		mockTokenService := new(mocks.MockTokenService)
+		mockTokenService.On("NewPairFromUser", mock.Anything, user, "").Return(tokens, nil)
+
+		router := gin.Default()
+		NewController(&Config{Router: router, MagicLinkService: mockService, TokenService: mockTokenService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest("/signin/link/verify", gin.H{"token": "token", "nonce": "nonce"}))
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"tokens": tokens,
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+
+	test.Run("Verify without nonce", func(test *testing.T) {
+		mockService := new(mocks.MockMagicLinkService)
+
+		router := gin.Default()
+		NewController(&Config{Router: router, MagicLinkService: mockService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest("/signin/link/verify", gin.H{"token": "token"}))
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		mockService.AssertNotCalled(test, "Verify", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Verify invalid link", func(test *testing.T) {
+		mockService := new(mocks.MockMagicLinkService)
+		mockService.
+			On("Verify", mock.Anything, "token", "nonce").
+			Return(nil, apperrors.NewAuthorization("Signin link is invalid or expired").WithCode(apperrors.SigninLinkInvalid))
+		mockTokenService := new(mocks.MockTokenService)
+
+		router := gin.Default()
+		NewController(&Config{Router: router, MagicLinkService: mockService, TokenService: mockTokenService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest("/signin/link/verify", gin.H{"token": "token", "nonce": "nonce"}))
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+		mockTokenService.AssertNotCalled(test, "NewPairFromUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"log"
+	"memorize/controller/middleware"
 	"net/http"
 
 	"memorize/models"
@@ -17,9 +18,7 @@ func (c *controller) Me(ctx *gin.Context) {
 	if !exists {
 		log.Printf("Unable to extract user from request context unknown reason: %v\n", ctx)
 		err := apperrors.NewInternal()
-		ctx.JSON(err.Status(), gin.H{
-			"error": err,
-		})
+		middleware.RespondError(ctx, err)
 
 		return
 	}
@@ -33,13 +32,21 @@ func (c *controller) Me(ctx *gin.Context) {
 		log.Printf("Unable to find user: %v\n%v", uid, err)
 		e := apperrors.NewNotFound("user", uid.String())
 
-		ctx.JSON(e.Status(), gin.H{
-			"error": e,
-		})
+		middleware.RespondError(ctx, e)
 
 		return
 	}
 
+	// clients revalidate with If-None-Match, unchanged user is not sent again
+	etag := userETag(userFromDB)
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", "private, no-cache")
+
+	if !noneMatch(ctx, etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"user": userFromDB,
 	})
@@ -121,3 +121,61 @@ func TestMe(test *testing.T) {
 		mockUserService.AssertExpectations(t)
 	})
 }
+
+func TestMeETag(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Name: "Alice", Version: 7}
+
+	mockUserService := new(mocks.MockUserService)
+	mockUserService.On("GetUser", mock.Anything, uid).Return(user, nil)
+
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{UID: uid})
+	})
+
+	NewController(&Config{
+		Router:      router,
+		UserService: mockUserService,
+	})
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+
+		request, _ := http.NewRequest(http.MethodGet, "/me", nil)
+		if ifNoneMatch != "" {
+			request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("ETag of user", func(test *testing.T) {
+		recorder := get("")
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, `"7"`, recorder.Header().Get("ETag"))
+		assert.Equal(test, "private, no-cache", recorder.Header().Get("Cache-Control"))
+	})
+
+	test.Run("Not modified", func(test *testing.T) {
+		for _, ifNoneMatch := range []string{`"7"`, `W/"7"`, `"6", "7"`, "*"} {
+			recorder := get(ifNoneMatch)
+
+			assert.Equal(test, http.StatusNotModified, recorder.Code, ifNoneMatch)
+			assert.Equal(test, `"7"`, recorder.Header().Get("ETag"))
+			assert.Empty(test, recorder.Body.String())
+		}
+	})
+
+	test.Run("Modified", func(test *testing.T) {
+		recorder := get(`"6"`)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), `"name":"Alice"`)
+	})
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiVersionKey  = "apiVersion"
+	apiBasePathKey = "apiBasePath"
+)
+
+var (
+	// requests by API version, like "v1"
+	apiVersionRequests = expvar.NewMap("apiVersionRequests")
+	// requests of deprecated versions by route, like "v1 POST /api/account/v1/tokens",
+	// tells which endpoints clients still need before sunset
+	deprecatedRouteRequests = expvar.NewMap("deprecatedRouteRequests")
+)
+
+// Deprecation announces that API version is deprecated, headers are defined by
+// RFC 9745 and RFC 8594
+type Deprecation struct {
+	// when version was or will be deprecated
+	At time.Time
+	// when version stops working, not sent when zero
+	Sunset time.Time
+	// documentation of deprecation and migration, not sent when empty
+	Link string
+}
+
+// APIVersion marks requests of route group with API version and its base path
+// responses of deprecated versions get Deprecation and Sunset headers
+func APIVersion(version int, basePath string, deprecation *Deprecation) gin.HandlerFunc {
+	name := "v" + strconv.Itoa(version)
+
+	return func(ctx *gin.Context) {
+		ctx.Set(apiVersionKey, version)
+		ctx.Set(apiBasePathKey, basePath)
+
+		apiVersionRequests.Add(name, 1)
+
+		if deprecation != nil {
+			deprecatedRouteRequests.Add(fmt.Sprintf("%v %v %v", name, ctx.Request.Method, ctx.FullPath()), 1)
+
+			ctx.Header("Deprecation", fmt.Sprintf("@%d", deprecation.At.Unix()))
+
+			if !deprecation.Sunset.IsZero() {
+				ctx.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+			}
+
+			if deprecation.Link != "" {
+				ctx.Header("Link", fmt.Sprintf("<%v>; rel=\"deprecation\"", deprecation.Link))
+			}
+		}
+
+		ctx.Next()
+	}
+}
+
+// Version of API the request was routed to, routes outside of versioned groups are version 1
+func Version(ctx *gin.Context) int {
+	if version, ok := ctx.Get(apiVersionKey); ok {
+		return version.(int)
+	}
+
+	return 1
+}
+
+// BasePath of API version the request was routed to, empty outside of versioned groups
+func BasePath(ctx *gin.Context) string {
+	return ctx.GetString(apiBasePathKey)
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIVersion(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(version int, deprecation *Deprecation) (*httptest.ResponseRecorder, int, string) {
+		recorder := httptest.NewRecorder()
+		_, router := gin.CreateTestContext(recorder)
+
+		var routedVersion int
+		var basePath string
+
+		group := router.Group("/api/v1", APIVersion(version, "/api/v1", deprecation))
+		group.GET("/me", func(ctx *gin.Context) {
+			routedVersion = Version(ctx)
+			basePath = BasePath(ctx)
+			ctx.Status(http.StatusOK)
+		})
+
+		request, _ := http.NewRequest(http.MethodGet, "/api/v1/me", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		return recorder, routedVersion, basePath
+	}
+
+	test.Run("Current version", func(test *testing.T) {
+		recorder, version, basePath := serve(2, nil)
+
+		assert.Equal(test, 2, version)
+		assert.Equal(test, "/api/v1", basePath)
+		assert.Empty(test, recorder.Header().Get("Deprecation"))
+		assert.Empty(test, recorder.Header().Get("Sunset"))
+	})
+
+	test.Run("Deprecated version", func(test *testing.T) {
+		before := deprecatedRouteRequests.Get("v1 GET /api/v1/me")
+
+		recorder, version, _ := serve(1, &Deprecation{
+			At:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Sunset: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+			Link:   "https://example.com/migrate",
+		})
+
+		assert.Equal(test, 1, version)
+		assert.Equal(test, "@1767225600", recorder.Header().Get("Deprecation"))
+		assert.Equal(test, "Thu, 31 Dec 2026 00:00:00 GMT", recorder.Header().Get("Sunset"))
+		assert.Equal(test, `<https://example.com/migrate>; rel="deprecation"`, recorder.Header().Get("Link"))
+
+		after := deprecatedRouteRequests.Get("v1 GET /api/v1/me")
+		assert.NotNil(test, after)
+		if before == nil {
+			assert.Equal(test, "1", after.String())
+		}
+	})
+
+	test.Run("Unversioned route", func(test *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+		assert.Equal(test, 1, Version(ctx))
+		assert.Empty(test, BasePath(ctx))
+	})
+}
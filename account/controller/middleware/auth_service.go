@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"memorize/models"
+	"memorize/models/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// extracts a service principal from the Authorization header
+// only client credentials tokens issued for audience are accepted, user tokens are rejected
+// It sets the principal to the context as "service", "user" stays unset
+func AuthService(s models.TokenService, audience string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, ok := bearerToken(ctx)
+		if !ok {
+			return
+		}
+
+		principal, err := s.ValidateServiceToken(token, audience)
+
+		if err != nil {
+			err := apperrors.NewAuthorization("Provided token is invalid").WithCode(apperrors.InvalidToken)
+			RespondError(ctx, err)
+			return
+		}
+
+		ctx.Set("service", principal)
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"fmt"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthService(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockTokenService := new(mocks.MockTokenService)
+
+	principal := &models.ServicePrincipal{
+		ClientID: "billing",
+		Audience: "account",
+	}
+
+	mockTokenService.On("ValidateServiceToken", "serviceToken", "account").Return(principal, nil)
+	mockTokenService.
+		On("ValidateServiceToken", "userToken", "account").
+		Return(nil, apperrors.NewAuthorization("Unable to verify service from token"))
+
+	test.Run("Adds a service to context", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		_, router := gin.CreateTestContext(recorder)
+
+		var contextService *models.ServicePrincipal
+		var userExists bool
+
+		router.GET("/users", AuthService(mockTokenService, "account"), func(c *gin.Context) {
+			contextService = c.MustGet("service").(*models.ServicePrincipal)
+			_, userExists = c.Get("user")
+		})
+
+		request, _ := http.NewRequest(http.MethodGet, "/users", http.NoBody)
+
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", "serviceToken"))
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, principal, contextService)
+		assert.False(test, userExists)
+	})
+
+	test.Run("User token", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		_, router := gin.CreateTestContext(recorder)
+
+		router.GET("/users", AuthService(mockTokenService, "account"))
+
+		request, _ := http.NewRequest(http.MethodGet, "/users", http.NoBody)
+
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", "userToken"))
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+	})
+
+	test.Run("Missing Authorization Header", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		_, router := gin.CreateTestContext(recorder)
+
+		router.GET("/users", AuthService(mockTokenService, "account"))
+
+		request, _ := http.NewRequest(http.MethodGet, "/users", http.NoBody)
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+	})
+}
@@ -3,55 +3,74 @@ package middleware
 import (
 	"memorize/models"
 	"memorize/models/apperrors"
+	"memorize/validation"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 type authHeader struct {
 	Token string `header:"Authorization"`
 }
 
-type invalidArgument struct {
-	Field string `json:"field"`
-	Value string `json:"value"`
-	Tag   string `json:"tag"`
-	Param string `json:"param"`
-}
-
 // extracts a user from the Authorization header
 // It sets the user to the context if the user exists
-func AuthUser(s models.TokenService) gin.HandlerFunc {
+// personal access tokens are accepted when p is not nil, their scopes are set to the context
+// tokens issued to OAuth clients are limited to scopes user granted client, they are set to the context too
+func AuthUser(s models.TokenService, p models.PersonalAccessTokenService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		header := authHeader{}
+		token, ok := bearerToken(ctx)
+		if !ok {
+			return
+		}
+
+		if p != nil && strings.HasPrefix(token, models.PersonalAccessTokenPrefix) {
+			user, scopes, err := p.Validate(ctx.Request.Context(), token)
+
+			if err != nil {
+				RespondError(ctx, err)
+				return
+			}
+
+			ctx.Set("user", user)
+			ctx.Set("scopes", scopes)
+
+			ctx.Next()
+			return
+		}
 
-		if err := ctx.ShouldBindHeader(&header); err != nil {
-			handleError(ctx, err)
-			ctx.Abort()
+		user, grant, ok := validateAccessToken(ctx, s, token)
+		if !ok {
 			return
 		}
 
-		idTokenHeader := strings.Split(header.Token, "Bearer ")
+		ctx.Set("user", user)
+
+		if grant != nil {
+			ctx.Set("scopes", grant.Scopes())
+		}
 
-		if len(idTokenHeader) < 2 {
-			err := apperrors.NewAuthorization("Must provide Authorization header with format `Bearer {token}`")
+		ctx.Next()
+	}
+}
 
-			ctx.JSON(err.Status(), gin.H{
-				"error": err,
-			})
-			ctx.Abort()
+// AuthSession extracts a user like AuthUser, but only from access tokens of account service itself
+// routes that manage account security cannot be called by OAuth clients or with personal access tokens
+func AuthSession(s models.TokenService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, ok := bearerToken(ctx)
+		if !ok {
 			return
 		}
 
-		user, err := s.ValidateAccessToken(idTokenHeader[1])
+		user, grant, ok := validateAccessToken(ctx, s, token)
+		if !ok {
+			return
+		}
 
-		if err != nil {
-			err := apperrors.NewAuthorization("Provided token is invalid")
-			ctx.JSON(err.Status(), gin.H{
-				"error": err,
-			})
-			ctx.Abort()
+		if grant != nil {
+			err := apperrors.NewForbidden("Token issued to OAuth client cannot manage account").WithCode(apperrors.InsufficientScope)
+			RespondError(ctx, err)
 			return
 		}
 
@@ -61,31 +80,44 @@ func AuthUser(s models.TokenService) gin.HandlerFunc {
 	}
 }
 
-func handleError(ctx *gin.Context, err error) {
-	if errs, ok := err.(validator.ValidationErrors); ok {
-		var invalidArgs []invalidArgument
-
-		for _, err := range errs {
-			invalidArgs = append(invalidArgs, invalidArgument{
-				err.Field(),
-				err.Value().(string),
-				err.Tag(),
-				err.Param(),
-			})
-		}
+// validates access token and reads client grant of it, responds with error and aborts if it is invalid
+func validateAccessToken(ctx *gin.Context, s models.TokenService, token string) (*models.User, *models.ClientGrant, bool) {
+	invalid := apperrors.NewAuthorization("Provided token is invalid").WithCode(apperrors.InvalidToken)
+
+	user, err := s.ValidateAccessToken(token)
+
+	if err != nil {
+		RespondError(ctx, invalid)
+		return nil, nil, false
+	}
+
+	grant, err := s.AccessTokenGrant(token)
+
+	if err != nil {
+		RespondError(ctx, invalid)
+		return nil, nil, false
+	}
+
+	return user, grant, true
+}
+
+// reads token from Authorization header, responds with error and aborts if it is missing
+func bearerToken(ctx *gin.Context) (string, bool) {
+	header := authHeader{}
+
+	if err := ctx.ShouldBindHeader(&header); err != nil {
+		RespondError(ctx, validation.Error(err, Locale(ctx)))
+		return "", false
+	}
+
+	idTokenHeader := strings.Split(header.Token, "Bearer ")
 
-		err := apperrors.NewBadRequest("Invalid request parameters. See invalidArgs")
+	if len(idTokenHeader) < 2 {
+		err := apperrors.NewAuthorization("Must provide Authorization header with format `Bearer {token}`").WithCode(apperrors.MissingToken)
 
-		ctx.JSON(err.Status(), gin.H{
-			"error":       err,
-			"invalidArgs": invalidArgs,
-		})
-		ctx.Abort()
-		return
+		RespondError(ctx, err)
+		return "", false
 	}
 
-	error := apperrors.NewInternal()
-	ctx.JSON(error.Status(), gin.H{
-		"error": error,
-	})
+	return idTokenHeader[1], true
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestAuthUser(test *testing.T) {
@@ -27,10 +28,16 @@ func TestAuthUser(test *testing.T) {
 
 	validTokenHeader := "validTokenString"
 	invalidTokenHeader := "invalidTokenString"
-	invalidTokenErr := apperrors.NewAuthorization("Unable to verify user from idToken")
+	invalidTokenErr := apperrors.NewAuthorization("Unable to verify user from idToken").WithCode(apperrors.InvalidToken)
+
+	clientTokenHeader := "clientTokenString"
+	clientGrant := &models.ClientGrant{ClientID: "third-party", Scope: "openid profile"}
 
 	mockTokenService.On("ValidateAccessToken", validTokenHeader).Return(user, nil)
 	mockTokenService.On("ValidateAccessToken", invalidTokenHeader).Return(nil, invalidTokenErr)
+	mockTokenService.On("ValidateAccessToken", clientTokenHeader).Return(user, nil)
+	mockTokenService.On("AccessTokenGrant", validTokenHeader).Return(nil, nil)
+	mockTokenService.On("AccessTokenGrant", clientTokenHeader).Return(clientGrant, nil)
 
 	test.Run("Adds a user to context", func(test *testing.T) {
 		recorder := httptest.NewRecorder()
@@ -39,7 +46,7 @@ func TestAuthUser(test *testing.T) {
 
 		var contextUser *models.User
 
-		router.GET("/me", AuthUser(mockTokenService), func(c *gin.Context) {
+		router.GET("/me", AuthUser(mockTokenService, nil), func(c *gin.Context) {
 			contextKeyVal, _ := c.Get("user")
 			contextUser = contextKeyVal.(*models.User)
 		})
@@ -60,7 +67,7 @@ func TestAuthUser(test *testing.T) {
 
 		_, router := gin.CreateTestContext(recorder)
 
-		router.GET("/me", AuthUser(mockTokenService))
+		router.GET("/me", AuthUser(mockTokenService, nil))
 
 		request, _ := http.NewRequest(http.MethodGet, "/me", http.NoBody)
 
@@ -76,7 +83,7 @@ func TestAuthUser(test *testing.T) {
 
 		_, router := gin.CreateTestContext(recorder)
 
-		router.GET("/me", AuthUser(mockTokenService))
+		router.GET("/me", AuthUser(mockTokenService, nil))
 
 		request, _ := http.NewRequest(http.MethodGet, "/me", http.NoBody)
 
@@ -85,4 +92,115 @@ func TestAuthUser(test *testing.T) {
 		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
 		mockTokenService.AssertNotCalled(test, "ValidateAccessToken")
 	})
+
+	test.Run("Personal access token", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		_, router := gin.CreateTestContext(recorder)
+
+		personalAccessToken := models.PersonalAccessTokenPrefix + "secret"
+
+		mockPersonalAccessTokenService := new(mocks.MockPersonalAccessTokenService)
+		mockPersonalAccessTokenService.
+			On("Validate", mock.Anything, personalAccessToken).
+			Return(user, []string{models.ScopeProfileRead}, nil)
+
+		var contextScopes []string
+
+		router.GET("/me", AuthUser(mockTokenService, mockPersonalAccessTokenService), func(c *gin.Context) {
+			contextScopes = c.MustGet("scopes").([]string)
+		})
+
+		request, _ := http.NewRequest(http.MethodGet, "/me", http.NoBody)
+
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", personalAccessToken))
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, []string{models.ScopeProfileRead}, contextScopes)
+		mockTokenService.AssertNotCalled(test, "ValidateAccessToken", personalAccessToken)
+	})
+
+	test.Run("Personal access token not accepted", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		_, router := gin.CreateTestContext(recorder)
+
+		personalAccessToken := models.PersonalAccessTokenPrefix + "secret"
+		mockTokenService.On("ValidateAccessToken", personalAccessToken).Return(nil, invalidTokenErr)
+
+		router.GET("/me", AuthUser(mockTokenService, nil))
+
+		request, _ := http.NewRequest(http.MethodGet, "/me", http.NoBody)
+
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", personalAccessToken))
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+	})
+
+	test.Run("Client token is limited to granted scopes", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		_, router := gin.CreateTestContext(recorder)
+
+		var contextScopes []string
+
+		router.GET("/me", AuthUser(mockTokenService, nil), func(c *gin.Context) {
+			contextScopes = c.MustGet("scopes").([]string)
+		})
+
+		request, _ := http.NewRequest(http.MethodGet, "/me", http.NoBody)
+
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", clientTokenHeader))
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, []string{"openid", "profile"}, contextScopes)
+	})
+}
+
+func TestAuthSession(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockTokenService := new(mocks.MockTokenService)
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{
+		UID:   uid,
+		Login: "alice",
+	}
+
+	mockTokenService.On("ValidateAccessToken", "session").Return(user, nil)
+	mockTokenService.On("ValidateAccessToken", "client").Return(user, nil)
+	mockTokenService.On("AccessTokenGrant", "session").Return(nil, nil)
+	mockTokenService.On("AccessTokenGrant", "client").Return(&models.ClientGrant{ClientID: "third-party", Scope: "openid"}, nil)
+
+	serve := func(token string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+
+		_, router := gin.CreateTestContext(recorder)
+
+		router.POST("/me/tokens", AuthSession(mockTokenService), func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+
+		request, _ := http.NewRequest(http.MethodPost, "/me/tokens", http.NoBody)
+
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("Session token", func(test *testing.T) {
+		assert.Equal(test, http.StatusCreated, serve("session").Code)
+	})
+
+	test.Run("Client token", func(test *testing.T) {
+		recorder := serve("client")
+
+		assert.Equal(test, http.StatusForbidden, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), string(apperrors.InsufficientScope))
+	})
 }
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// methods and headers allowed when config does not list them
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", CSRFHeader}
+)
+
+// CORSConfig lists origins other than the API's own that may call it from browsers
+type CORSConfig struct {
+	// "*" allows any origin, it cannot be combined with credentials
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// how long browsers may cache preflight response
+	MaxAge time.Duration
+}
+
+// answers preflight requests and sets CORS headers for allowed origins
+// must be used on the engine, so preflight of any route reaches it
+func CORS(config *CORSConfig) gin.HandlerFunc {
+	methods := config.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+
+	headers := config.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+
+	allowedOrigins := map[string]bool{}
+	for _, origin := range config.AllowedOrigins {
+		allowedOrigins[strings.TrimSuffix(origin, "/")] = true
+	}
+
+	anyOrigin := allowedOrigins["*"] && !config.AllowCredentials
+
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+
+		header := ctx.Writer.Header()
+		header.Add("Vary", "Origin")
+
+		preflight := ctx.Request.Method == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != ""
+
+		if !anyOrigin && !allowedOrigins[origin] {
+			if preflight {
+				ctx.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+
+			// browser hides response from origin without CORS headers
+			ctx.Next()
+			return
+		}
+
+		if anyOrigin {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if len(config.ExposedHeaders) != 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(config.ExposedHeaders, ", "))
+		}
+
+		if !preflight {
+			ctx.Next()
+			return
+		}
+
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+
+		if config.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.FormatInt(int64(config.MaxAge.Seconds()), 10))
+		}
+
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
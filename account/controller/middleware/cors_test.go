@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(config *CORSConfig) *gin.Engine {
+		_, router := gin.CreateTestContext(httptest.NewRecorder())
+
+		router.Use(CORS(config))
+		router.GET("/me", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		return router
+	}
+
+	serve := func(router *gin.Engine, method string, origin string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, "/me", http.NoBody)
+
+		if origin != "" {
+			request.Header.Set("Origin", origin)
+		}
+
+		if method == http.MethodOptions {
+			request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	router := newRouter(&CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com/"},
+		AllowCredentials: true,
+		ExposedHeaders:   []string{"ETag"},
+		MaxAge:           10 * time.Minute,
+	})
+
+	test.Run("Request without origin", func(test *testing.T) {
+		recorder := serve(router, http.MethodGet, "")
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Empty(test, recorder.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	test.Run("Allowed origin", func(test *testing.T) {
+		recorder := serve(router, http.MethodGet, "https://app.example.com")
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, "https://app.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(test, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(test, "ETag", recorder.Header().Get("Access-Control-Expose-Headers"))
+		assert.Equal(test, "Origin", recorder.Header().Get("Vary"))
+	})
+
+	test.Run("Disallowed origin", func(test *testing.T) {
+		recorder := serve(router, http.MethodGet, "https://evil.example.com")
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Empty(test, recorder.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	test.Run("Preflight", func(test *testing.T) {
+		recorder := serve(router, http.MethodOptions, "https://app.example.com")
+
+		assert.Equal(test, http.StatusNoContent, recorder.Code)
+		assert.Equal(test, "GET, POST, PUT, PATCH, DELETE", recorder.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(test, "Authorization, Content-Type, X-CSRF-Token", recorder.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(test, "600", recorder.Header().Get("Access-Control-Max-Age"))
+	})
+
+	test.Run("Preflight from disallowed origin", func(test *testing.T) {
+		recorder := serve(router, http.MethodOptions, "https://evil.example.com")
+
+		assert.Equal(test, http.StatusForbidden, recorder.Code)
+	})
+
+	test.Run("Any origin without credentials", func(test *testing.T) {
+		router := newRouter(&CORSConfig{AllowedOrigins: []string{"*"}})
+
+		recorder := serve(router, http.MethodGet, "https://any.example.com")
+
+		assert.Equal(test, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(test, recorder.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	test.Run("Any origin is ignored with credentials", func(test *testing.T) {
+		router := newRouter(&CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+
+		recorder := serve(router, http.MethodGet, "https://any.example.com")
+
+		assert.Empty(test, recorder.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
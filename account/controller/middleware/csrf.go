@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"memorize/models/apperrors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// double-submit token is set as readable cookie and sent back by browser clients in header
+const (
+	CSRFCookie = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// rejects state-changing requests that carry session or csrf cookie
+// unless CSRFHeader matches CSRFCookie
+// requests without cookies authenticate with headers only and are not checked
+func CSRF(sessionCookie string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			ctx.Next()
+			return
+		}
+
+		_, sessionErr := ctx.Cookie(sessionCookie)
+		token, csrfErr := ctx.Cookie(CSRFCookie)
+
+		if sessionErr != nil && csrfErr != nil {
+			ctx.Next()
+			return
+		}
+
+		header := ctx.GetHeader(CSRFHeader)
+
+		if csrfErr != nil || header == "" || subtle.ConstantTimeCompare([]byte(token), []byte(header)) != 1 {
+			err := apperrors.NewForbidden("Missing or invalid CSRF token").WithCode(apperrors.CSRFTokenInvalid)
+			RespondError(ctx, err)
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRF(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(recorder)
+
+	router.Use(CSRF("session"))
+	router.GET("/tokens", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/tokens", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(method string, cookies map[string]string, header string) int {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, "/tokens", http.NoBody)
+
+		for name, value := range cookies {
+			request.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+
+		if header != "" {
+			request.Header.Set(CSRFHeader, header)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder.Code
+	}
+
+	test.Run("Request without cookies", func(test *testing.T) {
+		assert.Equal(test, http.StatusOK, serve(http.MethodPost, nil, ""))
+	})
+
+	test.Run("Safe method", func(test *testing.T) {
+		assert.Equal(test, http.StatusOK, serve(http.MethodGet, map[string]string{"session": "s"}, ""))
+	})
+
+	test.Run("Matching token", func(test *testing.T) {
+		cookies := map[string]string{"session": "s", CSRFCookie: "token"}
+		assert.Equal(test, http.StatusOK, serve(http.MethodPost, cookies, "token"))
+	})
+
+	test.Run("Missing header", func(test *testing.T) {
+		cookies := map[string]string{"session": "s", CSRFCookie: "token"}
+		assert.Equal(test, http.StatusForbidden, serve(http.MethodPost, cookies, ""))
+	})
+
+	test.Run("Wrong header", func(test *testing.T) {
+		cookies := map[string]string{CSRFCookie: "token"}
+		assert.Equal(test, http.StatusForbidden, serve(http.MethodPost, cookies, "other"))
+	})
+
+	test.Run("Session cookie without csrf cookie", func(test *testing.T) {
+		cookies := map[string]string{"session": "s"}
+		assert.Equal(test, http.StatusForbidden, serve(http.MethodPost, cookies, "token"))
+	})
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"memorize/i18n"
+	"memorize/models"
+	"memorize/models/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale is language of messages responded to request
+// preference of signed in user wins over Accept-Language header
+func Locale(ctx *gin.Context) string {
+	if value, exists := ctx.Get("user"); exists {
+		if user, ok := value.(*models.User); ok && i18n.Supports(user.Locale) {
+			return user.Locale
+		}
+	}
+
+	return i18n.Negotiate(ctx.GetHeader("Accept-Language"))
+}
+
+// copy of err with message in locale of request, err itself may be shared
+func localize(ctx *gin.Context, err *apperrors.Error) *apperrors.Error {
+	message, ok := i18n.ErrorMessage(Locale(ctx), err)
+	if !ok {
+		return err
+	}
+
+	localized := *err
+	localized.Message = message
+
+	return &localized
+}
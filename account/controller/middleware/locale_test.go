@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"encoding/json"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocale(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	err := apperrors.NewAuthorization("Account is disabled").WithCode(apperrors.AccountDisabled)
+
+	serve := func(user *models.User, acceptLanguage string) *apperrors.Error {
+		recorder := httptest.NewRecorder()
+		_, router := gin.CreateTestContext(recorder)
+
+		router.GET("/me", func(c *gin.Context) {
+			if user != nil {
+				c.Set("user", user)
+			}
+
+			RespondError(c, err)
+		})
+
+		request, _ := http.NewRequest(http.MethodGet, "/me", http.NoBody)
+		request.Header.Set("Accept-Language", acceptLanguage)
+		router.ServeHTTP(recorder, request)
+
+		var body struct {
+			Error *apperrors.Error `json:"error"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &body)
+
+		return body.Error
+	}
+
+	test.Run("Accept-Language", func(test *testing.T) {
+		responded := serve(nil, "de-DE,de;q=0.9")
+
+		assert.Equal(test, "Das Konto ist deaktiviert", responded.Message)
+		assert.Equal(test, apperrors.AccountDisabled, responded.Code)
+		assert.Equal(test, "Account is disabled", err.Message)
+	})
+
+	test.Run("User preference", func(test *testing.T) {
+		responded := serve(&models.User{Locale: "en"}, "de")
+
+		assert.Equal(test, "Account is disabled", responded.Message)
+	})
+
+	test.Run("Unsupported user preference", func(test *testing.T) {
+		responded := serve(&models.User{Locale: "fr"}, "de")
+
+		assert.Equal(test, "Das Konto ist deaktiviert", responded.Message)
+	})
+}
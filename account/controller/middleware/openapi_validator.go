@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"memorize/i18n"
+	"memorize/models/apperrors"
+	"memorize/openapi"
+	"memorize/validation"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validator tags of schema keywords, so messages match those of binding errors
+var violationTags = map[string]string{
+	"required":  "required",
+	"minLength": "min",
+	"maxLength": "max",
+	"minItems":  "min",
+	"maxItems":  "max",
+	"minimum":   "gte",
+	"maximum":   "lte",
+	"enum":      "oneof",
+	"type":      "type",
+}
+
+// formats of schema by validator tag
+var formatTags = map[string]string{
+	"email": "email",
+	"uri":   "url",
+	"uuid":  "uuid",
+}
+
+// OpenAPIValidator checks JSON requests and responses against operations of doc.
+// It is meant for contract tests and staging, responses are buffered until they are validated
+func OpenAPIValidator(doc *openapi.Document, validateRequests bool, validateResponses bool) gin.HandlerFunc {
+	basePath := ""
+	if len(doc.Servers) > 0 {
+		basePath = doc.Servers[0].URL
+	}
+
+	return func(ctx *gin.Context) {
+		operation := doc.Operation(ctx.Request.Method, strings.TrimPrefix(ctx.FullPath(), basePath))
+
+		// routes that are not documented are not validated
+		if operation == nil {
+			ctx.Next()
+			return
+		}
+
+		if validateRequests {
+			if violations := validateRequest(doc, operation, ctx); len(violations) > 0 {
+				RespondError(ctx, invalidRequest(violations, Locale(ctx)))
+				return
+			}
+		}
+
+		if !validateResponses {
+			ctx.Next()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: ctx.Writer, status: http.StatusOK}
+		ctx.Writer = writer
+
+		ctx.Next()
+
+		ctx.Writer = writer.ResponseWriter
+
+		if violations := validateResponse(doc, operation, writer); len(violations) > 0 {
+			err := fmt.Errorf("response of %v %v does not match OpenAPI document: %v", ctx.Request.Method, ctx.FullPath(), violations)
+			RespondError(ctx, apperrors.NewInternal().Wrap(err))
+			return
+		}
+
+		writer.flush()
+	}
+}
+
+// body is read and put back for the handler to bind, bodies that are not valid JSON
+// are left to the handler so it reports them like without validator
+func validateRequest(doc *openapi.Document, operation *openapi.Operation, ctx *gin.Context) []openapi.Violation {
+	if operation.RequestBody == nil || ctx.Request.Body == nil {
+		return nil
+	}
+
+	// only JSON bodies, like application/merge-patch+json, are validated
+	contentType := ctx.ContentType()
+	media, ok := operation.RequestBody.Content[contentType]
+	if !ok || (contentType != "application/json" && !strings.HasSuffix(contentType, "+json")) {
+		return nil
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var value interface{}
+	if err != nil || json.Unmarshal(body, &value) != nil {
+		return nil
+	}
+
+	return doc.Validate(media.Schema, value)
+}
+
+func validateResponse(doc *openapi.Document, operation *openapi.Operation, writer *bufferedWriter) []openapi.Violation {
+	response, ok := operation.Responses[strconv.Itoa(writer.status)]
+	if !ok {
+		response, ok = operation.Responses["default"]
+	}
+
+	if !ok {
+		return []openapi.Violation{{Message: fmt.Sprintf("status %d is not documented", writer.status)}}
+	}
+
+	if writer.body.Len() == 0 {
+		return nil
+	}
+
+	contentType, _, _ := mime.ParseMediaType(writer.Header().Get("Content-Type"))
+
+	media, ok := response.Content[contentType]
+	if !ok {
+		return []openapi.Violation{{Message: fmt.Sprintf("content type %q of status %d is not documented", contentType, writer.status)}}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(writer.body.Bytes(), &value); err != nil {
+		return []openapi.Violation{{Message: "body is not valid JSON: " + err.Error()}}
+	}
+
+	return doc.Validate(media.Schema, value)
+}
+
+// violations are reported like failed binding
+func invalidRequest(violations []openapi.Violation, locale string) *apperrors.Error {
+	invalidArgs := make([]validation.InvalidArgument, 0, len(violations))
+
+	for _, violation := range violations {
+		tag := violationTags[violation.Keyword]
+		if violation.Keyword == "format" {
+			tag = formatTags[violation.Param]
+		}
+
+		invalidArgs = append(invalidArgs, validation.InvalidArgument{
+			Field:   violation.Field,
+			Value:   violation.Value,
+			Tag:     violation.Keyword,
+			Param:   violation.Param,
+			Message: i18n.ValidationMessage(locale, violation.Field, tag, violation.Param),
+		})
+	}
+
+	return apperrors.NewBadRequest("Invalid request parameters. See details").
+		WithCode(apperrors.ValidationFailed).
+		WithDetail("invalidArgs", invalidArgs)
+}
+
+// bufferedWriter holds response until it is validated
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(data string) (int, error) {
+	return w.body.WriteString(data)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// writes validated response to client
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if _, err := w.ResponseWriter.Write(w.body.Bytes()); err != nil {
+		log.Printf("Failed to write validated response: %v\n", err)
+	}
+}
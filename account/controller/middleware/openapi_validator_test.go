@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"encoding/json"
+	"memorize/openapi"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type detailsBody struct {
+	Name  string `json:"name" binding:"required,max=5"`
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+type detailsResponse struct {
+	Name string `json:"name"`
+}
+
+func TestOpenAPIValidator(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doc := openapi.New("test", "1.0.0", "/api")
+	doc.Add("PUT", "/details", &openapi.Operation{
+		OperationID: "putDetails",
+		RequestBody: doc.JSONBody(detailsBody{}),
+		Responses: map[string]*openapi.Response{
+			"200": doc.JSONResponse("details", detailsResponse{}),
+		},
+	})
+
+	serve := func(body string, response interface{}) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		_, router := gin.CreateTestContext(recorder)
+
+		handled := false
+		group := router.Group("/api", OpenAPIValidator(doc, true, true))
+		group.PUT("/details", func(ctx *gin.Context) {
+			var request detailsBody
+			ctx.ShouldBindJSON(&request)
+			handled = request.Name != ""
+
+			ctx.JSON(http.StatusOK, response)
+		})
+
+		request, _ := http.NewRequest(http.MethodPut, "/api/details", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		if handled {
+			recorder.Header().Set("X-Handled", "true")
+		}
+
+		return recorder
+	}
+
+	test.Run("Valid request and response", func(test *testing.T) {
+		recorder := serve(`{"name":"Bob"}`, gin.H{"name": "Bob"})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, "true", recorder.Header().Get("X-Handled"))
+		assert.JSONEq(test, `{"name":"Bob"}`, recorder.Body.String())
+	})
+
+	test.Run("Invalid request", func(test *testing.T) {
+		recorder := serve(`{"name":"Robert","email":"bob"}`, gin.H{"name": "Bob"})
+
+		var body struct {
+			Error struct {
+				Code    string `json:"code"`
+				Details struct {
+					InvalidArgs []map[string]interface{} `json:"invalidArgs"`
+				} `json:"details"`
+			} `json:"error"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &body)
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		assert.Empty(test, recorder.Header().Get("X-Handled"))
+		assert.Equal(test, "VALIDATION_FAILED", body.Error.Code)
+		assert.Len(test, body.Error.Details.InvalidArgs, 2)
+		assert.Equal(test, "email", body.Error.Details.InvalidArgs[0]["field"])
+		assert.Equal(test, "email must be a valid email address", body.Error.Details.InvalidArgs[0]["message"])
+		assert.Equal(test, "name must be at most 5 characters long", body.Error.Details.InvalidArgs[1]["message"])
+	})
+
+	test.Run("Invalid response", func(test *testing.T) {
+		recorder := serve(`{"name":"Bob"}`, gin.H{"name": 5})
+
+		assert.Equal(test, http.StatusInternalServerError, recorder.Code)
+		assert.NotContains(test, recorder.Body.String(), `"name"`)
+	})
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"fmt"
+	"memorize/models/apperrors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rejects personal access tokens and tokens of OAuth clients without any of scopes
+// must run after AuthUser, access tokens of account service have no scopes set and are not limited
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value, exists := ctx.Get("scopes")
+
+		if !exists {
+			ctx.Next()
+			return
+		}
+
+		for _, granted := range value.([]string) {
+			for _, scope := range scopes {
+				if granted == scope {
+					ctx.Next()
+					return
+				}
+			}
+		}
+
+		err := apperrors.NewForbidden(fmt.Sprintf("Token does not have %v scope", strings.Join(scopes, " or "))).WithCode(apperrors.InsufficientScope)
+		RespondError(ctx, err)
+	}
+}
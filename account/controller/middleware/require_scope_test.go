@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"memorize/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScope(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(setScopes gin.HandlerFunc) int {
+		recorder := httptest.NewRecorder()
+
+		_, router := gin.CreateTestContext(recorder)
+
+		router.GET("/me", setScopes, RequireScope(models.ScopeProfileRead), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		request, _ := http.NewRequest(http.MethodGet, "/me", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		return recorder.Code
+	}
+
+	test.Run("Access token is not limited", func(test *testing.T) {
+		assert.Equal(test, http.StatusOK, serve(func(c *gin.Context) {}))
+	})
+
+	test.Run("Token with scope", func(test *testing.T) {
+		assert.Equal(test, http.StatusOK, serve(func(c *gin.Context) {
+			c.Set("scopes", []string{models.ScopeSessions, models.ScopeProfileRead})
+		}))
+	})
+
+	test.Run("Token with any of scopes", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		_, router := gin.CreateTestContext(recorder)
+
+		router.GET("/userinfo", func(c *gin.Context) {
+			c.Set("scopes", []string{"openid"})
+		}, RequireScope(models.ScopeProfileRead, "openid"), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		request, _ := http.NewRequest(http.MethodGet, "/userinfo", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+	})
+
+	test.Run("Token without scope", func(test *testing.T) {
+		assert.Equal(test, http.StatusForbidden, serve(func(c *gin.Context) {
+			c.Set("scopes", []string{models.ScopeSessions})
+		}))
+	})
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"memorize/models/apperrors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const problemJSONKey = "problemJSON"
+
+// makes error responses of following handlers RFC 7807 problem details,
+// without it only clients that accept application/problem+json get them
+func ProblemJSON() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(problemJSONKey, true)
+		ctx.Next()
+	}
+}
+
+// RespondError writes error response and aborts the chain
+// errors other than *apperrors.Error are responded as internal errors
+func RespondError(ctx *gin.Context, err error) {
+	appErr := apperrors.From(err)
+	logIncident(appErr)
+
+	contentType, body := errorBody(ctx, appErr)
+
+	ctx.Header("Content-Type", contentType)
+	ctx.AbortWithStatusJSON(appErr.Status(), body)
+}
+
+// internal errors are logged with their causes, response only has incident id
+func logIncident(err *apperrors.Error) {
+	if err.IncidentID != "" {
+		log.Printf("Incident %v: %v\n", err.IncidentID, err)
+	}
+}
+
+// error response in format the request asked for
+func errorBody(ctx *gin.Context, err *apperrors.Error) (string, interface{}) {
+	err = localize(ctx, err)
+
+	if ctx.GetBool(problemJSONKey) || strings.Contains(ctx.GetHeader("Accept"), apperrors.ProblemContentType) {
+		return apperrors.ProblemContentType, err.Problem(ctx.Request.URL.Path)
+	}
+
+	return "application/json; charset=utf-8", gin.H{
+		"error": err,
+	}
+}
+
+// marshalError is errorBody for writers that bypass gin rendering
+func marshalError(ctx *gin.Context, err *apperrors.Error) (string, []byte) {
+	contentType, body := errorBody(ctx, err)
+	data, _ := json.Marshal(body)
+
+	return contentType, data
+}
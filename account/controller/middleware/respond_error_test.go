@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRespondError(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(err error, accept string, handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		_, router := gin.CreateTestContext(recorder)
+
+		router.Use(handlers...)
+		router.POST("/signup", func(c *gin.Context) {
+			RespondError(c, err)
+		})
+
+		request, _ := http.NewRequest(http.MethodPost, "/signup", http.NoBody)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	conflict := apperrors.NewConflict("login", "alice").WithCode(apperrors.LoginTaken)
+
+	test.Run("Error object", func(test *testing.T) {
+		recorder := serve(conflict, "")
+
+		respBody, _ := json.Marshal(gin.H{
+			"error": conflict,
+		})
+
+		assert.Equal(test, http.StatusConflict, recorder.Code)
+		assert.Equal(test, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+		assert.Equal(test, respBody, recorder.Body.Bytes())
+	})
+
+	test.Run("Problem details accepted by client", func(test *testing.T) {
+		recorder := serve(conflict, apperrors.ProblemContentType)
+
+		problem := &apperrors.Problem{}
+		json.Unmarshal(recorder.Body.Bytes(), problem)
+
+		assert.Equal(test, http.StatusConflict, recorder.Code)
+		assert.Equal(test, apperrors.ProblemContentType, recorder.Header().Get("Content-Type"))
+		assert.Equal(test, "urn:memorize:problem:login-taken", problem.Type)
+		assert.Equal(test, "Conflict", problem.Title)
+		assert.Equal(test, http.StatusConflict, problem.Status)
+		assert.Equal(test, "/signup", problem.Instance)
+		assert.Equal(test, apperrors.LoginTaken, problem.Code)
+		assert.Equal(test, "alice", problem.Details["value"])
+	})
+
+	test.Run("Problem details enabled", func(test *testing.T) {
+		recorder := serve(conflict, "application/json", ProblemJSON())
+
+		assert.Equal(test, apperrors.ProblemContentType, recorder.Header().Get("Content-Type"))
+	})
+
+	test.Run("Unknown error is internal", func(test *testing.T) {
+		recorder := serve(errors.New("connection refused"), "")
+
+		var body struct {
+			Error *apperrors.Error `json:"error"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &body)
+
+		assert.Equal(test, http.StatusInternalServerError, recorder.Code)
+		assert.Equal(test, apperrors.Internal, body.Error.Type)
+		assert.NotEmpty(test, body.Error.IncidentID)
+		assert.NotContains(test, recorder.Body.String(), "connection refused")
+	})
+
+	test.Run("Internal error keeps cause", func(test *testing.T) {
+		cause := errors.New("connection refused")
+		internal := apperrors.NewInternal().Wrap(cause)
+
+		recorder := serve(internal, apperrors.ProblemContentType)
+
+		problem := &apperrors.Problem{}
+		json.Unmarshal(recorder.Body.Bytes(), problem)
+
+		assert.True(test, errors.Is(internal, cause))
+		assert.Equal(test, internal.IncidentID, problem.IncidentID)
+		assert.NotContains(test, recorder.Body.String(), "connection refused")
+	})
+}
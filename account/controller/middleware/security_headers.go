@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// policy for JSON responses, they never load anything or get framed
+const DefaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// SecurityHeadersConfig holds values of security headers set on every response
+type SecurityHeadersConfig struct {
+	// HSTS is not sent when zero, it should be set only where API is served over https
+	HSTSMaxAge time.Duration
+	// handlers serving HTML may replace it with their own policy
+	ContentSecurityPolicy string
+}
+
+// sets security headers on every response
+func SecurityHeaders(config *SecurityHeadersConfig) gin.HandlerFunc {
+	policy := config.ContentSecurityPolicy
+	if policy == "" {
+		policy = DefaultContentSecurityPolicy
+	}
+
+	var hsts string
+	if config.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d; includeSubDomains", int64(config.HSTSMaxAge.Seconds()))
+	}
+
+	return func(ctx *gin.Context) {
+		header := ctx.Writer.Header()
+
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("Referrer-Policy", "no-referrer")
+		header.Set("Content-Security-Policy", policy)
+
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+
+		ctx.Next()
+	}
+}
+
+// forbids caching of responses that contain tokens
+func NoStore() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Cache-Control", "no-store")
+		ctx.Header("Pragma", "no-cache")
+
+		ctx.Next()
+	}
+}
+
+// replaces policy of SecurityHeaders for routes that serve pages
+func ContentSecurityPolicy(policy string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Content-Security-Policy", policy)
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeaders(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(config *SecurityHeadersConfig, handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		_, router := gin.CreateTestContext(recorder)
+
+		router.Use(SecurityHeaders(config))
+		router.GET("/me", append(handlers, func(c *gin.Context) { c.Status(http.StatusOK) })...)
+
+		request, _ := http.NewRequest(http.MethodGet, "/me", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("Defaults", func(test *testing.T) {
+		recorder := serve(&SecurityHeadersConfig{})
+
+		assert.Equal(test, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+		assert.Equal(test, "DENY", recorder.Header().Get("X-Frame-Options"))
+		assert.Equal(test, DefaultContentSecurityPolicy, recorder.Header().Get("Content-Security-Policy"))
+		assert.Empty(test, recorder.Header().Get("Strict-Transport-Security"))
+	})
+
+	test.Run("HSTS and custom policy", func(test *testing.T) {
+		recorder := serve(&SecurityHeadersConfig{
+			HSTSMaxAge:            365 * 24 * time.Hour,
+			ContentSecurityPolicy: "default-src 'self'",
+		})
+
+		assert.Equal(test, "max-age=31536000; includeSubDomains", recorder.Header().Get("Strict-Transport-Security"))
+		assert.Equal(test, "default-src 'self'", recorder.Header().Get("Content-Security-Policy"))
+	})
+
+	test.Run("No store", func(test *testing.T) {
+		recorder := serve(&SecurityHeadersConfig{}, NoStore())
+
+		assert.Equal(test, "no-store", recorder.Header().Get("Cache-Control"))
+		assert.Equal(test, "no-cache", recorder.Header().Get("Pragma"))
+	})
+}
@@ -3,7 +3,6 @@ package middleware
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"memorize/models/apperrors"
 	"net/http"
@@ -47,8 +46,8 @@ func Timeout(timeout time.Duration, errTimeout *apperrors.Error) gin.HandlerFunc
 		}()
 
 		select {
-		case <-panicChan:
-			handlePanic(timeoutWr)
+		case p := <-panicChan:
+			handlePanic(timeoutWr, ctx, p)
 		case <-finished:
 			handleFinished(timeoutWr)
 		case <-timeoutContext.Done():
@@ -57,15 +56,16 @@ func Timeout(timeout time.Duration, errTimeout *apperrors.Error) gin.HandlerFunc
 	}
 }
 
-func handlePanic(timeoutWr *timeoutWriter) {
+func handlePanic(timeoutWr *timeoutWriter, ginContext *gin.Context, p interface{}) {
 	// if we cannot recover from panic,
 	// send internal server error
-	err := apperrors.NewInternal()
-	timeoutWr.ResponseWriter.WriteHeader(err.Status())
-	eResp, _ := json.Marshal(gin.H{
-		"error": err,
-	})
+	err := apperrors.NewInternal().Wrap(fmt.Errorf("panic: %v", p))
+	logIncident(err)
+
+	contentType, eResp := marshalError(ginContext, err)
 
+	timeoutWr.ResponseWriter.Header().Set("Content-Type", contentType)
+	timeoutWr.ResponseWriter.WriteHeader(err.Status())
 	timeoutWr.ResponseWriter.Write(eResp)
 }
 
@@ -90,14 +90,11 @@ func handleTimeout(timeoutWr *timeoutWriter, errTimeout *apperrors.Error, ginCon
 	// timeout has occurred, send errTimeout and write headers
 	timeoutWr.mutex.Lock()
 	defer timeoutWr.mutex.Unlock()
+	contentType, errorResponse := marshalError(ginContext, errTimeout)
+
 	// ResponseWriter from gin
-	timeoutWr.ResponseWriter.Header().Set("Content-Type", "application/json")
+	timeoutWr.ResponseWriter.Header().Set("Content-Type", contentType)
 	timeoutWr.ResponseWriter.WriteHeader(errTimeout.Status())
-
-	errorResponse, _ := json.Marshal(gin.H{
-		"error": errTimeout,
-	})
-
 	timeoutWr.ResponseWriter.Write(errorResponse)
 	ginContext.Abort()
 	timeoutWr.SetTimedOut()
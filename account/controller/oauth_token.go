@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthToken is the OAuth 2.0 token endpoint
+func (c *controller) OAuthToken(ctx *gin.Context) {
+	var request models.OAuthTokenRequest
+
+	if ok := bindOAuthForm(ctx, &request, &request.ClientID, &request.ClientSecret); !ok {
+		return
+	}
+
+	response, err := c.OAuthService.Token(ctx.Request.Context(), &request)
+
+	if err != nil {
+		log.Printf("Failed to issue tokens for client %v: %v\n", request.ClientID, err.Error())
+		respondOAuthError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// binds form of OAuth endpoint, client credentials from Authorization header take precedence
+// responds with error and returns false if request is invalid
+func bindOAuthForm(ctx *gin.Context, request interface{}, clientID *string, clientSecret *string) bool {
+	// OAuth endpoints use forms as required by RFC 6749
+	if ctx.ContentType() != "application/x-www-form-urlencoded" {
+		respondOAuthError(ctx, apperrors.NewOAuthError(
+			apperrors.InvalidRequest,
+			"Content-Type must be application/x-www-form-urlencoded",
+		))
+		return false
+	}
+
+	if err := ctx.ShouldBind(request); err != nil {
+		respondOAuthError(ctx, apperrors.NewOAuthError(apperrors.InvalidRequest, "Invalid request"))
+		return false
+	}
+
+	// client_secret_basic authentication
+	if basicClientID, basicClientSecret, ok := ctx.Request.BasicAuth(); ok {
+		*clientID = basicClientID
+		*clientSecret = basicClientSecret
+	}
+
+	return true
+}
+
+// respond with RFC 6749 error, other errors are reported as server_error
+func respondOAuthError(ctx *gin.Context, err error) {
+	var oauthErr *apperrors.OAuthError
+	if !errors.As(err, &oauthErr) {
+		oauthErr = apperrors.NewOAuthError(apperrors.ServerError, "Internal server error")
+	}
+
+	if oauthErr.Code == apperrors.InvalidClient {
+		ctx.Header("WWW-Authenticate", `Basic realm="token"`)
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(oauthErr.Status(), oauthErr)
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOAuthToken(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockOAuthService := new(mocks.MockOAuthService)
+
+	router := gin.Default()
+
+	NewController(&Config{
+		Router:       router,
+		OAuthService: mockOAuthService,
+	})
+
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {"abc"},
+		"redirect_uri": {"https://app.example.com/callback"},
+	}
+
+	test.Run("Success with basic authentication", func(test *testing.T) {
+		tokenResponse := &models.OAuthTokenResponse{
+			AccessToken: "access",
+			TokenType:   "Bearer",
+			ExpiresIn:   900,
+			IDToken:     "idtoken",
+		}
+
+		mockOAuthService.
+			On("Token", mock.Anything, &models.OAuthTokenRequest{
+				GrantType:    "authorization_code",
+				Code:         "abc",
+				RedirectURI:  "https://app.example.com/callback",
+				ClientID:     "client",
+				ClientSecret: "secret",
+			}).
+			Return(tokenResponse, nil)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.SetBasicAuth("client", "secret")
+		router.ServeHTTP(recorder, request)
+
+		expectedBody, _ := json.Marshal(tokenResponse)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, "no-store", recorder.Header().Get("Cache-Control"))
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+
+	test.Run("Invalid grant", func(test *testing.T) {
+		mockError := apperrors.NewOAuthError(apperrors.InvalidGrant, "Invalid authorization code")
+
+		mockOAuthService.
+			On("Token", mock.Anything, &models.OAuthTokenRequest{
+				GrantType:   "authorization_code",
+				Code:        "abc",
+				RedirectURI: "https://app.example.com/callback",
+				ClientID:    "public",
+			}).
+			Return(nil, mockError)
+
+		publicForm := url.Values{"client_id": {"public"}}
+		for key, value := range form {
+			publicForm[key] = value
+		}
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/token", strings.NewReader(publicForm.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(recorder, request)
+
+		expectedBody, _ := json.Marshal(mockError)
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+
+	test.Run("JSON body is rejected", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/token", strings.NewReader(`{"grant_type":"authorization_code"}`))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), apperrors.InvalidRequest)
+	})
+}
@@ -0,0 +1,410 @@
+package controller
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/openapi"
+	"memorize/validation"
+	"memorize/webauthn"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// page that renders openapi.json, it is embedded so docs need no other server
+//
+//go:embed docs
+var docsFiles embed.FS
+
+// docs page loads its script, style and document from this server only
+const docsContentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self' data:; frame-ancestors 'none'"
+
+// bodies of responses are gin.H maps, these types describe them in the document only
+type userResponse struct {
+	User *models.User `json:"user"`
+}
+
+type tokensResponse struct {
+	Tokens *models.TokenPair `json:"tokens"`
+}
+
+// signin responds with tokens, or with passkey options when user has second factor
+type signinResponse struct {
+	Tokens       *models.TokenPair        `json:"tokens"`
+	SecondFactor *webauthn.RequestOptions `json:"secondFactor"`
+}
+
+// signinResponseV2 has tokens unwrapped like other responses of version 2
+type signinResponseV2 struct {
+	*models.TokenPair
+	SecondFactor *webauthn.RequestOptions `json:"secondFactor"`
+}
+
+type secondFactorResponse struct {
+	SecondFactor bool `json:"secondFactor"`
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+type nonceResponse struct {
+	Nonce string `json:"nonce"`
+}
+
+type personalAccessTokensResponse struct {
+	Tokens []*models.PersonalAccessToken `json:"tokens"`
+}
+
+type personalAccessTokenResponse struct {
+	Token *models.PersonalAccessToken `json:"token"`
+}
+
+type identitiesResponse struct {
+	Identities []*models.UserIdentity `json:"identities"`
+}
+
+type providersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+type redirectResponse struct {
+	RedirectURL string `json:"redirectUrl"`
+}
+
+type creationOptionsResponse struct {
+	Options *webauthn.CreationOptions `json:"options"`
+}
+
+type requestOptionsResponse struct {
+	Options *webauthn.RequestOptions `json:"options"`
+}
+
+type passkeyResponse struct {
+	Credential *models.WebAuthnCredential `json:"credential"`
+}
+
+type passkeysResponse struct {
+	Passkeys []*models.WebAuthnCredential `json:"passkeys"`
+}
+
+// detailsPatch describes merge patch of details, null clears field
+type detailsPatch struct {
+	Name    *string `json:"name" binding:"omitempty,max=50"`
+	Email   *string `json:"email" binding:"omitempty,email"`
+	Website *string `json:"website" binding:"omitempty,url"`
+	Locale  *string `json:"locale" binding:"omitempty,oneof=en de"`
+}
+
+type errorResponse struct {
+	Error *apperrors.Error `json:"error"`
+}
+
+// userinfo claims of OpenID Connect, optional claims are left out when empty or not granted
+type userinfoResponse struct {
+	Subject           string `json:"sub"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+	Email             string `json:"email"`
+	Website           string `json:"website"`
+	Picture           string `json:"picture"`
+	Locale            string `json:"locale"`
+}
+
+// image routes are placeholders
+type imageResponse struct {
+	Hello string `json:"hello"`
+}
+
+type openIDConfigurationResponse struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// apiDocument describes routes of API version served under basePath
+func apiDocument(basePath string, version int) *openapi.Document {
+	doc := newDocument(basePath, version)
+
+	user := []map[string][]string{{"bearerAuth": {}}}
+	service := []map[string][]string{{"serviceToken": {}}}
+
+	errors := errorsResponse(doc)
+
+	add := func(method string, path string, tag string, summary string, body *openapi.RequestBody, status string, response *openapi.Response, security []map[string][]string) {
+		doc.Add(method, path, &openapi.Operation{
+			OperationID: operationID(method, path),
+			Summary:     summary,
+			Tags:        []string{tag},
+			RequestBody: body,
+			Responses: map[string]*openapi.Response{
+				status:    response,
+				"default": errors,
+			},
+			Security: security,
+		})
+	}
+
+	tokens := doc.JSONResponse("tokens of user, refresh token is a cookie in session cookie mode for requests with Origin or X-Session-Cookies: true", tokensResponse{})
+	signin := doc.JSONResponse("tokens, or passkey options when user has second factor", signinResponse{})
+
+	// since version 2 tokens are not wrapped
+	if version >= 2 {
+		tokens = doc.JSONResponse("tokens of user, refresh token is a cookie in session cookie mode for requests with Origin or X-Session-Cookies: true", models.TokenPair{})
+		signin = doc.JSONResponse("tokens, or passkey options when user has second factor", signinResponseV2{})
+	}
+
+	add("POST", "/signup", "auth", "Create user", doc.JSONBody(signinRequest{}), "201", tokens, nil)
+	add("POST", "/signin", "auth", "Sign in with login and password", doc.JSONBody(signinRequset{}), "200", signin, nil)
+	add("POST", "/signin/link", "auth", "Mail signin link", doc.JSONBody(magicLinkRequest{}), "202", doc.JSONResponse("nonce of device that requested link", nonceResponse{}), nil)
+	add("POST", "/signin/link/verify", "auth", "Sign in with link", doc.JSONBody(magicLinkVerifyRequest{}), "200", signin, nil)
+	add("POST", "/signin/social", "social", "Sign in with code external provider callback redirected with", doc.JSONBody(socialSigninRequest{}), "200", tokens, nil)
+	add("POST", "/tokens", "auth", "Exchange refresh token for new tokens", doc.JSONBody(tokensRequest{}), "200", tokens, nil)
+	add("POST", "/signout", "auth", "Revoke refresh tokens of user", nil, "200", doc.JSONResponse("user signed out", messageResponse{}), user)
+
+	add("GET", "/me", "user", "Get signed in user", nil, "200", doc.JSONResponse("user", userResponse{}), user)
+	add("PUT", "/details", "user", "Replace details of signed in user", doc.JSONBody(detailsRequset{}), "200", doc.JSONResponse("updated user", userResponse{}), user)
+
+	// merge patch is also accepted as application/json
+	patch := doc.JSONBody(detailsPatch{})
+	patch.Content[mergePatchContentType] = patch.Content["application/json"]
+	add("PATCH", "/details", "user", "Change some details of signed in user, null clears field", patch, "200", doc.JSONResponse("updated user", userResponse{}), user)
+	conditionalRequests(doc, version)
+	add("GET", "/userinfo", "user", "OpenID Connect claims of signed in user, limited to scope granted to client", nil, "200", doc.JSONResponse("claims", userinfoResponse{}), user)
+
+	add("GET", "/users/:uid", "user", "Get user for backend service", nil, "200", doc.JSONResponse("user", userResponse{}), service)
+
+	add("PUT", "/login", "login", "Change login, tokens are reissued with new login", doc.JSONBody(loginRequest{}), "200", tokens, user)
+	add("GET", "/login/availability", "login", "Check if login can be taken", nil, "200", doc.JSONResponse("availability of login", loginAvailabilityResponse{}), user)
+	add("GET", "/logins/:login", "login", "Get user by login for backend service", nil, "200", doc.JSONResponse("user", userResponse{}), service)
+	loginOperations(doc)
+
+	add("GET", "/me/tokens", "tokens", "List personal access tokens", nil, "200", doc.JSONResponse("tokens without secret", personalAccessTokensResponse{}), user)
+	add("POST", "/me/tokens", "tokens", "Create personal access token", doc.JSONBody(personalAccessTokenRequest{}), "201", doc.JSONResponse("token with secret, it is shown only once", personalAccessTokenResponse{}), user)
+	add("DELETE", "/me/tokens/:id", "tokens", "Revoke personal access token", nil, "204", openapi.EmptyResponse("token deleted"), user)
+
+	add("GET", "/me/identities", "social", "List linked identities", nil, "200", doc.JSONResponse("identities", identitiesResponse{}), user)
+	add("POST", "/oauth/:provider/link", "social", "Begin linking external identity", nil, "200", doc.JSONResponse("authorization url of provider", redirectResponse{}), user)
+	add("DELETE", "/oauth/:provider/link", "social", "Unlink external identity", nil, "204", openapi.EmptyResponse("identity unlinked"), user)
+
+	add("POST", "/webauthn/register/begin", "passkeys", "Begin passkey registration", nil, "200", doc.JSONResponse("credential creation options", creationOptionsResponse{}), user)
+	add("POST", "/webauthn/register/finish", "passkeys", "Finish passkey registration", doc.JSONBody(passkeyRegistrationRequest{}), "201", doc.JSONResponse("registered passkey", passkeyResponse{}), user)
+	add("POST", "/webauthn/signin/begin", "passkeys", "Begin passkey signin", doc.JSONBody(passkeySigninRequest{}), "200", doc.JSONResponse("credential request options", requestOptionsResponse{}), nil)
+	add("POST", "/webauthn/signin/finish", "passkeys", "Finish passkey signin", doc.JSONBody(passkeyAssertionRequest{}), "200", tokens, nil)
+	add("GET", "/me/passkeys", "passkeys", "List passkeys", nil, "200", doc.JSONResponse("passkeys", passkeysResponse{}), user)
+	add("DELETE", "/me/passkeys/:id", "passkeys", "Delete passkey", nil, "204", openapi.EmptyResponse("passkey deleted"), user)
+	add("PUT", "/me/second-factor", "passkeys", "Require passkey after password signin", doc.JSONBody(secondFactorRequest{}), "200", doc.JSONResponse("second factor setting", secondFactorResponse{}), user)
+
+	add("POST", "/image", "user", "Upload profile image, not implemented yet", nil, "200", doc.JSONResponse("placeholder", imageResponse{}), nil)
+	add("DELETE", "/image", "user", "Delete profile image, not implemented yet", nil, "200", doc.JSONResponse("placeholder", imageResponse{}), nil)
+
+	add("GET", "/openapi.json", "docs", "OpenAPI document of this API version", nil, "200", &openapi.Response{
+		Description: "this document",
+		Content: map[string]*openapi.MediaType{
+			"application/json": {Schema: &openapi.Schema{Type: "object"}},
+		},
+	}, nil)
+
+	return doc
+}
+
+// user is fetched with ETag and updated with If-Match, which is required since version 2
+func conditionalRequests(doc *openapi.Document, version int) {
+	etag := map[string]*openapi.Header{
+		"ETag": {Description: "entity tag of user, it changes with every update", Schema: &openapi.Schema{Type: "string"}},
+	}
+
+	me := doc.Operation("GET", "/me")
+	me.Parameters = append(me.Parameters, &openapi.Parameter{
+		Name:        "If-None-Match",
+		In:          "header",
+		Description: "entity tag of user client has, user is not sent again when it matches",
+		Schema:      &openapi.Schema{Type: "string"},
+	})
+	me.Responses["200"].Headers = etag
+	me.Responses["304"] = &openapi.Response{Description: "user has not changed", Headers: etag}
+
+	for _, method := range []string{"PUT", "PATCH"} {
+		details := doc.Operation(method, "/details")
+		details.Parameters = append(details.Parameters, &openapi.Parameter{
+			Name:        "If-Match",
+			In:          "header",
+			Description: "entity tag of user from GET /me, update fails with 412 when user has changed since",
+			Required:    version >= 2,
+			Schema:      &openapi.Schema{Type: "string"},
+		})
+		details.Responses["200"].Headers = etag
+	}
+}
+
+// availability has login in query, previous logins redirect to current ones
+func loginOperations(doc *openapi.Document) {
+	availability := doc.Operation("GET", "/login/availability")
+	availability.Parameters = append(availability.Parameters, &openapi.Parameter{
+		Name:     "login",
+		In:       "query",
+		Required: true,
+		Schema:   &openapi.Schema{Type: "string", Pattern: validation.Patterns["login"].String()},
+	})
+
+	doc.Operation("GET", "/logins/:login").Responses["307"] = openapi.RedirectResponse("login is previous login of user, Location has current one")
+}
+
+// protocolDocument describes unversioned protocol routes served under basePath
+func protocolDocument(basePath string) *openapi.Document {
+	doc := newDocument(basePath, 1)
+	protocolOperations(doc)
+
+	return doc
+}
+
+// protocolOperations adds OAuth and external provider endpoints to doc
+func protocolOperations(doc *openapi.Document) {
+	oauthErrors := doc.JSONResponse("OAuth error", apperrors.OAuthError{})
+
+	// OAuth endpoints answer with errors of RFC 6749 instead of application errors
+	oauth := func(method string, path string, summary string, body *openapi.RequestBody, response *openapi.Response) {
+		doc.Add(method, path, &openapi.Operation{
+			OperationID: operationID(method, path),
+			Summary:     summary,
+			Tags:        []string{"oauth"},
+			RequestBody: body,
+			Responses: map[string]*openapi.Response{
+				"200":     response,
+				"default": oauthErrors,
+			},
+		})
+	}
+
+	oauth("POST", "/token", "OAuth 2.0 token endpoint", doc.FormBody(models.OAuthTokenRequest{}), doc.JSONResponse("tokens", models.OAuthTokenResponse{}))
+	oauth("POST", "/introspect", "Token introspection", doc.FormBody(models.TokenIntrospectionRequest{}), doc.JSONResponse("token description", models.TokenIntrospectionResponse{}))
+	oauth("POST", "/revoke", "Token revocation", doc.FormBody(models.TokenRevocationRequest{}), openapi.EmptyResponse("token revoked or unknown"))
+	oauth("GET", "/jwks", "Keys that verify issued tokens", nil, doc.JSONResponse("key set", models.JSONWebKeySet{}))
+	oauth("GET", "/.well-known/openid-configuration", "OpenID Connect discovery", nil, doc.JSONResponse("provider metadata", openIDConfigurationResponse{}))
+
+	// authorization endpoint sends user agent to login page, which signs user in with POST
+	doc.Add("GET", "/authorize", &openapi.Operation{
+		OperationID: operationID("GET", "/authorize"),
+		Summary:     "OAuth 2.0 authorization endpoint",
+		Tags:        []string{"oauth"},
+		Parameters:  doc.QueryParameters(models.AuthorizationRequest{}),
+		Responses: map[string]*openapi.Response{
+			"302":     openapi.RedirectResponse("login page with authorization request in query, or redirect uri of client with error"),
+			"default": oauthErrors,
+		},
+	})
+	doc.Add("POST", "/authorize", &openapi.Operation{
+		OperationID: operationID("POST", "/authorize"),
+		Summary:     "Sign in on login page and authorize client",
+		Tags:        []string{"oauth"},
+		RequestBody: doc.JSONBody(authorizeRequest{}),
+		Responses: map[string]*openapi.Response{
+			"200":     doc.JSONResponse("redirect uri of client with authorization code or error", redirectResponse{}),
+			"default": errorsResponse(doc),
+		},
+	})
+
+	doc.Add("GET", "/oauth", &openapi.Operation{
+		OperationID: operationID("GET", "/oauth"),
+		Summary:     "List external identity providers",
+		Tags:        []string{"social"},
+		Responses: map[string]*openapi.Response{
+			"200":     doc.JSONResponse("names of providers", providersResponse{}),
+			"default": errorsResponse(doc),
+		},
+	})
+	doc.Add("GET", "/oauth/:provider/start", &openapi.Operation{
+		OperationID: operationID("GET", "/oauth/:provider/start"),
+		Summary:     "Sign in with external identity provider",
+		Tags:        []string{"social"},
+		Responses: map[string]*openapi.Response{
+			"302":     openapi.RedirectResponse("login page of provider"),
+			"default": errorsResponse(doc),
+		},
+	})
+	doc.Add("GET", "/oauth/:provider/callback", &openapi.Operation{
+		OperationID: operationID("GET", "/oauth/:provider/callback"),
+		Summary:     "Provider redirects here after login",
+		Tags:        []string{"social"},
+		Responses: map[string]*openapi.Response{
+			"302": openapi.RedirectResponse("signin page of front-end with one-time code, or with error code"),
+		},
+	})
+}
+
+// document with security schemes of the API
+func newDocument(basePath string, version int) *openapi.Document {
+	doc := openapi.New("Memorize account API", fmt.Sprintf("%d.0.0", version), basePath)
+
+	doc.Components.SecuritySchemes["bearerAuth"] = &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "access token of signed in user or personal access token",
+	}
+	doc.Components.SecuritySchemes["serviceToken"] = &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "access token issued to backend service with client credentials",
+	}
+
+	return doc
+}
+
+// error responses are problem+json when client accepts it
+func errorsResponse(doc *openapi.Document) *openapi.Response {
+	return &openapi.Response{
+		Description: "error",
+		Content: map[string]*openapi.MediaType{
+			"application/json":           {Schema: doc.Schema(errorResponse{})},
+			apperrors.ProblemContentType: {Schema: doc.Schema(apperrors.Problem{})},
+		},
+	}
+}
+
+// operation id like "postMeTokens" for "POST /me/tokens"
+func operationID(method string, path string) string {
+	words := strings.FieldsFunc(path, func(char rune) bool {
+		return !unicode.IsLetter(char) && !unicode.IsDigit(char)
+	})
+
+	id := strings.ToLower(method)
+	for _, word := range words {
+		id += strings.ToUpper(word[:1]) + word[1:]
+	}
+
+	return id
+}
+
+// OpenAPI serves document of the API version the route belongs to
+func (c *controller) OpenAPI(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.APIDocuments[strings.TrimSuffix(ctx.FullPath(), "/openapi.json")])
+}
+
+// docs page is static, files are served from embedded directory
+func docsFileSystem() http.FileSystem {
+	files, err := fs.Sub(docsFiles, "docs")
+	if err != nil {
+		panic(err)
+	}
+
+	return http.FS(files)
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/openapi"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOpenAPI(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	test.Run("Document", func(test *testing.T) {
+		router := gin.Default()
+		NewController(&Config{Router: router, BaseURL: "/api/account"})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/api/account/openapi.json", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		var document struct {
+			OpenAPI    string                                       `json:"openapi"`
+			Servers    []map[string]string                          `json:"servers"`
+			Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+			Components struct {
+				Schemas map[string]map[string]interface{} `json:"schemas"`
+			} `json:"components"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &document)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, "3.0.3", document.OpenAPI)
+		assert.Equal(test, "/api/account", document.Servers[0]["url"])
+		assert.Equal(test, "postSignup", document.Paths["/signup"]["post"]["operationId"])
+		assert.Contains(test, document.Paths["/users/{uid}"], "get")
+		assert.Contains(test, document.Components.Schemas, "DetailsRequset")
+		assert.Contains(test, document.Components.Schemas, "TokensRequest")
+		assert.Equal(test, []interface{}{"login", "password"}, document.Components.Schemas["SigninRequest"]["required"])
+	})
+
+	test.Run("Every route is documented", func(test *testing.T) {
+		router := gin.Default()
+		NewController(&Config{Router: router, BaseURL: "/api/account"})
+
+		paths := map[string]map[string]map[string]interface{}{}
+		for _, basePath := range []string{"/api/account", "/api/account/v1", "/api/account/v2"} {
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest(http.MethodGet, basePath+"/openapi.json", http.NoBody)
+			router.ServeHTTP(recorder, request)
+
+			var document struct {
+				Paths map[string]map[string]interface{} `json:"paths"`
+			}
+			json.Unmarshal(recorder.Body.Bytes(), &document)
+			paths[basePath] = document.Paths
+		}
+
+		for _, route := range router.Routes() {
+			// docs page is static files, not part of the API
+			if strings.Contains(route.Path, "/docs/") {
+				continue
+			}
+
+			basePath := "/api/account"
+			for _, version := range []string{"/v1", "/v2"} {
+				if strings.HasPrefix(route.Path, basePath+version+"/") {
+					basePath += version
+					break
+				}
+			}
+
+			path := openapi.Path(strings.TrimPrefix(route.Path, basePath))
+			assert.Contains(test, paths[basePath][path], strings.ToLower(route.Method), "%v %v is not documented", route.Method, route.Path)
+		}
+	})
+
+	test.Run("Docs page", func(test *testing.T) {
+		router := gin.Default()
+		NewController(&Config{Router: router, BaseURL: "/api/account"})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/api/account/docs/", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), `<script src="docs.js">`)
+		assert.Equal(test, docsContentSecurityPolicy, recorder.Header().Get("Content-Security-Policy"))
+	})
+
+	test.Run("Responses match document", func(test *testing.T) {
+		uid, _ := uuid.NewRandom()
+		user := &models.User{UID: uid, Login: "alice", Locale: "en"}
+
+		mockUserService := new(mocks.MockUserService)
+		mockUserService.On("UpdateDetails", mock.Anything, mock.AnythingOfType("*models.User")).Return(nil)
+
+		router := gin.Default()
+		router.Use(func(ctx *gin.Context) {
+			ctx.Set("user", user)
+		})
+
+		NewController(&Config{
+			Router:            router,
+			UserService:       mockUserService,
+			ValidateRequests:  true,
+			ValidateResponses: true,
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPut, "/details", strings.NewReader(`{"name":"Alice","locale":"de"}`))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		mockUserService.AssertExpectations(test)
+
+		recorder = httptest.NewRecorder()
+		request, _ = http.NewRequest(http.MethodPut, "/details", strings.NewReader(`{"locale":"fr"}`))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), `"field":"locale"`)
+	})
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"log"
+	"memorize/controller/middleware"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// scopes and expiresIn are checked by service
+type personalAccessTokenRequest struct {
+	Name      string   `json:"name" binding:"required,max=100"`
+	Scopes    []string `json:"scopes"`
+	ExpiresIn int64    `json:"expiresIn"`
+}
+
+// PersonalAccessTokens lists tokens of signed in user
+func (c *controller) PersonalAccessTokens(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	tokens, err := c.PersonalAccessTokenService.List(ctx.Request.Context(), authUser.UID)
+
+	if err != nil {
+		log.Printf("Failed to list personal access tokens of user: %v\n%v", authUser.UID, err)
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"tokens": tokens,
+	})
+}
+
+// CreatePersonalAccessToken creates token, plain token is in response only once
+func (c *controller) CreatePersonalAccessToken(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	var request personalAccessTokenRequest
+
+	if ok := bindData(ctx, &request); !ok {
+		return
+	}
+
+	if request.ExpiresIn < 0 {
+		err := apperrors.NewBadRequest("expiresIn must not be negative")
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	token := &models.PersonalAccessToken{
+		Name:   request.Name,
+		Scopes: request.Scopes,
+	}
+
+	expiresIn := time.Duration(request.ExpiresIn) * time.Second
+	err := c.PersonalAccessTokenService.Create(ctx.Request.Context(), authUser.UID, token, expiresIn)
+
+	if err != nil {
+		log.Printf("Failed to create personal access token: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"token": token,
+	})
+}
+
+// RevokePersonalAccessToken deletes token of signed in user
+func (c *controller) RevokePersonalAccessToken(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	tokenID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		err := apperrors.NewNotFound("token", ctx.Param("id"))
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	if err := c.PersonalAccessTokenService.Revoke(ctx.Request.Context(), authUser.UID, tokenID); err != nil {
+		log.Printf("Failed to revoke personal access token: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPersonalAccessTokens(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+	tokenID, _ := uuid.NewRandom()
+
+	newRouter := func(service models.PersonalAccessTokenService) *gin.Engine {
+		router := gin.Default()
+		router.Use(func(c *gin.Context) {
+			c.Set("user", &models.User{UID: uid})
+		})
+
+		NewController(&Config{
+			Router:                     router,
+			PersonalAccessTokenService: service,
+		})
+
+		return router
+	}
+
+	test.Run("Create", func(test *testing.T) {
+		mockService := new(mocks.MockPersonalAccessTokenService)
+		mockService.
+			On("Create", mock.Anything, uid, &models.PersonalAccessToken{
+				Name:   "backup script",
+				Scopes: []string{models.ScopeProfileRead},
+			}, 30*24*time.Hour).
+			Run(func(args mock.Arguments) {
+				token := args.Get(2).(*models.PersonalAccessToken)
+				token.ID = tokenID
+				token.Token = models.PersonalAccessTokenPrefix + "secret"
+			}).
+			Return(nil)
+
+		router := newRouter(mockService)
+
+		body, _ := json.Marshal(gin.H{
+			"name":      "backup script",
+			"scopes":    []string{models.ScopeProfileRead},
+			"expiresIn": 30 * 24 * 60 * 60,
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/me/tokens", bytes.NewBuffer(body))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		var response struct {
+			Token map[string]interface{} `json:"token"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.Equal(test, http.StatusCreated, recorder.Code)
+		assert.Equal(test, models.PersonalAccessTokenPrefix+"secret", response.Token["token"])
+		assert.Equal(test, tokenID.String(), response.Token["id"])
+		mockService.AssertExpectations(test)
+	})
+
+	test.Run("Create without name", func(test *testing.T) {
+		mockService := new(mocks.MockPersonalAccessTokenService)
+
+		router := newRouter(mockService)
+
+		body, _ := json.Marshal(gin.H{
+			"scopes": []string{models.ScopeProfileRead},
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/me/tokens", bytes.NewBuffer(body))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		mockService.AssertNotCalled(test, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("List hides plain token", func(test *testing.T) {
+		mockService := new(mocks.MockPersonalAccessTokenService)
+		mockService.On("List", mock.Anything, uid).Return([]*models.PersonalAccessToken{
+			{ID: tokenID, UserID: uid, Name: "ci", Scopes: []string{models.ScopeSessions}, TokenHash: "hash"},
+		}, nil)
+
+		router := newRouter(mockService)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/me/tokens", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.NotContains(test, recorder.Body.String(), "hash")
+		assert.NotContains(test, recorder.Body.String(), `"token":`)
+	})
+
+	test.Run("Revoke", func(test *testing.T) {
+		mockService := new(mocks.MockPersonalAccessTokenService)
+		mockService.On("Revoke", mock.Anything, uid, tokenID).Return(nil)
+
+		router := newRouter(mockService)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodDelete, "/me/tokens/"+tokenID.String(), http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusNoContent, recorder.Code)
+		mockService.AssertExpectations(test)
+	})
+
+	test.Run("Revoke unknown token", func(test *testing.T) {
+		mockService := new(mocks.MockPersonalAccessTokenService)
+		mockService.On("Revoke", mock.Anything, uid, tokenID).Return(apperrors.NewNotFound("token", tokenID.String()))
+
+		router := newRouter(mockService)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodDelete, "/me/tokens/"+tokenID.String(), http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusNotFound, recorder.Code)
+	})
+}
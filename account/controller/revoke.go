@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"log"
+	"memorize/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Revoke is the OAuth 2.0 token revocation endpoint defined by RFC 7009
+func (c *controller) Revoke(ctx *gin.Context) {
+	var request models.TokenRevocationRequest
+
+	if ok := bindOAuthForm(ctx, &request, &request.ClientID, &request.ClientSecret); !ok {
+		return
+	}
+
+	if err := c.OAuthService.Revoke(ctx.Request.Context(), &request); err != nil {
+		log.Printf("Failed to revoke token for client %v: %v\n", request.ClientID, err.Error())
+		respondOAuthError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRevoke(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockOAuthService := new(mocks.MockOAuthService)
+
+	router := gin.Default()
+
+	NewController(&Config{
+		Router:       router,
+		OAuthService: mockOAuthService,
+	})
+
+	revoke := func(form url.Values) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/revoke", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("Revoked", func(test *testing.T) {
+		mockOAuthService.
+			On("Revoke", mock.Anything, &models.TokenRevocationRequest{
+				Token:         "refresh",
+				TokenTypeHint: "refresh_token",
+				ClientID:      "public",
+			}).
+			Return(nil)
+
+		recorder := revoke(url.Values{
+			"token":           {"refresh"},
+			"token_type_hint": {"refresh_token"},
+			"client_id":       {"public"},
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Empty(test, recorder.Body.String())
+	})
+
+	test.Run("Unknown client", func(test *testing.T) {
+		mockOAuthService.
+			On("Revoke", mock.Anything, &models.TokenRevocationRequest{
+				Token:    "refresh",
+				ClientID: "unknown",
+			}).
+			Return(apperrors.NewOAuthError(apperrors.InvalidClient, "Client authentication failed"))
+
+		recorder := revoke(url.Values{"token": {"refresh"}, "client_id": {"unknown"}})
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), apperrors.InvalidClient)
+	})
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+	"memorize/controller/middleware"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cookie holding refresh token in session cookie mode, only sent to tokens endpoint
+const refreshTokenCookie = "refresh_token"
+
+// clients that send no Origin opt in to refresh token cookie with this header set to true
+const sessionCookiesHeader = "X-Session-Cookies"
+
+// respondTokens sends issued tokens to client, since API version 2 they are not wrapped in "tokens"
+// in session cookie mode refresh token is set as HttpOnly cookie instead of being in the body
+// for browsers and clients that ask for it, others like the Go client keep it in the body
+func (c *controller) respondTokens(ctx *gin.Context, status int, tokens *models.TokenPair) {
+	if !c.SessionCookies || !wantsSessionCookies(ctx) {
+		respondTokenPair(ctx, status, tokens)
+		return
+	}
+
+	csrfToken, err := newCSRFToken()
+	if err != nil {
+		log.Printf("Failed to create csrf token: %v\n", err.Error())
+		err := apperrors.NewInternal().Wrap(err)
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	c.setCookie(ctx, refreshTokenCookie, tokens.RefreshToken.Token, c.tokensPath(ctx), int(tokens.RefreshToken.ExpiresIn), true)
+	c.setCookie(ctx, middleware.CSRFCookie, csrfToken, "/", int(tokens.RefreshToken.ExpiresIn), false)
+
+	body := *tokens
+	body.RefreshToken.Token = ""
+
+	respondTokenPair(ctx, status, &body)
+}
+
+// browsers send Origin with every POST, other clients opt in with sessionCookiesHeader
+// clients that refresh with the cookie keep getting it
+func wantsSessionCookies(ctx *gin.Context) bool {
+	if ctx.GetHeader("Origin") != "" {
+		return true
+	}
+
+	if cookie, err := ctx.Cookie(refreshTokenCookie); err == nil && cookie != "" {
+		return true
+	}
+
+	optIn, _ := strconv.ParseBool(ctx.GetHeader(sessionCookiesHeader))
+	return optIn
+}
+
+func respondTokenPair(ctx *gin.Context, status int, tokens *models.TokenPair) {
+	if middleware.Version(ctx) >= 2 {
+		ctx.JSON(status, tokens)
+		return
+	}
+
+	ctx.JSON(status, gin.H{
+		"tokens": tokens,
+	})
+}
+
+// tokens endpoint of API version the request was routed to, refresh token cookie is sent only there
+// so clients moving to other version sign in again
+func (c *controller) tokensPath(ctx *gin.Context) string {
+	basePath := middleware.BasePath(ctx)
+
+	// protocol routes are not versioned
+	if basePath == "" {
+		basePath = c.BaseURL
+	}
+
+	return basePath + "/tokens"
+}
+
+// clearSessionCookies removes cookies set by respondTokens
+func (c *controller) clearSessionCookies(ctx *gin.Context) {
+	if !c.SessionCookies {
+		return
+	}
+
+	c.setCookie(ctx, refreshTokenCookie, "", c.tokensPath(ctx), -1, true)
+	c.setCookie(ctx, middleware.CSRFCookie, "", "/", -1, false)
+}
+
+// cookies are always Secure, browsers treat localhost as secure during development
+func (c *controller) setCookie(ctx *gin.Context, name string, value string, path string, maxAge int, httpOnly bool) {
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		Secure:   true,
+		HttpOnly: httpOnly,
+		SameSite: c.CookieSameSite,
+	})
+}
+
+func newCSRFToken() (string, error) {
+	bytes := make([]byte, 32)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
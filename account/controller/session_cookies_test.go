@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"memorize/controller/middleware"
+	"memorize/mocks"
+	"memorize/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSessionCookies(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockTokenService := new(mocks.MockTokenService)
+	mockUserService := new(mocks.MockUserService)
+
+	router := gin.Default()
+
+	NewController(&Config{
+		Router:         router,
+		TokenService:   mockTokenService,
+		UserService:    mockUserService,
+		BaseURL:        "/api/account",
+		SessionCookies: true,
+	})
+
+	uid, _ := uuid.NewRandom()
+	tokenID, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice"}
+
+	tokens := &models.TokenPair{
+		AccessToken:  models.AccessToken{Token: "access", ExpiresIn: 900},
+		RefreshToken: models.RefreshToken{ID: tokenID, UserID: uid, Token: "refresh", ExpiresIn: 3600},
+	}
+
+	cookiesOf := func(recorder *httptest.ResponseRecorder) map[string]*http.Cookie {
+		cookies := map[string]*http.Cookie{}
+		for _, cookie := range (&http.Response{Header: recorder.Header()}).Cookies() {
+			cookies[cookie.Name] = cookie
+		}
+		return cookies
+	}
+
+	test.Run("Signin sets refresh token cookie", func(test *testing.T) {
+		mockUserService.On("Signin", mock.Anything, &models.User{Login: "alice", Password: "password"}).Return(user, nil)
+		mockTokenService.On("NewPairFromUser", mock.Anything, user, "").Return(tokens, nil)
+
+		requestBody, _ := json.Marshal(gin.H{"login": "alice", "password": "password"})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/api/account/signin", bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Origin", "http://localhost:3000")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.NotContains(test, recorder.Body.String(), "refresh")
+		assert.Contains(test, recorder.Body.String(), `"accessToken":"access"`)
+
+		cookies := cookiesOf(recorder)
+
+		refreshCookie := cookies[refreshTokenCookie]
+		assert.Equal(test, "refresh", refreshCookie.Value)
+		assert.Equal(test, "/api/account/tokens", refreshCookie.Path)
+		assert.Equal(test, 3600, refreshCookie.MaxAge)
+		assert.True(test, refreshCookie.HttpOnly)
+		assert.True(test, refreshCookie.Secure)
+		assert.Equal(test, http.SameSiteStrictMode, refreshCookie.SameSite)
+
+		csrfCookie := cookies[middleware.CSRFCookie]
+		assert.NotEmpty(test, csrfCookie.Value)
+		assert.False(test, csrfCookie.HttpOnly)
+	})
+
+	test.Run("Client opts in with header", func(test *testing.T) {
+		requestBody, _ := json.Marshal(gin.H{"login": "alice", "password": "password"})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/api/account/signin", bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set(sessionCookiesHeader, "true")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.NotContains(test, recorder.Body.String(), "refresh")
+		assert.Equal(test, "refresh", cookiesOf(recorder)[refreshTokenCookie].Value)
+	})
+
+	test.Run("Client without Origin keeps refresh token in body", func(test *testing.T) {
+		requestBody, _ := json.Marshal(gin.H{"login": "alice", "password": "password"})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/api/account/signin", bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), `"refreshToken":"refresh"`)
+		assert.Empty(test, recorder.Header().Get("Set-Cookie"))
+	})
+
+	test.Run("Tokens reads refresh token from cookie", func(test *testing.T) {
+		mockTokenService.On("ValidateRefreshToken", "refresh").Return(&tokens.RefreshToken, nil)
+		mockUserService.On("GetUser", mock.Anything, uid).Return(user, nil)
+		mockTokenService.On("NewPairFromUser", mock.Anything, user, tokenID.String()).Return(tokens, nil)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/api/account/tokens", http.NoBody)
+		request.AddCookie(&http.Cookie{Name: refreshTokenCookie, Value: "refresh"})
+		request.AddCookie(&http.Cookie{Name: middleware.CSRFCookie, Value: "csrf"})
+		request.Header.Set(middleware.CSRFHeader, "csrf")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, "refresh", cookiesOf(recorder)[refreshTokenCookie].Value)
+		mockTokenService.AssertCalled(test, "ValidateRefreshToken", "refresh")
+	})
+
+	test.Run("Tokens without CSRF header", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/api/account/tokens", http.NoBody)
+		request.AddCookie(&http.Cookie{Name: refreshTokenCookie, Value: "refresh"})
+		request.AddCookie(&http.Cookie{Name: middleware.CSRFCookie, Value: "csrf"})
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusForbidden, recorder.Code)
+	})
+
+	test.Run("Signout clears cookies", func(test *testing.T) {
+		signoutRouter := gin.Default()
+		signoutRouter.Use(func(c *gin.Context) {
+			c.Set("user", user)
+		})
+
+		NewController(&Config{
+			Router:         signoutRouter,
+			TokenService:   mockTokenService,
+			SessionCookies: true,
+		})
+
+		mockTokenService.On("Signout", mock.Anything, uid).Return(nil)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/signout", http.NoBody)
+		signoutRouter.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+
+		cookies := cookiesOf(recorder)
+		assert.Equal(test, "", cookies[refreshTokenCookie].Value)
+		assert.True(test, cookies[refreshTokenCookie].MaxAge < 0)
+		assert.True(test, cookies[middleware.CSRFCookie].MaxAge < 0)
+	})
+}
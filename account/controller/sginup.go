@@ -2,15 +2,15 @@ package controller
 
 import (
 	"log"
+	"memorize/controller/middleware"
 	"memorize/models"
-	"memorize/models/apperrors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type signinRequest struct {
-	Login    string `json:"login" binding:"required"`
+	Login    string `json:"login" binding:"required,login"`
 	Password string `json:"password" binding:"required,gte=6,lte=30"`
 }
 
@@ -31,9 +31,7 @@ func (c *controller) Signup(ctx *gin.Context) {
 
 	if err != nil {
 		log.Printf("Faild to sign up user: %v\n", err.Error())
-		ctx.JSON(apperrors.Status(err), gin.H{
-			"error": err,
-		})
+		middleware.RespondError(ctx, err)
 		return
 	}
 
@@ -42,14 +40,10 @@ func (c *controller) Signup(ctx *gin.Context) {
 	if err != nil {
 		log.Printf("Failded to create tokens for user: %v\n", err.Error())
 
-		ctx.JSON(apperrors.Status(err), gin.H{
-			"error": err,
-		})
+		middleware.RespondError(ctx, err)
 
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{
-		"tokens": tokens,
-	})
+	c.respondTokens(ctx, http.StatusCreated, tokens)
 }
@@ -2,8 +2,8 @@ package controller
 
 import (
 	"log"
+	"memorize/controller/middleware"
 	"memorize/models"
-	"memorize/models/apperrors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -33,24 +33,41 @@ func (c *controller) Signin(ctx *gin.Context) {
 
 	if err != nil {
 		log.Printf("Failed to sign in user: %v\n", err.Error())
-		ctx.JSON(apperrors.Status(err), gin.H{
-			"error": err,
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	c.completeSignin(ctx, signupedUser)
+}
+
+// completeSignin issues tokens to user whose first factor is confirmed
+// users with second factor get passkey options instead, tokens are issued after passkey signin finishes
+func (c *controller) completeSignin(ctx *gin.Context, user *models.User) {
+	requestCtx := ctx.Request.Context()
+
+	if user.SecondFactor {
+		options, err := c.WebAuthnService.BeginSecondFactor(requestCtx, user.UID)
+
+		if err != nil {
+			log.Printf("Failed to begin second factor for user: %v\n", err.Error())
+			middleware.RespondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"secondFactor": options,
 		})
 		return
 	}
 
-	tokens, err := c.TokenService.NewPairFromUser(requestCtx, signupedUser, "")
+	tokens, err := c.TokenService.NewPairFromUser(requestCtx, user, "")
 
 	if err != nil {
 		log.Printf("Failed to create tokens for user: %v\n", err.Error())
 
-		ctx.JSON(apperrors.Status(err), gin.H{
-			"error": err,
-		})
+		middleware.RespondError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"tokens": tokens,
-	})
+	c.respondTokens(ctx, http.StatusOK, tokens)
 }
@@ -1,8 +1,8 @@
 package controller
 
 import (
+	"memorize/controller/middleware"
 	"memorize/models"
-	"memorize/models/apperrors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,12 +13,12 @@ func (c *controller) Signout(ctx *gin.Context) {
 
 	requestCtx := ctx.Request.Context()
 	if err := c.TokenService.Signout(requestCtx, user.(*models.User).UID); err != nil {
-		ctx.JSON(apperrors.Status(err), gin.H{
-			"error": err,
-		})
+		middleware.RespondError(ctx, err)
 		return
 	}
 
+	c.clearSessionCookies(ctx)
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "user signed out successfully!",
 	})
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"log"
+	"memorize/controller/middleware"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SocialProviders lists external providers user can sign in with
+func (c *controller) SocialProviders(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"providers": c.SocialService.Providers(),
+	})
+}
+
+// SocialStart redirects browser to external provider login page
+func (c *controller) SocialStart(ctx *gin.Context) {
+	redirectURL, err := c.SocialService.Start(ctx.Request.Context(), ctx.Param("provider"), uuid.Nil)
+
+	if err != nil {
+		log.Printf("Failed to start %v login: %v\n", ctx.Param("provider"), err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, redirectURL)
+}
+
+type socialSigninRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// SocialCallback finishes login at external provider
+// browser is sent to front-end with one-time signin code, or with error code when login failed
+func (c *controller) SocialCallback(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
+	if ctx.Query("error") != "" {
+		log.Printf("Provider %v returned error: %v\n", provider, ctx.Query("error"))
+		c.socialRedirect(ctx, "error", string(apperrors.Authorization))
+		return
+	}
+
+	if ctx.Query("code") == "" || ctx.Query("state") == "" {
+		c.socialRedirect(ctx, "error", string(apperrors.BadRequest))
+		return
+	}
+
+	requestCtx := ctx.Request.Context()
+	user, err := c.SocialService.Callback(requestCtx, provider, ctx.Query("code"), ctx.Query("state"))
+
+	if err != nil {
+		log.Printf("Failed to sign in with %v: %v\n", provider, err.Error())
+		c.socialRedirect(ctx, "error", string(apperrors.From(err).Code))
+		return
+	}
+
+	code, err := c.SocialService.SigninCode(requestCtx, user.UID)
+
+	if err != nil {
+		log.Printf("Failed to create signin code for user: %v\n", err.Error())
+		c.socialRedirect(ctx, "error", string(apperrors.From(err).Code))
+		return
+	}
+
+	c.socialRedirect(ctx, "code", code)
+}
+
+// SocialSignin exchanges signin code from callback redirect for tokens
+func (c *controller) SocialSignin(ctx *gin.Context) {
+	var request socialSigninRequest
+
+	if ok := bindData(ctx, &request); !ok {
+		return
+	}
+
+	user, err := c.SocialService.Signin(ctx.Request.Context(), request.Code)
+
+	if err != nil {
+		log.Printf("Failed to exchange signin code: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	c.completeSignin(ctx, user)
+}
+
+// sends browser to front-end signin page with param in query
+func (c *controller) socialRedirect(ctx *gin.Context, param string, value string) {
+	redirectURL, err := service.RedirectURIWithParams(c.SocialSigninURL, map[string]string{
+		param: value,
+	})
+
+	if err != nil {
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, redirectURL)
+}
+
+// SocialLink returns provider login url which links external account to signed in user
+func (c *controller) SocialLink(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	redirectURL, err := c.SocialService.Start(ctx.Request.Context(), ctx.Param("provider"), authUser.UID)
+
+	if err != nil {
+		log.Printf("Failed to start %v linking: %v\n", ctx.Param("provider"), err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"redirectUrl": redirectURL,
+	})
+}
+
+// SocialUnlink removes link between signed in user and external provider
+func (c *controller) SocialUnlink(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	if err := c.SocialService.Unlink(ctx.Request.Context(), ctx.Param("provider"), authUser.UID); err != nil {
+		log.Printf("Failed to unlink %v: %v\n", ctx.Param("provider"), err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Identities lists external providers linked to signed in user
+func (c *controller) Identities(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	identities, err := c.SocialService.ListIdentities(ctx.Request.Context(), authUser.UID)
+
+	if err != nil {
+		log.Printf("Failed to list identities of user: %v\n%v", authUser.UID, err)
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"identities": identities,
+	})
+}
@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/webauthn"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSocial(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+
+	test.Run("Start redirects to provider", func(test *testing.T) {
+		mockSocialService := new(mocks.MockSocialService)
+		mockSocialService.
+			On("Start", mock.Anything, "google", uuid.Nil).
+			Return("https://accounts.google.com/o/oauth2/v2/auth?state=abc", nil)
+
+		router := gin.Default()
+		NewController(&Config{
+			Router:        router,
+			SocialService: mockSocialService,
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/oauth/google/start", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusFound, recorder.Code)
+		assert.Equal(test, "https://accounts.google.com/o/oauth2/v2/auth?state=abc", recorder.Header().Get("Location"))
+	})
+
+	test.Run("Start unknown provider", func(test *testing.T) {
+		mockSocialService := new(mocks.MockSocialService)
+		mockSocialService.
+			On("Start", mock.Anything, "unknown", uuid.Nil).
+			Return("", apperrors.NewNotFound("provider", "unknown"))
+
+		router := gin.Default()
+		NewController(&Config{
+			Router:        router,
+			SocialService: mockSocialService,
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/oauth/unknown/start", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusNotFound, recorder.Code)
+	})
+
+	test.Run("Callback redirects with signin code", func(test *testing.T) {
+		user := &models.User{UID: uid, Login: "alice"}
+
+		mockSocialService := new(mocks.MockSocialService)
+		mockSocialService.On("Callback", mock.Anything, "google", "code", "state").Return(user, nil)
+		mockSocialService.On("SigninCode", mock.Anything, uid).Return("signincode", nil)
+
+		router := gin.Default()
+		NewController(&Config{
+			Router:          router,
+			SocialService:   mockSocialService,
+			SocialSigninURL: "https://app.example.com/signin/social",
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/oauth/google/callback?code=code&state=state", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusFound, recorder.Code)
+		assert.Equal(test, "https://app.example.com/signin/social?code=signincode", recorder.Header().Get("Location"))
+	})
+
+	test.Run("Callback with provider error", func(test *testing.T) {
+		mockSocialService := new(mocks.MockSocialService)
+
+		router := gin.Default()
+		NewController(&Config{
+			Router:          router,
+			SocialService:   mockSocialService,
+			SocialSigninURL: "https://app.example.com/signin/social",
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/oauth/google/callback?error=access_denied&state=state", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusFound, recorder.Code)
+		assert.Equal(test, "https://app.example.com/signin/social?error=AUTHORIZATION", recorder.Header().Get("Location"))
+		mockSocialService.AssertNotCalled(test, "Callback", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Signin code is exchanged for tokens", func(test *testing.T) {
+		user := &models.User{UID: uid, Login: "alice"}
+		tokens := &models.TokenPair{
+			AccessToken:  models.AccessToken{Token: "access"},
+			RefreshToken: models.RefreshToken{Token: "refresh"},
+		}
+
+		mockSocialService := new(mocks.MockSocialService)
+		mockSocialService.On("Signin", mock.Anything, "signincode").Return(user, nil)
+
+		mockTokenService := new(mocks.MockTokenService)
+		mockTokenService.On("NewPairFromUser", mock.Anything, user, "").Return(tokens, nil)
+
+		router := gin.Default()
+		NewController(&Config{
+			Router:        router,
+			SocialService: mockSocialService,
+			TokenService:  mockTokenService,
+		})
+
+		recorder := httptest.NewRecorder()
+		requestBody, _ := json.Marshal(gin.H{"code": "signincode"})
+		request, _ := http.NewRequest(http.MethodPost, "/signin/social", bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"tokens": tokens,
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.JSONEq(test, string(expectedBody), recorder.Body.String())
+	})
+
+	test.Run("Signin code asks for second factor", func(test *testing.T) {
+		user := &models.User{UID: uid, Login: "alice", SecondFactor: true}
+		options := &webauthn.RequestOptions{
+			PublicKey: webauthn.PublicKeyCredentialRequestOptions{Challenge: []byte("challenge")},
+		}
+
+		mockSocialService := new(mocks.MockSocialService)
+		mockSocialService.On("Signin", mock.Anything, "signincode").Return(user, nil)
+		mockWebAuthnService := new(mocks.MockWebAuthnService)
+		mockWebAuthnService.On("BeginSecondFactor", mock.Anything, uid).Return(options, nil)
+		mockTokenService := new(mocks.MockTokenService)
+
+		router := gin.Default()
+		NewController(&Config{
+			Router:          router,
+			SocialService:   mockSocialService,
+			TokenService:    mockTokenService,
+			WebAuthnService: mockWebAuthnService,
+		})
+
+		recorder := httptest.NewRecorder()
+		requestBody, _ := json.Marshal(gin.H{"code": "signincode"})
+		request, _ := http.NewRequest(http.MethodPost, "/signin/social", bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"secondFactor": options,
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.JSONEq(test, string(expectedBody), recorder.Body.String())
+		mockTokenService.AssertNotCalled(test, "NewPairFromUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Link returns provider url", func(test *testing.T) {
+		mockSocialService := new(mocks.MockSocialService)
+		mockSocialService.
+			On("Start", mock.Anything, "github", uid).
+			Return("https://github.com/login/oauth/authorize?state=abc", nil)
+
+		router := gin.Default()
+		router.Use(func(c *gin.Context) {
+			c.Set("user", &models.User{UID: uid})
+		})
+		NewController(&Config{
+			Router:        router,
+			SocialService: mockSocialService,
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/oauth/github/link", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"redirectUrl": "https://github.com/login/oauth/authorize?state=abc",
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.JSONEq(test, string(expectedBody), recorder.Body.String())
+	})
+
+	test.Run("Unlink", func(test *testing.T) {
+		mockSocialService := new(mocks.MockSocialService)
+		mockSocialService.On("Unlink", mock.Anything, "github", uid).Return(nil)
+
+		router := gin.Default()
+		router.Use(func(c *gin.Context) {
+			c.Set("user", &models.User{UID: uid})
+		})
+		NewController(&Config{
+			Router:        router,
+			SocialService: mockSocialService,
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodDelete, "/oauth/github/link", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusNoContent, recorder.Code)
+		mockSocialService.AssertExpectations(test)
+	})
+
+	test.Run("Identities", func(test *testing.T) {
+		identities := []*models.UserIdentity{{Provider: "github", Email: "alice@mail.com"}}
+
+		mockSocialService := new(mocks.MockSocialService)
+		mockSocialService.On("ListIdentities", mock.Anything, uid).Return(identities, nil)
+
+		router := gin.Default()
+		router.Use(func(c *gin.Context) {
+			c.Set("user", &models.User{UID: uid})
+		})
+		NewController(&Config{
+			Router:        router,
+			SocialService: mockSocialService,
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/me/identities", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"identities": identities,
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.JSONEq(test, string(expectedBody), recorder.Body.String())
+	})
+}
@@ -2,6 +2,8 @@ package controller
 
 import (
 	"log"
+	"memorize/controller/middleware"
+	"memorize/models"
 	"memorize/models/apperrors"
 	"net/http"
 
@@ -12,30 +14,30 @@ type tokensRequest struct {
 	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
+// Tokens exchanges refresh token for new pair of tokens
+// in session cookie mode refresh token is read from cookie, body is used when cookie is missing
 func (c *controller) Tokens(ctx *gin.Context) {
 	var request tokensRequest
 
-	if ok := bindData(ctx, &request); !ok {
+	if cookie, err := ctx.Cookie(refreshTokenCookie); c.SessionCookies && err == nil && cookie != "" {
+		request.RefreshToken = cookie
+	} else if ok := bindData(ctx, &request); !ok {
 		return
 	}
 
 	requestCtx := ctx.Request.Context()
 
-	refreshToken, err := c.TokenService.ValidateRefreshToken(request.RefreshToken)
+	refreshToken, err := c.validateRefreshToken(request.RefreshToken)
 
 	if err != nil {
-		ctx.JSON(apperrors.Status(err), gin.H{
-			"error": err,
-		})
+		middleware.RespondError(ctx, err)
 		return
 	}
 
 	user, err := c.UserService.GetUser(requestCtx, refreshToken.UserID)
 
 	if err != nil {
-		ctx.JSON(apperrors.Status(err), gin.H{
-			"error": err,
-		})
+		middleware.RespondError(ctx, err)
 		return
 	}
 
@@ -44,13 +46,24 @@ func (c *controller) Tokens(ctx *gin.Context) {
 	if err != nil {
 		log.Printf("Failed to create tokens for user: %+v. Error: %v\n", user, err.Error())
 
-		ctx.JSON(apperrors.Status(err), gin.H{
-			"error": err,
-		})
+		middleware.RespondError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"tokens": tokens,
-	})
+	c.respondTokens(ctx, http.StatusOK, tokens)
+}
+
+// validateRefreshToken accepts only refresh tokens of the account service itself
+// tokens issued to OAuth clients are redeemed by their client at /token
+func (c *controller) validateRefreshToken(token string) (*models.RefreshToken, error) {
+	refreshToken, err := c.TokenService.ValidateRefreshToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if refreshToken.Grant != nil {
+		return nil, apperrors.NewAuthorization("Refresh token was issued to OAuth client").WithCode(apperrors.InvalidRefreshToken)
+	}
+
+	return refreshToken, nil
 }
@@ -104,7 +104,7 @@ func TestTokens(test *testing.T) {
 			On("GetUser", getArguments...).
 			Return(mockUserResponse, nil)
 
-		mockError := apperrors.NewAuthorization("Invalid refresh token")
+		mockError := apperrors.NewAuthorization("Invalid refresh token").WithCode(apperrors.TokenReused)
 		newPairArgumnets := mock.Arguments{
 			mock.AnythingOfType("*context.emptyCtx"),
 			mockUserResponse,
@@ -205,4 +205,27 @@ func TestTokens(test *testing.T) {
 		mockTokenService.AssertCalled(test, "NewPairFromUser", newPairArguments...)
 	})
 
+	test.Run("Refresh token of OAuth client", func(test *testing.T) {
+		clientTokenString := "clientRefreshToken"
+		mockUserID, _ := uuid.NewRandom()
+
+		mockTokenService.
+			On("ValidateRefreshToken", clientTokenString).
+			Return(&models.RefreshToken{UserID: mockUserID, Grant: &models.ClientGrant{ClientID: "client"}}, nil)
+
+		recorder := httptest.NewRecorder()
+
+		requestBody, _ := json.Marshal(gin.H{
+			"refreshToken": clientTokenString,
+		})
+
+		request, _ := http.NewRequest(http.MethodPost, "/tokens", bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), string(apperrors.InvalidRefreshToken))
+		mockUserService.AssertNotCalled(test, "GetUser", mock.Anything, mockUserID)
+	})
+
 }
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"log"
+	"memorize/controller/middleware"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// User resolves user by uid for backend services
+func (c *controller) User(ctx *gin.Context) {
+	uid, err := uuid.Parse(ctx.Param("uid"))
+	if err != nil {
+		err := apperrors.NewNotFound("user", ctx.Param("uid"))
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	if principal, exists := ctx.Get("service"); exists {
+		log.Printf("Service %v resolves user: %v\n", principal.(*models.ServicePrincipal).ClientID, uid)
+	}
+
+	user, err := c.UserService.GetUser(ctx.Request.Context(), uid)
+
+	if err != nil {
+		log.Printf("Unable to find user: %v\n%v", uid, err)
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"user": user,
+	})
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUser(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice"}
+
+	mockUserService := new(mocks.MockUserService)
+	mockUserService.On("GetUser", mock.Anything, uid).Return(user, nil)
+
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		c.Set("service", &models.ServicePrincipal{ClientID: "billing", Audience: "account"})
+	})
+
+	NewController(&Config{
+		Router:      router,
+		UserService: mockUserService,
+	})
+
+	test.Run("Found", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/users/"+uid.String(), http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"user": user,
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.JSONEq(test, string(expectedBody), recorder.Body.String())
+	})
+
+	test.Run("Not found", func(test *testing.T) {
+		unknown, _ := uuid.NewRandom()
+		mockUserService.On("GetUser", mock.Anything, unknown).Return(nil, apperrors.NewNotFound("uid", unknown.String()))
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/users/"+unknown.String(), http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusNotFound, recorder.Code)
+	})
+
+	test.Run("Invalid uid", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/users/alice", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusNotFound, recorder.Code)
+	})
+}
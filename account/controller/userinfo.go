@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"log"
+	"memorize/controller/middleware"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Userinfo returns OpenID Connect claims of the signed in user
+// tokens of OAuth clients get only claims their scope grants
+func (c *controller) Userinfo(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	grant, err := c.accessTokenGrant(ctx)
+
+	if err != nil {
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	user, err := c.UserService.GetUser(ctx.Request.Context(), authUser.UID)
+
+	if err != nil {
+		log.Printf("Unable to find user: %v\n%v", authUser.UID, err)
+		e := apperrors.NewNotFound("user", authUser.UID.String())
+
+		middleware.RespondError(ctx, e)
+
+		return
+	}
+
+	user = grant.User(user)
+
+	claims := gin.H{
+		"sub":                user.UID.String(),
+		"preferred_username": user.Login,
+	}
+
+	optionalClaims := map[string]string{
+		"name":    user.Name,
+		"email":   user.Email,
+		"website": user.Website,
+		"picture": user.ImageURL,
+		"locale":  user.Locale,
+	}
+
+	for claim, value := range optionalClaims {
+		if value != "" {
+			claims[claim] = value
+		}
+	}
+
+	ctx.JSON(http.StatusOK, claims)
+}
+
+// accessTokenGrant reads client grant of bearer token, requests authenticated otherwise have none
+func (c *controller) accessTokenGrant(ctx *gin.Context) (*models.ClientGrant, error) {
+	token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+
+	// personal access tokens belong to user themselves
+	if token == "" || strings.HasPrefix(token, models.PersonalAccessTokenPrefix) {
+		return nil, nil
+	}
+
+	return c.TokenService.AccessTokenGrant(token)
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserinfo(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+
+	mockUserService := new(mocks.MockUserService)
+	mockUserService.On("GetUser", mock.Anything, uid).Return(&models.User{
+		UID:   uid,
+		Login: "alice",
+		Email: "alice@mail.com",
+	}, nil)
+
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{UID: uid})
+	})
+
+	NewController(&Config{
+		Router:      router,
+		UserService: mockUserService,
+	})
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodGet, "/userinfo", http.NoBody)
+	router.ServeHTTP(recorder, request)
+
+	expectedBody, _ := json.Marshal(gin.H{
+		"sub":                uid.String(),
+		"preferred_username": "alice",
+		"email":              "alice@mail.com",
+	})
+
+	assert.Equal(test, http.StatusOK, recorder.Code)
+	assert.JSONEq(test, string(expectedBody), recorder.Body.String())
+}
+
+func TestUserinfoScope(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+
+	mockUserService := new(mocks.MockUserService)
+	mockUserService.On("GetUser", mock.Anything, uid).Return(&models.User{
+		UID:   uid,
+		Login: "alice",
+		Name:  "Alice",
+		Email: "alice@mail.com",
+	}, nil)
+
+	mockTokenService := new(mocks.MockTokenService)
+	mockTokenService.On("AccessTokenGrant", "client-token").Return(&models.ClientGrant{
+		ClientID: "client",
+		Scope:    "openid profile",
+	}, nil)
+
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{UID: uid})
+	})
+
+	NewController(&Config{
+		Router:       router,
+		UserService:  mockUserService,
+		TokenService: mockTokenService,
+	})
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodGet, "/userinfo", http.NoBody)
+	request.Header.Set("Authorization", "Bearer client-token")
+	router.ServeHTTP(recorder, request)
+
+	expectedBody, _ := json.Marshal(gin.H{
+		"sub":                uid.String(),
+		"preferred_username": "alice",
+		"name":               "Alice",
+	})
+
+	assert.Equal(test, http.StatusOK, recorder.Code)
+	assert.JSONEq(test, string(expectedBody), recorder.Body.String())
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"encoding/json"
+	"memorize/controller/middleware"
+	"memorize/mocks"
+	"memorize/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestVersions(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+	tokenID, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice"}
+
+	tokens := &models.TokenPair{
+		RefreshToken: models.RefreshToken{Token: "refresh"},
+		AccessToken:  models.AccessToken{Token: "access"},
+	}
+
+	mockTokenService := new(mocks.MockTokenService)
+	mockTokenService.On("ValidateRefreshToken", "refresh").Return(&models.RefreshToken{ID: tokenID, UserID: uid}, nil)
+	mockTokenService.On("NewPairFromUser", mock.Anything, user, tokenID.String()).Return(tokens, nil)
+
+	mockUserService := new(mocks.MockUserService)
+	mockUserService.On("GetUser", mock.Anything, uid).Return(user, nil)
+
+	router := gin.Default()
+
+	NewController(&Config{
+		Router:       router,
+		BaseURL:      "/api/account",
+		TokenService: mockTokenService,
+		UserService:  mockUserService,
+		Deprecations: map[int]*middleware.Deprecation{
+			1: {At: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	})
+
+	refresh := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(`{"refreshToken":"refresh"}`))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	test.Run("Unversioned routes are deprecated version 1", func(test *testing.T) {
+		recorder := refresh("/api/account/tokens")
+
+		var body map[string]interface{}
+		json.Unmarshal(recorder.Body.Bytes(), &body)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Contains(test, body, "tokens")
+		assert.Equal(test, "@1767225600", recorder.Header().Get("Deprecation"))
+	})
+
+	test.Run("Version 1", func(test *testing.T) {
+		recorder := refresh("/api/account/v1/tokens")
+
+		var body map[string]interface{}
+		json.Unmarshal(recorder.Body.Bytes(), &body)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Contains(test, body, "tokens")
+		assert.Equal(test, "@1767225600", recorder.Header().Get("Deprecation"))
+	})
+
+	test.Run("Version 2 does not wrap tokens", func(test *testing.T) {
+		recorder := refresh("/api/account/v2/tokens")
+
+		var body map[string]interface{}
+		json.Unmarshal(recorder.Body.Bytes(), &body)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, "access", body["accessToken"])
+		assert.Equal(test, "refresh", body["refreshToken"])
+		assert.Empty(test, recorder.Header().Get("Deprecation"))
+	})
+
+	test.Run("Protocol routes are not versioned", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/api/account/v2/.well-known/openid-configuration", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusNotFound, recorder.Code)
+
+		recorder = httptest.NewRecorder()
+		request, _ = http.NewRequest(http.MethodGet, "/api/account/.well-known/openid-configuration", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Empty(test, recorder.Header().Get("Deprecation"))
+	})
+
+	test.Run("Documents by version", func(test *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/api/account/v2/openapi.json", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		var document struct {
+			Info    map[string]string              `json:"info"`
+			Servers []map[string]string            `json:"servers"`
+			Paths   map[string]map[string]struct{} `json:"paths"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &document)
+
+		assert.Equal(test, "2.0.0", document.Info["version"])
+		assert.Equal(test, "/api/account/v2", document.Servers[0]["url"])
+		assert.Contains(test, document.Paths, "/tokens")
+		assert.NotContains(test, document.Paths, "/token")
+	})
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"log"
+	"memorize/controller/middleware"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/webauthn"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// credential is checked by service
+type passkeyRegistrationRequest struct {
+	Name       string                        `json:"name" binding:"required,max=100"`
+	Credential *webauthn.AttestationResponse `json:"credential"`
+}
+
+// login is optional, without it browser offers discoverable passkeys
+type passkeySigninRequest struct {
+	Login string `json:"login"`
+}
+
+type passkeyAssertionRequest struct {
+	Credential *webauthn.AssertionResponse `json:"credential"`
+}
+
+type secondFactorRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// BeginPasskeyRegistration returns options for navigator.credentials.create
+func (c *controller) BeginPasskeyRegistration(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	options, err := c.WebAuthnService.BeginRegistration(ctx.Request.Context(), authUser.UID)
+
+	if err != nil {
+		log.Printf("Failed to begin passkey registration of user: %v\n%v", authUser.UID, err)
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"options": options,
+	})
+}
+
+// FinishPasskeyRegistration stores passkey created by authenticator
+func (c *controller) FinishPasskeyRegistration(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	var request passkeyRegistrationRequest
+
+	if ok := bindData(ctx, &request); !ok {
+		return
+	}
+
+	if request.Credential == nil {
+		err := apperrors.NewBadRequest("credential is required")
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	credential, err := c.WebAuthnService.FinishRegistration(
+		ctx.Request.Context(),
+		authUser.UID,
+		request.Name,
+		request.Credential,
+	)
+
+	if err != nil {
+		log.Printf("Failed to register passkey: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"credential": credential,
+	})
+}
+
+// BeginPasskeySignin returns options for navigator.credentials.get
+func (c *controller) BeginPasskeySignin(ctx *gin.Context) {
+	var request passkeySigninRequest
+
+	if ok := bindData(ctx, &request); !ok {
+		return
+	}
+
+	options, err := c.WebAuthnService.BeginSignin(ctx.Request.Context(), request.Login)
+
+	if err != nil {
+		log.Printf("Failed to begin passkey signin: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"options": options,
+	})
+}
+
+// FinishPasskeySignin verifies passkey signin or second factor and issues tokens
+func (c *controller) FinishPasskeySignin(ctx *gin.Context) {
+	var request passkeyAssertionRequest
+
+	if ok := bindData(ctx, &request); !ok {
+		return
+	}
+
+	if request.Credential == nil {
+		err := apperrors.NewBadRequest("credential is required")
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	requestCtx := ctx.Request.Context()
+	user, err := c.WebAuthnService.FinishSignin(requestCtx, request.Credential)
+
+	if err != nil {
+		log.Printf("Failed to sign in with passkey: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	tokens, err := c.TokenService.NewPairFromUser(requestCtx, user, "")
+
+	if err != nil {
+		log.Printf("Failed to create tokens for user: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	c.respondTokens(ctx, http.StatusOK, tokens)
+}
+
+// Passkeys lists passkeys of signed in user
+func (c *controller) Passkeys(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	credentials, err := c.WebAuthnService.ListCredentials(ctx.Request.Context(), authUser.UID)
+
+	if err != nil {
+		log.Printf("Failed to list passkeys of user: %v\n%v", authUser.UID, err)
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"passkeys": credentials,
+	})
+}
+
+// DeletePasskey deletes passkey of signed in user
+func (c *controller) DeletePasskey(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	if err := c.WebAuthnService.DeleteCredential(ctx.Request.Context(), authUser.UID, ctx.Param("id")); err != nil {
+		log.Printf("Failed to delete passkey: %v\n", err.Error())
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// SecondFactor turns passkey confirmation of password signin on or off
+func (c *controller) SecondFactor(ctx *gin.Context) {
+	authUser := ctx.MustGet("user").(*models.User)
+
+	var request secondFactorRequest
+
+	if ok := bindData(ctx, &request); !ok {
+		return
+	}
+
+	if err := c.WebAuthnService.SetSecondFactor(ctx.Request.Context(), authUser.UID, request.Enabled); err != nil {
+		log.Printf("Failed to set second factor of user: %v\n%v", authUser.UID, err)
+		middleware.RespondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"secondFactor": request.Enabled,
+	})
+}
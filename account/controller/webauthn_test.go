@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/webauthn"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPasskeys(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice"}
+
+	newRouter := func(config *Config) *gin.Engine {
+		router := gin.Default()
+		router.Use(func(c *gin.Context) {
+			c.Set("user", user)
+		})
+
+		config.Router = router
+		NewController(config)
+
+		return router
+	}
+
+	newRequest := func(method string, target string, body interface{}) *http.Request {
+		requestBody, _ := json.Marshal(body)
+
+		request, _ := http.NewRequest(method, target, bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+
+		return request
+	}
+
+	test.Run("Begin registration", func(test *testing.T) {
+		options := &webauthn.CreationOptions{
+			PublicKey: webauthn.PublicKeyCredentialCreationOptions{Challenge: []byte("challenge")},
+		}
+
+		mockService := new(mocks.MockWebAuthnService)
+		mockService.On("BeginRegistration", mock.Anything, uid).Return(options, nil)
+
+		router := newRouter(&Config{WebAuthnService: mockService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest(http.MethodPost, "/webauthn/register/begin", gin.H{}))
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"options": options,
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+
+	test.Run("Finish registration", func(test *testing.T) {
+		response := &webauthn.AttestationResponse{
+			ID:    "Y3JlZGVudGlhbA",
+			RawID: []byte("credential"),
+			Type:  "public-key",
+			Response: webauthn.AuthenticatorAttestationResponse{
+				ClientDataJSON:    []byte("{}"),
+				AttestationObject: []byte("attestation"),
+			},
+		}
+		credential := &models.WebAuthnCredential{
+			ID:        "Y3JlZGVudGlhbA",
+			UserID:    uid,
+			Name:      "laptop",
+			PublicKey: []byte("key"),
+		}
+
+		mockService := new(mocks.MockWebAuthnService)
+		mockService.On("FinishRegistration", mock.Anything, uid, "laptop", response).Return(credential, nil)
+
+		router := newRouter(&Config{WebAuthnService: mockService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest(http.MethodPost, "/webauthn/register/finish", gin.H{
+			"name":       "laptop",
+			"credential": response,
+		}))
+
+		assert.Equal(test, http.StatusCreated, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), `"id":"Y3JlZGVudGlhbA"`)
+		assert.NotContains(test, recorder.Body.String(), "publicKey")
+		mockService.AssertExpectations(test)
+	})
+
+	test.Run("Finish registration without credential", func(test *testing.T) {
+		mockService := new(mocks.MockWebAuthnService)
+
+		router := newRouter(&Config{WebAuthnService: mockService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest(http.MethodPost, "/webauthn/register/finish", gin.H{
+			"name": "laptop",
+		}))
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+		mockService.AssertNotCalled(test, "FinishRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Passkey signin", func(test *testing.T) {
+		response := &webauthn.AssertionResponse{
+			ID:    "Y3JlZGVudGlhbA",
+			RawID: []byte("credential"),
+			Type:  "public-key",
+			Response: webauthn.AuthenticatorAssertionResponse{
+				ClientDataJSON:    []byte("{}"),
+				AuthenticatorData: []byte("data"),
+				Signature:         []byte("signature"),
+				UserHandle:        uid[:],
+			},
+		}
+		tokens := &models.TokenPair{
+			AccessToken:  models.AccessToken{Token: "access"},
+			RefreshToken: models.RefreshToken{Token: "refresh"},
+		}
+
+		mockService := new(mocks.MockWebAuthnService)
+		mockService.On("FinishSignin", mock.Anything, response).Return(user, nil)
+		mockTokenService := new(mocks.MockTokenService)
+		mockTokenService.On("NewPairFromUser", mock.Anything, user, "").Return(tokens, nil)
+
+		router := newRouter(&Config{WebAuthnService: mockService, TokenService: mockTokenService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest(http.MethodPost, "/webauthn/signin/finish", gin.H{
+			"credential": response,
+		}))
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"tokens": tokens,
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+	})
+
+	test.Run("Passkey signin fails", func(test *testing.T) {
+		response := &webauthn.AssertionResponse{
+			ID:    "Y3JlZGVudGlhbA",
+			RawID: []byte("credential"),
+			Type:  "public-key",
+			Response: webauthn.AuthenticatorAssertionResponse{
+				ClientDataJSON:    []byte("{}"),
+				AuthenticatorData: []byte("data"),
+				Signature:         []byte("signature"),
+				UserHandle:        uid[:],
+			},
+		}
+
+		mockService := new(mocks.MockWebAuthnService)
+		mockService.On("FinishSignin", mock.Anything, response).Return(nil, apperrors.NewAuthorization("Passkey could not be verified"))
+		mockTokenService := new(mocks.MockTokenService)
+
+		router := newRouter(&Config{WebAuthnService: mockService, TokenService: mockTokenService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest(http.MethodPost, "/webauthn/signin/finish", gin.H{
+			"credential": response,
+		}))
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+		mockTokenService.AssertNotCalled(test, "NewPairFromUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Password signin asks for second factor", func(test *testing.T) {
+		secondFactorUser := &models.User{UID: uid, Login: "alice", SecondFactor: true}
+		options := &webauthn.RequestOptions{
+			PublicKey: webauthn.PublicKeyCredentialRequestOptions{Challenge: []byte("challenge")},
+		}
+
+		mockUserService := new(mocks.MockUserService)
+		mockUserService.
+			On("Signin", mock.Anything, &models.User{Login: "alice", Password: "password"}).
+			Return(secondFactorUser, nil)
+		mockService := new(mocks.MockWebAuthnService)
+		mockService.On("BeginSecondFactor", mock.Anything, uid).Return(options, nil)
+		mockTokenService := new(mocks.MockTokenService)
+
+		router := newRouter(&Config{
+			UserService:     mockUserService,
+			TokenService:    mockTokenService,
+			WebAuthnService: mockService,
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest(http.MethodPost, "/signin", gin.H{
+			"login":    "alice",
+			"password": "password",
+		}))
+
+		expectedBody, _ := json.Marshal(gin.H{
+			"secondFactor": options,
+		})
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+		mockTokenService.AssertNotCalled(test, "NewPairFromUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Enable second factor without passkeys", func(test *testing.T) {
+		mockService := new(mocks.MockWebAuthnService)
+		mockService.
+			On("SetSecondFactor", mock.Anything, uid, true).
+			Return(apperrors.NewBadRequest("register a passkey before enabling second factor"))
+
+		router := newRouter(&Config{WebAuthnService: mockService})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newRequest(http.MethodPut, "/me/second-factor", gin.H{
+			"enabled": true,
+		}))
+
+		assert.Equal(test, http.StatusBadRequest, recorder.Code)
+	})
+
+	test.Run("Delete passkey", func(test *testing.T) {
+		mockService := new(mocks.MockWebAuthnService)
+		mockService.On("DeleteCredential", mock.Anything, uid, "Y3JlZGVudGlhbA").Return(nil)
+
+		router := newRouter(&Config{WebAuthnService: mockService})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodDelete, "/me/passkeys/Y3JlZGVudGlhbA", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusNoContent, recorder.Code)
+		mockService.AssertExpectations(test)
+	})
+}
@@ -0,0 +1,48 @@
+package i18n
+
+import "memorize/models/apperrors"
+
+var german = &catalog{
+	errors: map[apperrors.Code]string{
+		apperrors.AccountDisabled:      "Das Konto ist deaktiviert",
+		apperrors.CSRFTokenInvalid:     "CSRF-Token fehlt oder ist ungültig",
+		apperrors.InsufficientScope:    "Dem Token fehlt die nötige Berechtigung",
+		apperrors.InvalidCredentials:   "Login oder Passwort ist falsch",
+		apperrors.InvalidLogin:         "Der Login enthält unzulässige Zeichen oder mischt Schriftsysteme",
+		apperrors.InvalidRefreshToken:  "Der Refresh-Token ist ungültig",
+		apperrors.InvalidToken:         "Der Token ist ungültig",
+		apperrors.LastSigninMethod:     "Das ist die letzte Anmeldemethode des Kontos",
+		apperrors.LoginTaken:           "Der Login {value} ist bereits vergeben",
+		apperrors.MalformedJSON:        "Der Inhalt der Anfrage ist kein gültiges JSON",
+		apperrors.MissingToken:         "Der Authorization-Header muss das Format `Bearer {token}` haben",
+		apperrors.SecondFactorRequired: "Für die Anmeldung wird ein Passkey benötigt",
+		apperrors.SigninLinkInvalid:    "Der Anmeldelink ist ungültig oder abgelaufen",
+		apperrors.TokenReused:          "Der Refresh-Token wurde bereits verwendet",
+		apperrors.ValidationFailed:     "Ungültige Anfrageparameter, siehe details",
+
+		apperrors.Code(apperrors.Conflict):             "{resource} mit dem Wert {value} existiert bereits",
+		apperrors.Code(apperrors.Internal):             "Interner Serverfehler",
+		apperrors.Code(apperrors.NotFound):             "{resource} mit dem Wert {value} wurde nicht gefunden",
+		apperrors.Code(apperrors.PayloadTooLarge):      "Die Anfrage ist mit {contentLength} Bytes größer als die erlaubten {maxBodySize} Bytes",
+		apperrors.Code(apperrors.PreconditionFailed):   "{resource} mit dem Wert {value} wurde inzwischen geändert, bitte neu laden",
+		apperrors.Code(apperrors.PreconditionRequired): "Die Anfrage muss den Header {header} enthalten",
+		apperrors.Code(apperrors.ServiceUnavailable):   "Der Dienst ist nicht verfügbar oder hat das Zeitlimit überschritten",
+		apperrors.Code(apperrors.TooManyRequests):      "Zu viele Anfragen, bitte später erneut versuchen",
+	},
+	validation: map[string]string{
+		"":         "{field} ist ungültig",
+		"required": "{field} ist erforderlich",
+		"email":    "{field} muss eine gültige E-Mail-Adresse sein",
+		"url":      "{field} muss eine gültige URL sein",
+		"max":      "{field} darf höchstens {param} Zeichen lang sein",
+		"min":      "{field} muss mindestens {param} Zeichen lang sein",
+		"len":      "{field} muss genau {param} Zeichen lang sein",
+		"oneof":    "{field} muss einer der Werte {param} sein",
+		"uuid":     "{field} muss eine gültige UUID sein",
+		"eqfield":  "{field} muss mit {param} übereinstimmen",
+		"gte":      "{field} muss mindestens {param} Zeichen lang sein",
+		"lte":      "{field} darf höchstens {param} Zeichen lang sein",
+		"login":    "{field} darf nur Buchstaben, Ziffern, Punkte, Bindestriche und Unterstriche enthalten",
+		"type":     "{field} muss vom Typ {param} sein",
+	},
+}
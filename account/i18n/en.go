@@ -0,0 +1,24 @@
+package i18n
+
+import "memorize/models/apperrors"
+
+// messages of apperrors are written in english, so only validation is here
+var english = &catalog{
+	errors: map[apperrors.Code]string{},
+	validation: map[string]string{
+		"":         "{field} is invalid",
+		"required": "{field} is required",
+		"email":    "{field} must be a valid email address",
+		"url":      "{field} must be a valid URL",
+		"max":      "{field} must be at most {param} characters long",
+		"min":      "{field} must be at least {param} characters long",
+		"len":      "{field} must be exactly {param} characters long",
+		"oneof":    "{field} must be one of: {param}",
+		"uuid":     "{field} must be a valid UUID",
+		"eqfield":  "{field} must match {param}",
+		"gte":      "{field} must be at least {param} characters long",
+		"lte":      "{field} must be at most {param} characters long",
+		"login":    "{field} may contain only letters, digits, dots, dashes and underscores",
+		"type":     "{field} must be of type {param}",
+	},
+}
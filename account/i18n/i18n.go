@@ -0,0 +1,118 @@
+// Package i18n holds message catalogs of error responses and negotiates their locale
+package i18n
+
+import (
+	"fmt"
+	"memorize/models/apperrors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is language of messages written in code
+const DefaultLocale = "en"
+
+// catalog of messages in one language
+// placeholders like {value} are replaced with details of error or parameters of validation tag
+type catalog struct {
+	// messages by error code, errors without entry keep their message
+	errors map[apperrors.Code]string
+	// messages by validator tag, "" is used for tags without entry
+	validation map[string]string
+}
+
+var catalogs = map[string]*catalog{
+	"en": english,
+	"de": german,
+}
+
+// Supported lists locales that have catalog
+func Supported() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+
+	sort.Strings(locales)
+	return locales
+}
+
+// Supports reports if there is catalog for locale
+func Supports(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Negotiate picks supported locale most preferred in Accept-Language header value
+// regional variants fall back to their language, default locale is used when nothing matches
+func Negotiate(acceptLanguage string) string {
+	locale := DefaultLocale
+	best := 0.0
+
+	for _, item := range strings.Split(acceptLanguage, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ";")
+
+		tag := strings.ToLower(strings.TrimSpace(parts[0]))
+		if index := strings.Index(tag, "-"); index != -1 {
+			tag = tag[:index]
+		}
+
+		quality := 1.0
+		for _, param := range parts[1:] {
+			if value := strings.TrimSpace(param); strings.HasPrefix(value, "q=") {
+				if q, err := strconv.ParseFloat(value[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if Supports(tag) && quality > best {
+			locale = tag
+			best = quality
+		}
+	}
+
+	return locale
+}
+
+// ErrorMessage translates message of err, ok is false when it should be kept
+func ErrorMessage(locale string, err *apperrors.Error) (string, bool) {
+	c, ok := catalogs[locale]
+	if !ok {
+		return "", false
+	}
+
+	message, ok := c.errors[err.Code]
+	if !ok {
+		return "", false
+	}
+
+	return replace(message, err.Details), true
+}
+
+// ValidationMessage describes failed validator tag of field in a sentence
+func ValidationMessage(locale string, field string, tag string, param string) string {
+	c, ok := catalogs[locale]
+	if !ok {
+		c = catalogs[DefaultLocale]
+	}
+
+	message, ok := c.validation[tag]
+	if !ok {
+		message = c.validation[""]
+	}
+
+	return replace(message, map[string]interface{}{
+		"field": field,
+		"param": param,
+	})
+}
+
+func replace(message string, values map[string]interface{}) string {
+	pairs := make([]string, 0, 2*len(values))
+	for key, value := range values {
+		pairs = append(pairs, "{"+key+"}", fmt.Sprint(value))
+	}
+
+	return strings.NewReplacer(pairs...).Replace(message)
+}
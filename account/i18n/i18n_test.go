@@ -0,0 +1,47 @@
+package i18n
+
+import (
+	"memorize/models/apperrors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(test *testing.T) {
+	assert.Equal(test, DefaultLocale, Negotiate(""))
+	assert.Equal(test, "de", Negotiate("de"))
+	assert.Equal(test, "de", Negotiate("de-AT,de;q=0.9,en;q=0.8"))
+	assert.Equal(test, "en", Negotiate("fr-FR,en;q=0.5,de;q=0.4"))
+	assert.Equal(test, "de", Negotiate("en;q=0.3, DE;q=0.7"))
+	assert.Equal(test, DefaultLocale, Negotiate("fr,es;q=0.9"))
+	assert.Equal(test, DefaultLocale, Negotiate("de;q=0"))
+}
+
+func TestErrorMessage(test *testing.T) {
+	test.Run("Translated with details", func(test *testing.T) {
+		err := apperrors.NewConflict("login", "alice").WithCode(apperrors.LoginTaken)
+
+		message, ok := ErrorMessage("de", err)
+
+		assert.True(test, ok)
+		assert.Equal(test, "Der Login alice ist bereits vergeben", message)
+	})
+
+	test.Run("Source language is kept", func(test *testing.T) {
+		_, ok := ErrorMessage("en", apperrors.NewTooManyRequests())
+
+		assert.False(test, ok)
+	})
+
+	test.Run("Message without entry is kept", func(test *testing.T) {
+		_, ok := ErrorMessage("de", apperrors.NewBadRequest("code and state are required"))
+
+		assert.False(test, ok)
+	})
+}
+
+func TestValidationMessage(test *testing.T) {
+	assert.Equal(test, "Login is required", ValidationMessage("en", "Login", "required", ""))
+	assert.Equal(test, "Name darf höchstens 50 Zeichen lang sein", ValidationMessage("de", "Name", "max", "50"))
+	assert.Equal(test, "Email is invalid", ValidationMessage("fr", "Email", "hostname", ""))
+}
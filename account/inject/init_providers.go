@@ -0,0 +1,88 @@
+package inject
+
+import (
+	"fmt"
+	"log"
+	"memorize/models"
+	"memorize/providers"
+	"os"
+	"strings"
+)
+
+// issuers of well known OpenID Connect providers
+var defaultIssuers = map[string]string{
+	"google": "https://accounts.google.com",
+}
+
+// Init external identity providers listed in OAUTH_PROVIDERS
+// each provider is configured with OAUTH_<NAME>_CLIENT_ID, OAUTH_<NAME>_CLIENT_SECRET,
+// optional OAUTH_<NAME>_SCOPES and OAUTH_<NAME>_ISSUER for generic OpenID Connect providers
+func initIdentityProviders() (map[string]models.IdentityProvider, error) {
+	identityProviders := map[string]models.IdentityProvider{}
+
+	callbackURL := strings.TrimSuffix(os.Getenv("OAUTH_CALLBACK_URL"), "/")
+
+	for _, name := range strings.Split(os.Getenv("OAUTH_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		if callbackURL == "" {
+			return nil, fmt.Errorf("OAUTH_CALLBACK_URL is empty")
+		}
+
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+
+		config := &providers.Config{
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURI:  fmt.Sprintf("%v/%v/callback", callbackURL, name),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+
+		if config.ClientID == "" {
+			return nil, fmt.Errorf("%vCLIENT_ID is empty", prefix)
+		}
+
+		if name == "github" {
+			identityProviders[name] = providers.NewGitHubProvider(config, nil)
+			continue
+		}
+
+		issuer := os.Getenv(prefix + "ISSUER")
+		if issuer == "" {
+			issuer = defaultIssuers[name]
+		}
+
+		if issuer == "" {
+			return nil, fmt.Errorf("%vISSUER is empty", prefix)
+		}
+
+		identityProviders[name] = providers.NewOIDCProvider(issuer, config, nil)
+	}
+
+	return identityProviders, nil
+}
+
+// Init mailer from SMTP_ADDR, SMTP_USERNAME, SMTP_PASSWORD and MAIL_FROM
+// mails are only logged when SMTP_ADDR is empty
+func initMailer() (models.Mailer, error) {
+	addr := os.Getenv("SMTP_ADDR")
+	if addr == "" {
+		log.Println("SMTP_ADDR is empty, mails are written to log")
+		return providers.NewLogMailer(), nil
+	}
+
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		return nil, fmt.Errorf("MAIL_FROM is empty")
+	}
+
+	return providers.NewSMTPMailer(&providers.SMTPConfig{
+		Addr:     addr,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}), nil
+}
@@ -7,17 +7,38 @@ import (
 
 // Stractue for repositories
 type Repositories struct {
-	UserRepository  models.UserRepository
-	TokenRepository models.TokenRepository
+	UserRepository                models.UserRepository
+	TokenRepository               models.TokenRepository
+	OAuthClientRepository         models.OAuthClientRepository
+	AuthorizationCodeRepository   models.AuthorizationCodeRepository
+	UserIdentityRepository        models.UserIdentityRepository
+	SocialLoginStateRepository    models.SocialLoginStateRepository
+	PersonalAccessTokenRepository models.PersonalAccessTokenRepository
+	WebAuthnCredentialRepository  models.WebAuthnCredentialRepository
+	WebAuthnSessionRepository     models.WebAuthnSessionRepository
 }
 
 // Inject data sources into repositories
 func InitRepositories(sources *DataSources) *Repositories {
 	userRepository := repository.NewUserRepository(sources.DB)
 	tokenRepository := repository.NewTokenRepository(sources.RedisClient)
+	oauthClientRepository := repository.NewOAuthClientRepository(sources.DB)
+	authorizationCodeRepository := repository.NewAuthorizationCodeRepository(sources.RedisClient)
+	userIdentityRepository := repository.NewUserIdentityRepository(sources.DB)
+	socialLoginStateRepository := repository.NewSocialLoginStateRepository(sources.RedisClient)
+	personalAccessTokenRepository := repository.NewPersonalAccessTokenRepository(sources.DB)
+	webAuthnCredentialRepository := repository.NewWebAuthnCredentialRepository(sources.DB)
+	webAuthnSessionRepository := repository.NewWebAuthnSessionRepository(sources.RedisClient)
 
 	return &Repositories{
-		UserRepository:  userRepository,
-		TokenRepository: tokenRepository,
+		UserRepository:                userRepository,
+		TokenRepository:               tokenRepository,
+		OAuthClientRepository:         oauthClientRepository,
+		AuthorizationCodeRepository:   authorizationCodeRepository,
+		UserIdentityRepository:        userIdentityRepository,
+		SocialLoginStateRepository:    socialLoginStateRepository,
+		PersonalAccessTokenRepository: personalAccessTokenRepository,
+		WebAuthnCredentialRepository:  webAuthnCredentialRepository,
+		WebAuthnSessionRepository:     webAuthnSessionRepository,
 	}
 }
@@ -3,8 +3,11 @@ package inject
 import (
 	"fmt"
 	"memorize/controller"
+	"memorize/controller/middleware"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -23,13 +26,172 @@ func InitRouter(services *Services) (*gin.Engine, error) {
 		return nil, fmt.Errorf("could not parse HANDLER_TIMEOUT as int: %w", err)
 	}
 
+	sessionCookies, _ := strconv.ParseBool(os.Getenv("SESSION_COOKIES"))
+
+	cookieSameSite, err := parseSameSite(os.Getenv("SESSION_COOKIE_SAMESITE"))
+	if err != nil {
+		return nil, err
+	}
+
+	cors, err := corsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	securityHeaders, err := securityHeadersConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	problemJSON, _ := strconv.ParseBool(os.Getenv("PROBLEM_JSON"))
+
+	// contract tests run with validation of requests and responses against OpenAPI document
+	validateRequests, _ := strconv.ParseBool(os.Getenv("OPENAPI_VALIDATE_REQUESTS"))
+	validateResponses, _ := strconv.ParseBool(os.Getenv("OPENAPI_VALIDATE_RESPONSES"))
+
+	deprecations, err := deprecationsConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	controller.NewController(&controller.Config{
-		Router:          router,
-		UserService:     services.UserService,
-		TokenService:    services.TokenService,
-		BaseURL:         baseUrl,
-		TImeoutDuration: time.Duration(time.Duration(controllerTimeout) * time.Second),
+		Router:                     router,
+		UserService:                services.UserService,
+		TokenService:               services.TokenService,
+		OAuthService:               services.OAuthService,
+		SocialService:              services.SocialService,
+		PersonalAccessTokenService: services.PersonalAccessTokenService,
+		WebAuthnService:            services.WebAuthnService,
+		MagicLinkService:           services.MagicLinkService,
+		BaseURL:                    baseUrl,
+		Issuer:                     os.Getenv("OIDC_ISSUER"),
+		LoginURL:                   os.Getenv("OIDC_LOGIN_URL"),
+		SocialSigninURL:            os.Getenv("OAUTH_SIGNIN_URL"),
+		ServiceAudience:            os.Getenv("SERVICE_AUDIENCE"),
+		TImeoutDuration:            time.Duration(time.Duration(controllerTimeout) * time.Second),
+		SessionCookies:             sessionCookies,
+		CookieSameSite:             cookieSameSite,
+		CORS:                       cors,
+		SecurityHeaders:            securityHeaders,
+		ProblemJSON:                problemJSON,
+		ValidateRequests:           validateRequests,
+		ValidateResponses:          validateResponses,
+		Deprecations:               deprecations,
 	})
 
 	return router, nil
 }
+
+// parse SameSite attribute of session cookies, strict when not set
+func parseSameSite(value string) (http.SameSite, error) {
+	switch value {
+	case "", "strict":
+		return http.SameSiteStrictMode, nil
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("SESSION_COOKIE_SAMESITE must be strict, lax or none, got %v", value)
+	}
+}
+
+// CORS is disabled when no origin is allowed
+func corsConfig() (*middleware.CORSConfig, error) {
+	origins := parseList(os.Getenv("CORS_ALLOWED_ORIGINS"))
+	if len(origins) == 0 {
+		return nil, nil
+	}
+
+	allowCredentials, _ := strconv.ParseBool(os.Getenv("CORS_ALLOW_CREDENTIALS"))
+
+	for _, origin := range origins {
+		if origin == "*" && allowCredentials {
+			return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS cannot be * when CORS_ALLOW_CREDENTIALS is true")
+		}
+	}
+
+	var maxAge int64
+	if maxAgeStr := os.Getenv("CORS_MAX_AGE"); maxAgeStr != "" {
+		var err error
+		maxAge, err = strconv.ParseInt(maxAgeStr, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse CORS_MAX_AGE as int: %w", err)
+		}
+	}
+
+	return &middleware.CORSConfig{
+		AllowedOrigins:   origins,
+		AllowedMethods:   parseList(os.Getenv("CORS_ALLOWED_METHODS")),
+		AllowedHeaders:   parseList(os.Getenv("CORS_ALLOWED_HEADERS")),
+		ExposedHeaders:   parseList(os.Getenv("CORS_EXPOSED_HEADERS")),
+		AllowCredentials: allowCredentials,
+		MaxAge:           time.Duration(maxAge) * time.Second,
+	}, nil
+}
+
+// HSTS is sent only when HSTS_MAX_AGE is set, so it is not pinned on plain http in development
+func securityHeadersConfig() (*middleware.SecurityHeadersConfig, error) {
+	var hstsMaxAge int64
+	if hstsMaxAgeStr := os.Getenv("HSTS_MAX_AGE"); hstsMaxAgeStr != "" {
+		var err error
+		hstsMaxAge, err = strconv.ParseInt(hstsMaxAgeStr, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse HSTS_MAX_AGE as int: %w", err)
+		}
+	}
+
+	return &middleware.SecurityHeadersConfig{
+		HSTSMaxAge:            time.Duration(hstsMaxAge) * time.Second,
+		ContentSecurityPolicy: os.Getenv("CONTENT_SECURITY_POLICY"),
+	}, nil
+}
+
+// split comma separated env value, empty items are skipped
+func parseList(value string) []string {
+	var items []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// deprecationsConfig reads deprecations of API versions older than latest, version is deprecated when API_V<n>_DEPRECATED_AT is set
+// dates are RFC 3339
+func deprecationsConfig() (map[int]*middleware.Deprecation, error) {
+	deprecations := map[int]*middleware.Deprecation{}
+
+	for version := 1; version < controller.LatestAPIVersion; version++ {
+		prefix := fmt.Sprintf("API_V%d_", version)
+
+		deprecatedAt := os.Getenv(prefix + "DEPRECATED_AT")
+		if deprecatedAt == "" {
+			continue
+		}
+
+		at, err := time.Parse(time.RFC3339, deprecatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %vDEPRECATED_AT as RFC 3339 date: %w", prefix, err)
+		}
+
+		deprecation := &middleware.Deprecation{
+			At:   at,
+			Link: os.Getenv(prefix + "DEPRECATION_LINK"),
+		}
+
+		if sunset := os.Getenv(prefix + "SUNSET"); sunset != "" {
+			deprecation.Sunset, err = time.Parse(time.RFC3339, sunset)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse %vSUNSET as RFC 3339 date: %w", prefix, err)
+			}
+		}
+
+		deprecations[version] = deprecation
+	}
+
+	return deprecations, nil
+}
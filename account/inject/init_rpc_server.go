@@ -0,0 +1,18 @@
+package inject
+
+import (
+	"memorize/rpc"
+	"os"
+
+	"google.golang.org/grpc"
+)
+
+// Init gRPC server, inject services into it
+func InitRPCServer(services *Services) *grpc.Server {
+	return rpc.NewServer(&rpc.Config{
+		UserService:                services.UserService,
+		TokenService:               services.TokenService,
+		PersonalAccessTokenService: services.PersonalAccessTokenService,
+		ServiceAudience:            os.Getenv("SERVICE_AUDIENCE"),
+	})
+}
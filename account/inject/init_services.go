@@ -5,23 +5,38 @@ import (
 	"io/ioutil"
 	"memorize/models"
 	"memorize/service"
+	"memorize/webauthn"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 )
 
 // structure for services
 type Services struct {
-	UserService  models.UserService
-	TokenService models.TokenService
+	UserService                models.UserService
+	TokenService               models.TokenService
+	OAuthService               models.OAuthService
+	SocialService              models.SocialService
+	PersonalAccessTokenService models.PersonalAccessTokenService
+	WebAuthnService            models.WebAuthnService
+	MagicLinkService           models.MagicLinkService
 }
 
 // Inject repositories into services
 func InitServices(repositories *Repositories) (*Services, error) {
 
+	loginReservation, err := loginReservationConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	userService := service.NewUserService(&service.UserServiceConfig{
-		UserRepository: repositories.UserRepository,
+		UserRepository:   repositories.UserRepository,
+		TokenRepository:  repositories.TokenRepository,
+		LoginReservation: loginReservation,
 	})
 
 	tokenService, err := initTokenService(repositories.TokenRepository)
@@ -29,13 +44,115 @@ func InitServices(repositories *Repositories) (*Services, error) {
 		return nil, fmt.Errorf("faild to init tokenService: %w", err)
 	}
 
+	oauthService := service.NewOAuthService(&service.OAuthServiceConfig{
+		OAuthClientRepository:       repositories.OAuthClientRepository,
+		AuthorizationCodeRepository: repositories.AuthorizationCodeRepository,
+		UserRepository:              repositories.UserRepository,
+		TokenRepository:             repositories.TokenRepository,
+		TokenService:                tokenService,
+		Issuer:                      os.Getenv("OIDC_ISSUER"),
+	})
+
+	identityProviders, err := initIdentityProviders()
+	if err != nil {
+		return nil, fmt.Errorf("faild to init identity providers: %w", err)
+	}
+
+	socialService := service.NewSocialService(&service.SocialServiceConfig{
+		IdentityProviders:            identityProviders,
+		UserRepository:               repositories.UserRepository,
+		UserIdentityRepository:       repositories.UserIdentityRepository,
+		SocialLoginStateRepository:   repositories.SocialLoginStateRepository,
+		WebAuthnCredentialRepository: repositories.WebAuthnCredentialRepository,
+	})
+
+	personalAccessTokenService := service.NewPersonalAccessTokenService(&service.PersonalAccessTokenServiceConfig{
+		PersonalAccessTokenRepository: repositories.PersonalAccessTokenRepository,
+		UserRepository:                repositories.UserRepository,
+	})
+
+	webAuthnService := service.NewWebAuthnService(&service.WebAuthnServiceConfig{
+		RelyingParty:                 initRelyingParty(),
+		WebAuthnCredentialRepository: repositories.WebAuthnCredentialRepository,
+		WebAuthnSessionRepository:    repositories.WebAuthnSessionRepository,
+		UserRepository:               repositories.UserRepository,
+	})
+
+	mailer, err := initMailer()
+	if err != nil {
+		return nil, fmt.Errorf("faild to init mailer: %w", err)
+	}
+
+	magicLinkSecret := os.Getenv("MAGIC_LINK_SECRET")
+	if magicLinkSecret == "" {
+		return nil, fmt.Errorf("MAGIC_LINK_SECRET is empty")
+	}
+
+	magicLinkService := service.NewMagicLinkService(&service.MagicLinkServiceConfig{
+		UserRepository:  repositories.UserRepository,
+		TokenRepository: repositories.TokenRepository,
+		Mailer:          mailer,
+		Secret:          magicLinkSecret,
+		LinkURL:         os.Getenv("MAGIC_LINK_URL"),
+	})
+
 	return &Services{
-		UserService:  userService,
-		TokenService: tokenService,
+		UserService:                userService,
+		TokenService:               tokenService,
+		OAuthService:               oauthService,
+		SocialService:              socialService,
+		PersonalAccessTokenService: personalAccessTokenService,
+		WebAuthnService:            webAuthnService,
+		MagicLinkService:           magicLinkService,
 	}, nil
 }
 
+// days previous logins stay reserved, service default is used when LOGIN_RESERVATION_DAYS is empty
+func loginReservationConfig() (time.Duration, error) {
+	days := os.Getenv("LOGIN_RESERVATION_DAYS")
+	if days == "" {
+		return 0, nil
+	}
+
+	reservationDays, err := strconv.ParseInt(days, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse LOGIN_RESERVATION_DAYS as int: %w", err)
+	}
+
+	return time.Duration(reservationDays) * 24 * time.Hour, nil
+}
+
+// relying party passkeys are bound to, origins are comma separated
+func initRelyingParty() *webauthn.Config {
+	var origins []string
+
+	for _, origin := range strings.Split(os.Getenv("WEBAUTHN_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	return &webauthn.Config{
+		RPID:    os.Getenv("WEBAUTHN_RP_ID"),
+		RPName:  os.Getenv("WEBAUTHN_RP_NAME"),
+		Origins: origins,
+		Timeout: 5 * time.Minute,
+	}
+}
+
 func initTokenService(tokenRepository models.TokenRepository) (models.TokenService, error) {
+	config, err := loadTokenServiceConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	config.TokenRepository = tokenRepository
+
+	return service.NewTokenService(config), nil
+}
+
+// read keys, secret and expirations, shared by startup and reload
+func loadTokenServiceConfig() (*service.TokenServiceConfig, error) {
 
 	privateKeyFile := os.Getenv("PRIVATE_KEY_FILE")
 	privateKeyString, err := ioutil.ReadFile(privateKeyFile)
@@ -63,10 +180,18 @@ func initTokenService(tokenRepository models.TokenRepository) (models.TokenServi
 		return nil, fmt.Errorf("could not parse public key: %w", err)
 	}
 
+	if !privateKey.PublicKey.Equal(publicKey) {
+		return nil, fmt.Errorf("public key does not match private key")
+	}
+
 	refreshSecret := os.Getenv("REFRESH_SECRET")
 	tokenExpiration := os.Getenv("TOKEN_EXP")
 	refreshTokenExpiration := os.Getenv("REFRESH_TOKEN_EXP")
 
+	if refreshSecret == "" {
+		return nil, fmt.Errorf("REFRESH_SECRET is empty")
+	}
+
 	tokenExpirationSec, err := strconv.ParseInt(tokenExpiration, 0, 64)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse TOKEN_EXP as int: %v", err)
@@ -77,12 +202,15 @@ func initTokenService(tokenRepository models.TokenRepository) (models.TokenServi
 		return nil, fmt.Errorf("could not parse REFRESH_TOKEN_EXP as int: %v", err)
 	}
 
-	return service.NewTokenService(&service.TokenServiceConfig{
-		TokenRepository:           tokenRepository,
+	if tokenExpirationSec <= 0 || refreshTokenExpirationSec <= 0 {
+		return nil, fmt.Errorf("TOKEN_EXP and REFRESH_TOKEN_EXP must be positive")
+	}
+
+	return &service.TokenServiceConfig{
 		PrivateKey:                privateKey,
 		PublicKey:                 publicKey,
 		RefreshSecret:             refreshSecret,
 		TokenExpirationSec:        tokenExpirationSec,
 		RefreshTokenExpirationSec: refreshTokenExpirationSec,
-	}), nil
+	}, nil
 }
@@ -19,7 +19,7 @@ type DataSources struct {
 // Connecting to data sources
 func InitDataSources() (*DataSources, error) {
 
-	database, err := initPostgres()
+	database, err := InitPostgres()
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +47,8 @@ func (d *DataSources) Close() error {
 	return nil
 }
 
-func initPostgres() (*sqlx.DB, error) {
+// Connecting to Postgresql only, used by commands that do not need Redis
+func InitPostgres() (*sqlx.DB, error) {
 
 	log.Printf("Initilazing data sources\n")
 
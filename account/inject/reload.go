@@ -0,0 +1,124 @@
+package inject
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"memorize/service"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadConfigFile sets environment variables from CONFIG_FILE if it is set
+// the file uses the same KEY=VALUE format as .dev.env
+func LoadConfigFile() error {
+	configFile := os.Getenv("CONFIG_FILE")
+	if configFile == "" {
+		return nil
+	}
+
+	file, err := os.Open(configFile)
+	if err != nil {
+		return fmt.Errorf("could not open config file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid line in config file: %q", line)
+		}
+
+		if err := os.Setenv(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])); err != nil {
+			return fmt.Errorf("could not set %v: %w", parts[0], err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("could not read config file: %w", err)
+	}
+
+	return nil
+}
+
+// ReloadServices re-reads configuration and key files and swaps them into services
+// current configuration is kept if anything fails to load or validate
+func ReloadServices(services *Services) error {
+	tokenService, ok := services.TokenService.(service.ReloadableTokenService)
+	if !ok {
+		return fmt.Errorf("token service does not support reloading")
+	}
+
+	if err := LoadConfigFile(); err != nil {
+		return err
+	}
+
+	config, err := loadTokenServiceConfig()
+	if err != nil {
+		return err
+	}
+
+	gracePeriods, err := loadGracePeriods(config)
+	if err != nil {
+		return err
+	}
+
+	changes := tokenService.Reload(config, gracePeriods)
+
+	if len(changes) == 0 {
+		log.Println("Configuration reloaded, nothing changed")
+		return nil
+	}
+
+	log.Printf(
+		"Configuration reloaded, changed: %v. Previous signing key accepted for %v, refresh secret for %v\n",
+		strings.Join(changes, ", "),
+		gracePeriods.SigningKey,
+		gracePeriods.RefreshSecret,
+	)
+
+	return nil
+}
+
+// KEY_GRACE_PERIOD in seconds, defaults to access token lifetime
+// REFRESH_SECRET_GRACE_PERIOD in seconds, defaults to refresh token lifetime,
+// so rotating the secret does not sign out sessions before their tokens expire
+func loadGracePeriods(config *service.TokenServiceConfig) (service.GracePeriods, error) {
+	signingKey, err := loadGracePeriod("KEY_GRACE_PERIOD", config.TokenExpirationSec)
+	if err != nil {
+		return service.GracePeriods{}, err
+	}
+
+	refreshSecret, err := loadGracePeriod("REFRESH_SECRET_GRACE_PERIOD", config.RefreshTokenExpirationSec)
+	if err != nil {
+		return service.GracePeriods{}, err
+	}
+
+	return service.GracePeriods{
+		SigningKey:    signingKey,
+		RefreshSecret: refreshSecret,
+	}, nil
+}
+
+func loadGracePeriod(name string, defaultSec int64) (time.Duration, error) {
+	gracePeriod := os.Getenv(name)
+	if gracePeriod == "" {
+		return time.Duration(defaultSec) * time.Second, nil
+	}
+
+	gracePeriodSec, err := strconv.ParseInt(gracePeriod, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse %v as int: %w", name, err)
+	}
+
+	return time.Duration(gracePeriodSec) * time.Second, nil
+}
@@ -2,16 +2,35 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"log"
+	"memorize/cli"
 	"memorize/inject"
+	"memorize/migrations"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
+
+	"google.golang.org/grpc"
 )
 
 func main() {
+	if err := inject.LoadConfigFile(); err != nil {
+		log.Fatalf("Unable to load config file: %v\n", err)
+	}
+
+	// subcommands like `account migrate up`
+	if len(os.Args) > 1 {
+		if err := cli.Run(os.Args[1:]); err != nil {
+			log.Fatalf("%v\n", err)
+		}
+		return
+	}
+
 	log.Println("Starting server....")
 
 	dataSources, err := inject.InitDataSources()
@@ -19,6 +38,10 @@ func main() {
 		log.Fatalf("Unable to initialze data sources: %v\n", err)
 	}
 
+	if err := migrateOnStart(dataSources); err != nil {
+		log.Fatalf("Unable to migrate database: %v\n", err)
+	}
+
 	repositories := inject.InitRepositories(dataSources)
 
 	services, err := inject.InitServices(repositories)
@@ -37,13 +60,101 @@ func main() {
 		Handler: router,
 	}
 
+	// gRPC API runs alongside http one if GRPC_ADDR is set
+	var rpcServer *grpc.Server
+	if rpcAddr := os.Getenv("GRPC_ADDR"); rpcAddr != "" {
+		rpcServer = inject.InitRPCServer(services)
+
+		if err := serveRPC(rpcServer, rpcAddr); err != nil {
+			log.Fatalf("Unable to initilaze gRPC server: %v\n", err)
+		}
+	}
+
+	// usage of API versions is published with expvar on METRICS_ADDR, it should not be public
+	if metricsAddr := os.Getenv("METRICS_ADDR"); metricsAddr != "" {
+		if err := serveMetrics(metricsAddr); err != nil {
+			log.Fatalf("Unable to initilaze metrics server: %v\n", err)
+		}
+	}
+
 	log.Println("Server started.")
 
-	gracefullyShutdown(dataSources, server)
+	reloadOnSignal(services)
+
+	gracefullyShutdown(dataSources, server, rpcServer)
 }
 
-// Shutdown server and close connection
-func gracefullyShutdown(dataSources *inject.DataSources, server *http.Server) {
+// Apply pending migrations if MIGRATE_ON_START is set
+func migrateOnStart(dataSources *inject.DataSources) error {
+	migrateOnStart, _ := strconv.ParseBool(os.Getenv("MIGRATE_ON_START"))
+	if !migrateOnStart {
+		return nil
+	}
+
+	migrator, err := migrations.NewMigrator(dataSources.DB)
+	if err != nil {
+		return err
+	}
+
+	log.Println("Applying database migrations....")
+	return migrator.Up(context.Background())
+}
+
+// Reload configuration and keys on SIGHUP
+func reloadOnSignal(services *inject.Services) {
+	reload := make(chan os.Signal, 1)
+
+	signal.Notify(reload, syscall.SIGHUP)
+
+	go func() {
+		for range reload {
+			log.Println("Reloading configuration....")
+
+			if err := inject.ReloadServices(services); err != nil {
+				log.Printf("Unable to reload configuration, keeping current one: %v\n", err)
+			}
+		}
+	}()
+}
+
+// Listen on addr and serve gRPC requests in background
+func serveRPC(rpcServer *grpc.Server, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := rpcServer.Serve(listener); err != nil {
+			log.Fatalf("Failed to initilize gRPC server: %v\n", err)
+		}
+	}()
+
+	log.Printf("gRPC listening on %v\n", listener.Addr())
+
+	return nil
+}
+
+// Listen on addr and serve expvar metrics in background
+func serveMetrics(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := http.Serve(listener, expvar.Handler()); err != nil {
+			log.Fatalf("Failed to serve metrics: %v\n", err)
+		}
+	}()
+
+	log.Printf("Metrics listening on %v\n", listener.Addr())
+
+	return nil
+}
+
+// Shutdown servers and close connection
+func gracefullyShutdown(dataSources *inject.DataSources, server *http.Server, rpcServer *grpc.Server) {
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to initilize server: %v\n", err)
@@ -72,6 +183,10 @@ func gracefullyShutdown(dataSources *inject.DataSources, server *http.Server) {
 
 	// Shutdown server
 	log.Println("Shutting down server....")
+	if rpcServer != nil {
+		rpcServer.GracefulStop()
+	}
+
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v\n", err)
 	}
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS oauth_clients (
+  client_id VARCHAR PRIMARY KEY,
+  secret_hash VARCHAR NOT NULL DEFAULT '',
+  name VARCHAR NOT NULL,
+  redirect_uris TEXT[] NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
\ No newline at end of file
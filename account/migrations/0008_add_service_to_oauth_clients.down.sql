@@ -0,0 +1 @@
+ALTER TABLE oauth_clients DROP COLUMN service, DROP COLUMN audiences;
\ No newline at end of file
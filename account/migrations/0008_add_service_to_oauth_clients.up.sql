@@ -0,0 +1,3 @@
+ALTER TABLE oauth_clients
+ADD COLUMN service BOOLEAN NOT NULL DEFAULT false,
+ADD COLUMN audiences TEXT[] NOT NULL DEFAULT '{}';
\ No newline at end of file
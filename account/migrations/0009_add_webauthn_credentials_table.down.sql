@@ -0,0 +1,3 @@
+DROP TABLE webauthn_credentials;
+
+ALTER TABLE users DROP COLUMN second_factor;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE users
+ADD COLUMN locale VARCHAR(16) NOT NULL DEFAULT '';
\ No newline at end of file
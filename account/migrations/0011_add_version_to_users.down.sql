@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN version, DROP COLUMN updated_at;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE login_history;
+DROP INDEX users_lower_login_idx;
\ No newline at end of file
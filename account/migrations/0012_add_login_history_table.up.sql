@@ -0,0 +1,14 @@
+-- logins differing only in case are the same login, existing duplicates must be renamed before this migration
+CREATE UNIQUE INDEX IF NOT EXISTS users_lower_login_idx ON users (lower(login));
+
+-- previous logins of users, reserved for their user until reserved_until
+CREATE TABLE IF NOT EXISTS login_history (
+  id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+  uid uuid NOT NULL REFERENCES users(uid) ON DELETE CASCADE,
+  login VARCHAR NOT NULL,
+  changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  reserved_until TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS login_history_uid_idx ON login_history (uid);
+CREATE INDEX IF NOT EXISTS login_history_lower_login_idx ON login_history (lower(login));
\ No newline at end of file
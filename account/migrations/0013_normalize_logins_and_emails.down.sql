@@ -0,0 +1,2 @@
+ALTER TABLE login_history DROP COLUMN normalized_login;
+ALTER TABLE users DROP COLUMN normalized_login, DROP COLUMN normalized_email;
\ No newline at end of file
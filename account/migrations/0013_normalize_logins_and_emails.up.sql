@@ -0,0 +1,7 @@
+ALTER TABLE users
+ADD COLUMN normalized_login VARCHAR,
+ADD COLUMN normalized_email VARCHAR NOT NULL DEFAULT '';
+
+-- keys are filled by normalizeLogins data step with normalize package of the service,
+-- they are required and unique from 0015 on
+ALTER TABLE login_history ADD COLUMN normalized_login VARCHAR;
\ No newline at end of file
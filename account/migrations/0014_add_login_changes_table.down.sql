@@ -0,0 +1 @@
+DROP TABLE login_changes;
\ No newline at end of file
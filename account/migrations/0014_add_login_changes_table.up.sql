@@ -0,0 +1,13 @@
+-- every login change of users, rows are never deleted so changes can be counted for rate limit
+CREATE TABLE IF NOT EXISTS login_changes (
+  id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+  uid uuid NOT NULL REFERENCES users(uid) ON DELETE CASCADE,
+  previous_login VARCHAR NOT NULL,
+  changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS login_changes_uid_changed_at_idx ON login_changes (uid, changed_at);
+
+-- changes made before are known only from history rows that were not released
+INSERT INTO login_changes (uid, previous_login, changed_at)
+SELECT uid, login, changed_at FROM login_history;
\ No newline at end of file
@@ -0,0 +1,9 @@
+DROP INDEX login_history_normalized_login_idx;
+DROP INDEX users_normalized_email_idx;
+DROP INDEX users_normalized_login_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_lower_login_idx ON users (lower(login));
+CREATE INDEX IF NOT EXISTS login_history_lower_login_idx ON login_history (lower(login));
+
+ALTER TABLE login_history ALTER COLUMN normalized_login DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN normalized_login DROP NOT NULL;
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- users whose logins became equal after normalization must be renamed before migrating, all but one of each group
+DO $$
+DECLARE
+  collisions TEXT;
+BEGIN
+  SELECT string_agg(logins, '; ') INTO collisions FROM (
+    SELECT string_agg(login || ' (' || uid || ')', ', ' ORDER BY login) AS logins
+    FROM users
+    GROUP BY normalized_login
+    HAVING count(*) > 1
+  ) groups;
+
+  IF collisions IS NOT NULL THEN
+    RAISE EXCEPTION 'logins collide after normalization, rename users: %', collisions;
+  END IF;
+END
+$$;
+
+ALTER TABLE users ALTER COLUMN normalized_login SET NOT NULL;
+ALTER TABLE login_history ALTER COLUMN normalized_login SET NOT NULL;
+
+DROP INDEX IF EXISTS users_lower_login_idx;
+DROP INDEX IF EXISTS login_history_lower_login_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_normalized_login_idx ON users (normalized_login);
+CREATE INDEX IF NOT EXISTS users_normalized_email_idx ON users (normalized_email);
+CREATE INDEX IF NOT EXISTS login_history_normalized_login_idx ON login_history (normalized_login);
\ No newline at end of file
@@ -0,0 +1 @@
+-- up only checks logins, there is nothing to undo
\ No newline at end of file
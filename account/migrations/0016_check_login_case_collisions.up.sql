@@ -0,0 +1,18 @@
+-- logins differing only in case are the same login, 0012 indexed them without telling which users collide,
+-- so every database is checked here and listed users must be renamed before migrating
+DO $$
+DECLARE
+  collisions TEXT;
+BEGIN
+  SELECT string_agg(logins, '; ') INTO collisions FROM (
+    SELECT string_agg(login || ' (' || uid || ')', ', ' ORDER BY login) AS logins
+    FROM users
+    GROUP BY lower(login)
+    HAVING count(*) > 1
+  ) groups;
+
+  IF collisions IS NOT NULL THEN
+    RAISE EXCEPTION 'logins differ only in case, rename users: %', collisions;
+  END IF;
+END
+$$;
\ No newline at end of file
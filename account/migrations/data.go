@@ -0,0 +1,114 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"memorize/normalize"
+	"strings"
+)
+
+// data steps by version of migration they belong to
+var dataSteps = map[uint]DataStep{
+	13: normalizeLogins,
+}
+
+// normalizeLogins fills keys of logins and emails with normalize package, so they are
+// same keys the service looks users up by, SQL normalize() and lower() do not fold case like it
+// users whose logins cannot be normalized must be renamed before migrating, they could not sign in
+func normalizeLogins(ctx context.Context, tx *sql.Tx) error {
+	type userRow struct {
+		uid   string
+		login string
+		email string
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT uid, login, coalesce(email, '') FROM users")
+	if err != nil {
+		return fmt.Errorf("could not read users: %w", err)
+	}
+
+	var users []userRow
+	for rows.Next() {
+		var user userRow
+		if err := rows.Scan(&user.uid, &user.login, &user.email); err != nil {
+			rows.Close()
+			return fmt.Errorf("could not read user: %w", err)
+		}
+
+		users = append(users, user)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read users: %w", err)
+	}
+
+	var invalid []string
+	for _, user := range users {
+		key, err := normalize.LoginKey(user.login)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%v (%v)", user.login, user.uid))
+			continue
+		}
+
+		query := "UPDATE users SET normalized_login=$1, normalized_email=$2 WHERE uid=$3"
+		if _, err := tx.ExecContext(ctx, query, key, normalize.EmailKey(user.email), user.uid); err != nil {
+			return fmt.Errorf("could not normalize login of user %v: %w", user.uid, err)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("logins cannot be normalized, rename users: %v", strings.Join(invalid, ", "))
+	}
+
+	return normalizeLoginHistory(ctx, tx)
+}
+
+// previous logins that cannot be normalized are not reserved anymore, nobody can take them
+func normalizeLoginHistory(ctx context.Context, tx *sql.Tx) error {
+	type historyRow struct {
+		id    string
+		login string
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, login FROM login_history")
+	if err != nil {
+		return fmt.Errorf("could not read login history: %w", err)
+	}
+
+	var history []historyRow
+	for rows.Next() {
+		var row historyRow
+		if err := rows.Scan(&row.id, &row.login); err != nil {
+			rows.Close()
+			return fmt.Errorf("could not read login history: %w", err)
+		}
+
+		history = append(history, row)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read login history: %w", err)
+	}
+
+	for _, row := range history {
+		key, err := normalize.LoginKey(row.login)
+		if err != nil {
+			log.Printf("Releasing previous login %v that cannot be normalized\n", row.login)
+
+			if _, err := tx.ExecContext(ctx, "DELETE FROM login_history WHERE id=$1", row.id); err != nil {
+				return fmt.Errorf("could not release previous login %v: %w", row.login, err)
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE login_history SET normalized_login=$1 WHERE id=$2", key, row.id); err != nil {
+			return fmt.Errorf("could not normalize previous login %v: %w", row.login, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,367 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SQL files are embedded so the binary can manage its own schema
+//
+//go:embed *.sql
+var files embed.FS
+
+// advisory lock key shared by every migrator of this database
+const lockKey int64 = 7311842907
+
+// file names look like 0001_add_users_table.up.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration holds both directions of a single schema change
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+	// Data changes rows after Up in its transaction, for changes SQL cannot compute
+	Data DataStep
+}
+
+// DataStep is Go part of migration
+type DataStep func(ctx context.Context, tx *sql.Tx) error
+
+// MigrationStatus tells if a migration is applied
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Status of the database schema
+type Status struct {
+	Version    uint
+	Dirty      bool
+	Migrations []MigrationStatus
+}
+
+// Migrator applies migrations, version is stored in schema_migrations
+// table compatible with the migrate/migrate tool used before
+type Migrator struct {
+	DB         *sqlx.DB
+	Migrations []Migration
+}
+
+// factory for migrator with embedded migrations
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+	migrations, err := Load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range migrations {
+		migrations[i].Data = dataSteps[migrations[i].Version]
+	}
+
+	return &Migrator{
+		DB:         db,
+		Migrations: migrations,
+	}, nil
+}
+
+// Load reads migrations from file system sorted by version
+func Load(fileSystem fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fileSystem, ".")
+	if err != nil {
+		return nil, fmt.Errorf("could not read migrations: %w", err)
+	}
+
+	byVersion := map[uint]*Migration{}
+
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %v: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fileSystem, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("could not read migration %v: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %v", version)
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %v must have both up and down files", migration.Version)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.Migrations) == 0 {
+		return nil
+	}
+
+	return m.To(ctx, m.Migrations[len(m.Migrations)-1].Version)
+}
+
+// Down rolls back given number of applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		version, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		target := version
+		for i := m.index(version); i >= 0 && steps > 0; i-- {
+			steps--
+			target = m.previousVersion(i)
+		}
+
+		return m.migrate(ctx, conn, version, target)
+	})
+}
+
+// To migrates up or down to the given version, 0 rolls back everything
+func (m *Migrator) To(ctx context.Context, version uint) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("migration %v does not exist", version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		return m.migrate(ctx, conn, current, version)
+	})
+}
+
+// Status reports current version and which migrations are applied
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := createVersionTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Version: version,
+		Dirty:   dirty,
+	}
+
+	for _, migration := range m.Migrations {
+		status.Migrations = append(status.Migrations, MigrationStatus{
+			Migration: migration,
+			Applied:   migration.Version <= version,
+		})
+	}
+
+	return status, nil
+}
+
+// apply migrations one by one from current version to target
+func (m *Migrator) migrate(ctx context.Context, conn *sql.Conn, current uint, target uint) error {
+	if current != 0 && m.index(current) < 0 {
+		return fmt.Errorf("database version %v is unknown to this binary", current)
+	}
+
+	for current < target {
+		migration := m.Migrations[m.index(current)+1]
+
+		log.Printf("Applying migration %v_%v\n", migration.Version, migration.Name)
+		if err := apply(ctx, conn, migration.Up, migration.Data, migration.Version); err != nil {
+			return fmt.Errorf("migration %v up failed: %w", migration.Version, err)
+		}
+
+		current = migration.Version
+	}
+
+	for current > target {
+		i := m.index(current)
+		migration := m.Migrations[i]
+		previous := m.previousVersion(i)
+
+		log.Printf("Rolling back migration %v_%v\n", migration.Version, migration.Name)
+		if err := apply(ctx, conn, migration.Down, nil, previous); err != nil {
+			return fmt.Errorf("migration %v down failed: %w", migration.Version, err)
+		}
+
+		current = previous
+	}
+
+	return nil
+}
+
+// index of migration with given version, -1 for version 0 or unknown version
+func (m *Migrator) index(version uint) int {
+	for i, migration := range m.Migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// version before the migration at index i
+func (m *Migrator) previousVersion(i int) uint {
+	if i <= 0 {
+		return 0
+	}
+
+	return m.Migrations[i-1].Version
+}
+
+// run callback holding an advisory lock so concurrent migrators wait for each other
+func (m *Migrator) withLock(ctx context.Context, callback func(conn *sql.Conn) error) error {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("could not acquire migration lock: %w", err)
+	}
+
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey); err != nil {
+			log.Printf("Could not release migration lock: %v\n", err)
+		}
+	}()
+
+	if err := createVersionTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return callback(conn)
+}
+
+func createVersionTable(ctx context.Context, conn *sql.Conn) error {
+	query := "CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)"
+
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("could not create schema_migrations table: %w", err)
+	}
+
+	return nil
+}
+
+func readVersion(ctx context.Context, conn *sql.Conn) (uint, bool, error) {
+	var version uint
+	var dirty bool
+
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, fmt.Errorf("could not read schema version: %w", err)
+	}
+
+	return version, dirty, nil
+}
+
+// version of the database, fails if previous migration was left half applied
+func currentVersion(ctx context.Context, conn *sql.Conn) (uint, error) {
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	if dirty {
+		return 0, fmt.Errorf("database version %v is dirty, fix it manually", version)
+	}
+
+	return version, nil
+}
+
+// run migration sql, then its data step, and store new version in one transaction
+func apply(ctx context.Context, conn *sql.Conn, query string, data DataStep, version uint) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if data != nil {
+		if err := data(ctx, tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if version > 0 {
+		if _, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)",
+			version,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(test *testing.T) {
+	test.Run("Embedded migrations", func(test *testing.T) {
+		migrations, err := Load(files)
+		assert.NoError(test, err)
+
+		assert.NotEmpty(test, migrations)
+		assert.Equal(test, uint(1), migrations[0].Version)
+		assert.Equal(test, "add_users_table", migrations[0].Name)
+
+		for i := 1; i < len(migrations); i++ {
+			assert.Less(test, migrations[i-1].Version, migrations[i].Version)
+		}
+	})
+
+	test.Run("Sorted by version", func(test *testing.T) {
+		fileSystem := fstest.MapFS{
+			"0010_second.up.sql":   {Data: []byte("up 10")},
+			"0010_second.down.sql": {Data: []byte("down 10")},
+			"0002_first.up.sql":    {Data: []byte("up 2")},
+			"0002_first.down.sql":  {Data: []byte("down 2")},
+			"README.md":            {Data: []byte("ignored")},
+		}
+
+		migrations, err := Load(fileSystem)
+		assert.NoError(test, err)
+
+		assert.Equal(test, []Migration{
+			{Version: 2, Name: "first", Up: "up 2", Down: "down 2"},
+			{Version: 10, Name: "second", Up: "up 10", Down: "down 10"},
+		}, migrations)
+	})
+
+	test.Run("Missing down migration", func(test *testing.T) {
+		fileSystem := fstest.MapFS{
+			"0001_first.up.sql": {Data: []byte("up 1")},
+		}
+
+		_, err := Load(fileSystem)
+		assert.Error(test, err)
+	})
+
+	test.Run("Duplicate version", func(test *testing.T) {
+		fileSystem := fstest.MapFS{
+			"0001_first.up.sql":    {Data: []byte("up 1")},
+			"0001_first.down.sql":  {Data: []byte("down 1")},
+			"0001_second.up.sql":   {Data: []byte("up 1")},
+			"0001_second.down.sql": {Data: []byte("down 1")},
+		}
+
+		_, err := Load(fileSystem)
+		assert.Error(test, err)
+	})
+}
+
+func TestDataSteps(test *testing.T) {
+	migrations, err := Load(files)
+	assert.NoError(test, err)
+
+	versions := map[uint]bool{}
+	for _, migration := range migrations {
+		versions[migration.Version] = true
+	}
+
+	for version := range dataSteps {
+		assert.True(test, versions[version], "data step of missing migration %v", version)
+	}
+}
+
+func TestPreviousVersion(test *testing.T) {
+	migrator := &Migrator{
+		Migrations: []Migration{
+			{Version: 1},
+			{Version: 2},
+			{Version: 5},
+		},
+	}
+
+	assert.Equal(test, uint(0), migrator.previousVersion(migrator.index(1)))
+	assert.Equal(test, uint(2), migrator.previousVersion(migrator.index(5)))
+	assert.Equal(test, -1, migrator.index(3))
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthorizationCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuthorizationCodeRepository) Save(ctx context.Context, code *models.AuthorizationCode, expiresIn time.Duration) error {
+	args := m.Called(ctx, code, expiresIn)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockAuthorizationCodeRepository) Consume(ctx context.Context, code string) (*models.AuthorizationCode, error) {
+	args := m.Called(ctx, code)
+
+	var r0 *models.AuthorizationCode
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.AuthorizationCode)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
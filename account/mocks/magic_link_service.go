@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMagicLinkService struct {
+	mock.Mock
+}
+
+func (m *MockMagicLinkService) Send(ctx context.Context, login string, clientIP string) (string, error) {
+	args := m.Called(ctx, login, clientIP)
+
+	var r0 string
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(string)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockMagicLinkService) Verify(ctx context.Context, token string, nonce string) (*models.User, error) {
+	args := m.Called(ctx, token, nonce)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(ctx context.Context, mail *models.Mail) error {
+	args := m.Called(ctx, mail)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
@@ -0,0 +1,66 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOAuthClientRepository struct {
+	mock.Mock
+}
+
+func (m *MockOAuthClientRepository) Create(ctx context.Context, client *models.OAuthClient) error {
+	args := m.Called(ctx, client)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockOAuthClientRepository) FindByID(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	args := m.Called(ctx, clientID)
+
+	var r0 *models.OAuthClient
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.OAuthClient)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockOAuthClientRepository) FindAll(ctx context.Context) ([]*models.OAuthClient, error) {
+	args := m.Called(ctx)
+
+	var r0 []*models.OAuthClient
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.OAuthClient)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockOAuthClientRepository) Delete(ctx context.Context, clientID string) error {
+	args := m.Called(ctx, clientID)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
@@ -0,0 +1,115 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOAuthService struct {
+	mock.Mock
+}
+
+func (m *MockOAuthService) RegisterClient(ctx context.Context, client *models.OAuthClient, confidential bool) (string, error) {
+	args := m.Called(ctx, client, confidential)
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return args.String(0), r1
+}
+
+func (m *MockOAuthService) ListClients(ctx context.Context) ([]*models.OAuthClient, error) {
+	args := m.Called(ctx)
+
+	var r0 []*models.OAuthClient
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.OAuthClient)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockOAuthService) DeleteClient(ctx context.Context, clientID string) error {
+	args := m.Called(ctx, clientID)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockOAuthService) ValidateAuthorizationRequest(ctx context.Context, request *models.AuthorizationRequest) error {
+	args := m.Called(ctx, request)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockOAuthService) Authorize(ctx context.Context, request *models.AuthorizationRequest, user *models.User) (string, error) {
+	args := m.Called(ctx, request, user)
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return args.String(0), r1
+}
+
+func (m *MockOAuthService) Token(ctx context.Context, request *models.OAuthTokenRequest) (*models.OAuthTokenResponse, error) {
+	args := m.Called(ctx, request)
+
+	var r0 *models.OAuthTokenResponse
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.OAuthTokenResponse)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockOAuthService) Introspect(ctx context.Context, request *models.TokenIntrospectionRequest) (*models.TokenIntrospectionResponse, error) {
+	args := m.Called(ctx, request)
+
+	var r0 *models.TokenIntrospectionResponse
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.TokenIntrospectionResponse)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockOAuthService) Revoke(ctx context.Context, request *models.TokenRevocationRequest) error {
+	args := m.Called(ctx, request)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
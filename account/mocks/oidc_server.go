@@ -0,0 +1,168 @@
+package mocks
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// MockOIDCServer is a local OpenID Connect provider for tests
+// it signs in Profile without asking anything and supports PKCE
+type MockOIDCServer struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+	Profile      map[string]interface{}
+
+	mutex      sync.Mutex
+	codes      map[string]mockAuthorization
+	tokens     map[string]bool
+	codeNumber int
+}
+
+// pending authorization of mock server
+type mockAuthorization struct {
+	redirectURI   string
+	codeChallenge string
+}
+
+// starts mock server, close it with Close
+func NewMockOIDCServer(clientID string, clientSecret string, profile map[string]interface{}) *MockOIDCServer {
+	server := &MockOIDCServer{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Profile:      profile,
+		codes:        map[string]mockAuthorization{},
+		tokens:       map[string]bool{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", server.discovery)
+	mux.HandleFunc("/authorize", server.authorize)
+	mux.HandleFunc("/token", server.token)
+	mux.HandleFunc("/userinfo", server.userinfo)
+
+	server.Server = httptest.NewServer(mux)
+
+	return server
+}
+
+// Authorize follows authorization url like a browser would
+// returns code and state the provider sends back to redirect uri
+func (s *MockOIDCServer) Authorize(authURL string) (string, string, error) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	response, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer response.Body.Close()
+
+	location, err := url.Parse(response.Header.Get("Location"))
+	if err != nil || response.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("authorization failed with status %v", response.StatusCode)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+func (s *MockOIDCServer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"userinfo_endpoint":      s.URL + "/userinfo",
+	})
+}
+
+func (s *MockOIDCServer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("client_id") != s.ClientID || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	s.codeNumber++
+	code := fmt.Sprintf("code-%d", s.codeNumber)
+	s.codes[code] = mockAuthorization{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+	}
+	s.mutex.Unlock()
+
+	redirectURL, _ := url.Parse(query.Get("redirect_uri"))
+	redirectQuery := redirectURL.Query()
+	redirectQuery.Set("code", code)
+	redirectQuery.Set("state", query.Get("state"))
+	redirectURL.RawQuery = redirectQuery.Encode()
+
+	http.Redirect(w, r, redirectURL.String(), http.StatusFound)
+}
+
+func (s *MockOIDCServer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	if r.PostForm.Get("client_id") != s.ClientID || r.PostForm.Get("client_secret") != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mutex.Lock()
+	authorization, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mutex.Unlock()
+
+	hash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	if !ok || authorization.redirectURI != r.PostForm.Get("redirect_uri") || authorization.codeChallenge != challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	accessToken := "access-" + r.PostForm.Get("code")
+
+	s.mutex.Lock()
+	s.tokens[accessToken] = true
+	s.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (s *MockOIDCServer) userinfo(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	valid := s.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	s.mutex.Unlock()
+
+	if !valid {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.Profile)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,79 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPersonalAccessTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockPersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	args := m.Called(ctx, token)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockPersonalAccessTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	args := m.Called(ctx, tokenHash)
+
+	var r0 *models.PersonalAccessToken
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.PersonalAccessToken)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockPersonalAccessTokenRepository) FindByUserID(ctx context.Context, uid uuid.UUID) ([]*models.PersonalAccessToken, error) {
+	args := m.Called(ctx, uid)
+
+	var r0 []*models.PersonalAccessToken
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.PersonalAccessToken)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockPersonalAccessTokenRepository) Delete(ctx context.Context, uid uuid.UUID, tokenID uuid.UUID) error {
+	args := m.Called(ctx, uid, tokenID)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockPersonalAccessTokenRepository) UpdateLastUsed(ctx context.Context, tokenID uuid.UUID, lastUsedAt time.Time) error {
+	args := m.Called(ctx, tokenID, lastUsedAt)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
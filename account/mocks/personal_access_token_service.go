@@ -0,0 +1,73 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPersonalAccessTokenService struct {
+	mock.Mock
+}
+
+func (m *MockPersonalAccessTokenService) Create(ctx context.Context, uid uuid.UUID, token *models.PersonalAccessToken, expiresIn time.Duration) error {
+	args := m.Called(ctx, uid, token, expiresIn)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockPersonalAccessTokenService) List(ctx context.Context, uid uuid.UUID) ([]*models.PersonalAccessToken, error) {
+	args := m.Called(ctx, uid)
+
+	var r0 []*models.PersonalAccessToken
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.PersonalAccessToken)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockPersonalAccessTokenService) Revoke(ctx context.Context, uid uuid.UUID, tokenID uuid.UUID) error {
+	args := m.Called(ctx, uid, tokenID)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockPersonalAccessTokenService) Validate(ctx context.Context, token string) (*models.User, []string, error) {
+	args := m.Called(ctx, token)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 []string
+	if args.Get(1) != nil {
+		r1 = args.Get(1).([]string)
+	}
+
+	var r2 error
+	if args.Get(2) != nil {
+		r2 = args.Get(2).(error)
+	}
+
+	return r0, r1, r2
+}
@@ -0,0 +1,63 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSocialLoginStateRepository struct {
+	mock.Mock
+}
+
+func (m *MockSocialLoginStateRepository) Save(ctx context.Context, state *models.SocialLoginState, expiresIn time.Duration) error {
+	args := m.Called(ctx, state, expiresIn)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockSocialLoginStateRepository) Consume(ctx context.Context, state string) (*models.SocialLoginState, error) {
+	args := m.Called(ctx, state)
+
+	var r0 *models.SocialLoginState
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.SocialLoginState)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockSocialLoginStateRepository) SaveSigninCode(ctx context.Context, code string, userID uuid.UUID, expiresIn time.Duration) error {
+	args := m.Called(ctx, code, userID, expiresIn)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockSocialLoginStateRepository) ConsumeSigninCode(ctx context.Context, code string) (uuid.UUID, error) {
+	args := m.Called(ctx, code)
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return args.Get(0).(uuid.UUID), r1
+}
@@ -0,0 +1,105 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSocialService struct {
+	mock.Mock
+}
+
+func (m *MockSocialService) Providers() []string {
+	args := m.Called()
+
+	var r0 []string
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]string)
+	}
+
+	return r0
+}
+
+func (m *MockSocialService) Start(ctx context.Context, provider string, linkUserID uuid.UUID) (string, error) {
+	args := m.Called(ctx, provider, linkUserID)
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return args.String(0), r1
+}
+
+func (m *MockSocialService) Callback(ctx context.Context, provider string, code string, state string) (*models.User, error) {
+	args := m.Called(ctx, provider, code, state)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockSocialService) Unlink(ctx context.Context, provider string, uid uuid.UUID) error {
+	args := m.Called(ctx, provider, uid)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockSocialService) ListIdentities(ctx context.Context, uid uuid.UUID) ([]*models.UserIdentity, error) {
+	args := m.Called(ctx, uid)
+
+	var r0 []*models.UserIdentity
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.UserIdentity)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockSocialService) SigninCode(ctx context.Context, userID uuid.UUID) (string, error) {
+	args := m.Called(ctx, userID)
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return args.String(0), r1
+}
+
+func (m *MockSocialService) Signin(ctx context.Context, code string) (*models.User, error) {
+	args := m.Called(ctx, code)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
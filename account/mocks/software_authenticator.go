@@ -0,0 +1,246 @@
+package mocks
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"memorize/webauthn"
+	"sort"
+)
+
+// SoftwareAuthenticator is a WebAuthn authenticator in memory for tests
+// it creates P-256 credentials with "none" attestation
+type SoftwareAuthenticator struct {
+	Origin string
+	// set to false to act like a security key without PIN or biometrics
+	UserVerified bool
+
+	credentials map[string]*softwareCredential
+}
+
+type softwareCredential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	key        *ecdsa.PrivateKey
+	signCount  uint32
+}
+
+func NewSoftwareAuthenticator(origin string) *SoftwareAuthenticator {
+	return &SoftwareAuthenticator{
+		Origin:       origin,
+		UserVerified: true,
+		credentials:  map[string]*softwareCredential{},
+	}
+}
+
+// Create responds to registration options like navigator.credentials.create
+func (a *SoftwareAuthenticator) Create(options *webauthn.CreationOptions) (*webauthn.AttestationResponse, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	credential := &softwareCredential{
+		id:         id,
+		rpID:       options.PublicKey.RelyingParty.ID,
+		userHandle: options.PublicKey.User.ID,
+		key:        key,
+	}
+
+	a.credentials[string(id)] = credential
+
+	clientDataJSON := a.clientData("webauthn.create", options.PublicKey.Challenge)
+
+	publicKey := encodeCBOR(map[interface{}]interface{}{
+		int64(1):  int64(2),
+		int64(3):  int64(webauthn.AlgorithmES256),
+		int64(-1): int64(1),
+		int64(-2): padded(key.X.Bytes()),
+		int64(-3): padded(key.Y.Bytes()),
+	})
+
+	authData := a.authenticatorData(credential, 0x40)
+	authData = append(authData, make([]byte, 16)...)
+	authData = append(authData, byte(len(id)>>8), byte(len(id)))
+	authData = append(authData, id...)
+	authData = append(authData, publicKey...)
+
+	attestationObject := encodeCBOR(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": authData,
+	})
+
+	return &webauthn.AttestationResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(id),
+		RawID: id,
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAttestationResponse{
+			ClientDataJSON:    clientDataJSON,
+			AttestationObject: attestationObject,
+		},
+	}, nil
+}
+
+// Get responds to signin options like navigator.credentials.get
+func (a *SoftwareAuthenticator) Get(options *webauthn.RequestOptions) (*webauthn.AssertionResponse, error) {
+	credential := a.find(options)
+	if credential == nil {
+		return nil, fmt.Errorf("no credential for %v", options.PublicKey.RPID)
+	}
+
+	credential.signCount++
+
+	clientDataJSON := a.clientData("webauthn.get", options.PublicKey.Challenge)
+	authData := a.authenticatorData(credential, 0)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, credential.key, signed[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.AssertionResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(credential.id),
+		RawID: credential.id,
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAssertionResponse{
+			ClientDataJSON:    clientDataJSON,
+			AuthenticatorData: authData,
+			Signature:         signature,
+			UserHandle:        credential.userHandle,
+		},
+	}, nil
+}
+
+// allowed credential or any discoverable one for relying party
+func (a *SoftwareAuthenticator) find(options *webauthn.RequestOptions) *softwareCredential {
+	for _, allowed := range options.PublicKey.AllowCredentials {
+		if credential, ok := a.credentials[string(allowed.ID)]; ok {
+			return credential
+		}
+	}
+
+	if len(options.PublicKey.AllowCredentials) != 0 {
+		return nil
+	}
+
+	for _, credential := range a.credentials {
+		if credential.rpID == options.PublicKey.RPID {
+			return credential
+		}
+	}
+
+	return nil
+}
+
+func (a *SoftwareAuthenticator) clientData(ceremony string, challenge []byte) []byte {
+	clientDataJSON, _ := json.Marshal(map[string]interface{}{
+		"type":      ceremony,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    a.Origin,
+	})
+
+	return clientDataJSON
+}
+
+func (a *SoftwareAuthenticator) authenticatorData(credential *softwareCredential, flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(credential.rpID))
+
+	flags |= 0x01
+	if a.UserVerified {
+		flags |= 0x04
+	}
+
+	authData := append([]byte{}, rpIDHash[:]...)
+	authData = append(authData, flags)
+	authData = append(authData, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(authData[33:], credential.signCount)
+
+	return authData
+}
+
+// coordinates are fixed size in COSE keys
+func padded(value []byte) []byte {
+	return append(make([]byte, 32-len(value)), value...)
+}
+
+// encodeCBOR encodes values the software authenticator needs
+func encodeCBOR(value interface{}) []byte {
+	buffer := &bytes.Buffer{}
+
+	switch v := value.(type) {
+	case int64:
+		if v >= 0 {
+			writeCBORHead(buffer, 0, uint64(v))
+		} else {
+			writeCBORHead(buffer, 1, uint64(-1-v))
+		}
+	case []byte:
+		writeCBORHead(buffer, 2, uint64(len(v)))
+		buffer.Write(v)
+	case string:
+		writeCBORHead(buffer, 3, uint64(len(v)))
+		buffer.WriteString(v)
+	case map[interface{}]interface{}:
+		// canonical order, shorter encoded keys first
+		keys := make([][]byte, 0, len(v))
+		values := map[string][]byte{}
+
+		for key, item := range v {
+			encodedKey := encodeCBOR(key)
+			keys = append(keys, encodedKey)
+			values[string(encodedKey)] = encodeCBOR(item)
+		}
+
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) < len(keys[j])
+			}
+			return bytes.Compare(keys[i], keys[j]) < 0
+		})
+
+		writeCBORHead(buffer, 5, uint64(len(v)))
+		for _, key := range keys {
+			buffer.Write(key)
+			buffer.Write(values[string(key)])
+		}
+	default:
+		panic(fmt.Sprintf("cbor: unsupported type %T", value))
+	}
+
+	return buffer.Bytes()
+}
+
+func writeCBORHead(buffer *bytes.Buffer, majorType byte, argument uint64) {
+	switch {
+	case argument < 24:
+		buffer.WriteByte(majorType<<5 | byte(argument))
+	case argument <= 0xff:
+		buffer.WriteByte(majorType<<5 | 24)
+		buffer.WriteByte(byte(argument))
+	case argument <= 0xffff:
+		buffer.WriteByte(majorType<<5 | 25)
+		binary.Write(buffer, binary.BigEndian, uint16(argument))
+	case argument <= 0xffffffff:
+		buffer.WriteByte(majorType<<5 | 26)
+		binary.Write(buffer, binary.BigEndian, uint32(argument))
+	default:
+		buffer.WriteByte(majorType<<5 | 27)
+		binary.Write(buffer, binary.BigEndian, argument)
+	}
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"memorize/models"
 	"time"
 
 	"github.com/stretchr/testify/mock"
@@ -46,3 +47,59 @@ func (m *MockTokenRepository) DeleteUserRefreshTokens(ctx context.Context, userI
 
 	return r0
 }
+
+func (m *MockTokenRepository) ListRefreshTokens(ctx context.Context, userID string) ([]*models.Session, error) {
+	args := m.Called(ctx, userID)
+
+	var r0 []*models.Session
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.Session)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockTokenRepository) SetMagicLink(ctx context.Context, linkID string, expiresIn time.Duration) error {
+	args := m.Called(ctx, linkID, expiresIn)
+
+	var r0 error
+
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockTokenRepository) DeleteMagicLink(ctx context.Context, linkID string) error {
+	args := m.Called(ctx, linkID)
+
+	var r0 error
+
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockTokenRepository) IncrementAttempts(ctx context.Context, key string, window time.Duration) (int64, error) {
+	args := m.Called(ctx, key, window)
+
+	var r0 int64
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(int64)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
@@ -33,6 +33,45 @@ func (m *MockTokenService) NewPairFromUser(
 	return r0, r1
 }
 
+func (m *MockTokenService) NewPairForClient(
+	ctx context.Context,
+	user *models.User,
+	grant *models.ClientGrant,
+	prevTokenID string,
+) (*models.TokenPair, error) {
+
+	args := m.Called(ctx, user, grant, prevTokenID)
+
+	var r0 *models.TokenPair
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.TokenPair)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockTokenService) AccessTokenGrant(tokenString string) (*models.ClientGrant, error) {
+	args := m.Called(tokenString)
+
+	var r0 *models.ClientGrant
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.ClientGrant)
+	}
+
+	var r1 error
+
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
 func (m *MockTokenService) ValidateAccessToken(tokenString string) (*models.User, error) {
 	args := m.Called(tokenString)
 
@@ -77,3 +116,84 @@ func (m *MockTokenService) Signout(ctx context.Context, userID uuid.UUID) error
 
 	return r0
 }
+
+func (m *MockTokenService) ListSessions(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	args := m.Called(ctx, userID)
+
+	var r0 []*models.Session
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.Session)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockTokenService) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	args := m.Called(ctx, userID, sessionID)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockTokenService) NewIDToken(user *models.User, options *models.IDTokenOptions) (string, error) {
+	args := m.Called(user, options)
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return args.String(0), r1
+}
+
+func (m *MockTokenService) JWKS() *models.JSONWebKeySet {
+	args := m.Called()
+
+	var r0 *models.JSONWebKeySet
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.JSONWebKeySet)
+	}
+
+	return r0
+}
+
+func (m *MockTokenService) NewServiceToken(principal *models.ServicePrincipal, issuer string) (*models.AccessToken, error) {
+	args := m.Called(principal, issuer)
+
+	var r0 *models.AccessToken
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.AccessToken)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockTokenService) ValidateServiceToken(token string, audience string) (*models.ServicePrincipal, error) {
+	args := m.Called(token, audience)
+
+	var r0 *models.ServicePrincipal
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.ServicePrincipal)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,67 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	args := m.Called(ctx, identity)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockUserIdentityRepository) FindBySubject(ctx context.Context, provider string, subject string) (*models.UserIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+
+	var r0 *models.UserIdentity
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.UserIdentity)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockUserIdentityRepository) FindByUserID(ctx context.Context, uid uuid.UUID) ([]*models.UserIdentity, error) {
+	args := m.Called(ctx, uid)
+
+	var r0 []*models.UserIdentity
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.UserIdentity)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockUserIdentityRepository) Delete(ctx context.Context, provider string, uid uuid.UUID) error {
+	args := m.Called(ctx, provider, uid)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
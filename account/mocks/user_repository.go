@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"memorize/models"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -28,6 +29,22 @@ func (m *MockUserRepository) FindByID(ctx context.Context, uid uuid.UUID) (*mode
 	return r0, r1
 }
 
+func (m *MockUserRepository) FindByIDs(ctx context.Context, uids []uuid.UUID) ([]*models.User, error) {
+	args := m.Called(ctx, uids)
+
+	var r0 []*models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
 func (m *MockUserRepository) FindByLogin(ctx context.Context, login string) (*models.User, error) {
 	args := m.Called(ctx, login)
 
@@ -65,3 +82,100 @@ func (m *MockUserRepository) Update(ctx context.Context, u *models.User) error {
 
 	return r0
 }
+
+func (m *MockUserRepository) UpdateFields(ctx context.Context, patch *models.UserPatch) (*models.User, error) {
+	args := m.Called(ctx, patch)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, uid uuid.UUID, password string) error {
+	args := m.Called(ctx, uid, password)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockUserRepository) UpdateDisabled(ctx context.Context, uid uuid.UUID, disabled bool) error {
+	args := m.Called(ctx, uid, disabled)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockUserRepository) UpdateSecondFactor(ctx context.Context, uid uuid.UUID, enabled bool) error {
+	args := m.Called(ctx, uid, enabled)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockUserRepository) FindByPreviousLogin(ctx context.Context, login string) (*models.User, error) {
+	args := m.Called(ctx, login)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockUserRepository) LoginAvailable(ctx context.Context, uid uuid.UUID, login string) (bool, error) {
+	args := m.Called(ctx, uid, login)
+
+	var r0 bool
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(bool)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockUserRepository) UpdateLogin(ctx context.Context, uid uuid.UUID, login string, reservedUntil time.Time, limit models.LoginChangeLimit) (*models.User, error) {
+	args := m.Called(ctx, uid, login, reservedUntil, limit)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
@@ -29,6 +29,22 @@ func (m *MockUserService) GetUser(ctx context.Context, uid uuid.UUID) (*models.U
 	return r0, r1
 }
 
+func (m *MockUserService) GetUsers(ctx context.Context, uids []uuid.UUID) ([]*models.User, error) {
+	args := m.Called(ctx, uids)
+
+	var r0 []*models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
 func (m *MockUserService) Signup(ctx context.Context, user *models.User) error {
 	args := m.Called(ctx, user)
 
@@ -66,3 +82,89 @@ func (m *MockUserService) UpdateDetails(ctx context.Context, u *models.User) err
 
 	return r0
 }
+
+func (m *MockUserService) PatchDetails(ctx context.Context, patch *models.UserPatch) (*models.User, error) {
+	args := m.Called(ctx, patch)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockUserService) UpdatePassword(ctx context.Context, uid uuid.UUID, password string) error {
+	args := m.Called(ctx, uid, password)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockUserService) SetDisabled(ctx context.Context, uid uuid.UUID, disabled bool) error {
+	args := m.Called(ctx, uid, disabled)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockUserService) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	args := m.Called(ctx, login)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockUserService) LoginAvailable(ctx context.Context, uid uuid.UUID, login string) (bool, error) {
+	args := m.Called(ctx, uid, login)
+
+	var r0 bool
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(bool)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockUserService) ChangeLogin(ctx context.Context, uid uuid.UUID, login string) (*models.User, error) {
+	args := m.Called(ctx, uid, login)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
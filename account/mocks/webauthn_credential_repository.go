@@ -0,0 +1,79 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebAuthnCredentialRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebAuthnCredentialRepository) Create(ctx context.Context, credential *models.WebAuthnCredential) error {
+	args := m.Called(ctx, credential)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockWebAuthnCredentialRepository) FindByID(ctx context.Context, credentialID string) (*models.WebAuthnCredential, error) {
+	args := m.Called(ctx, credentialID)
+
+	var r0 *models.WebAuthnCredential
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.WebAuthnCredential)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockWebAuthnCredentialRepository) FindByUserID(ctx context.Context, uid uuid.UUID) ([]*models.WebAuthnCredential, error) {
+	args := m.Called(ctx, uid)
+
+	var r0 []*models.WebAuthnCredential
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.WebAuthnCredential)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockWebAuthnCredentialRepository) UpdateSignCount(ctx context.Context, credentialID string, signCount uint32, lastUsedAt time.Time) error {
+	args := m.Called(ctx, credentialID, signCount, lastUsedAt)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockWebAuthnCredentialRepository) Delete(ctx context.Context, uid uuid.UUID, credentialID string) error {
+	args := m.Called(ctx, uid, credentialID)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
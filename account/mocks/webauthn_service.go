@@ -0,0 +1,132 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+	"memorize/webauthn"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebAuthnService struct {
+	mock.Mock
+}
+
+func (m *MockWebAuthnService) BeginRegistration(ctx context.Context, uid uuid.UUID) (*webauthn.CreationOptions, error) {
+	args := m.Called(ctx, uid)
+
+	var r0 *webauthn.CreationOptions
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*webauthn.CreationOptions)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockWebAuthnService) FinishRegistration(ctx context.Context, uid uuid.UUID, name string, response *webauthn.AttestationResponse) (*models.WebAuthnCredential, error) {
+	args := m.Called(ctx, uid, name, response)
+
+	var r0 *models.WebAuthnCredential
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.WebAuthnCredential)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockWebAuthnService) BeginSignin(ctx context.Context, login string) (*webauthn.RequestOptions, error) {
+	args := m.Called(ctx, login)
+
+	var r0 *webauthn.RequestOptions
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*webauthn.RequestOptions)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockWebAuthnService) BeginSecondFactor(ctx context.Context, uid uuid.UUID) (*webauthn.RequestOptions, error) {
+	args := m.Called(ctx, uid)
+
+	var r0 *webauthn.RequestOptions
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*webauthn.RequestOptions)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockWebAuthnService) FinishSignin(ctx context.Context, response *webauthn.AssertionResponse) (*models.User, error) {
+	args := m.Called(ctx, response)
+
+	var r0 *models.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.User)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockWebAuthnService) ListCredentials(ctx context.Context, uid uuid.UUID) ([]*models.WebAuthnCredential, error) {
+	args := m.Called(ctx, uid)
+
+	var r0 []*models.WebAuthnCredential
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]*models.WebAuthnCredential)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
+
+func (m *MockWebAuthnService) DeleteCredential(ctx context.Context, uid uuid.UUID, credentialID string) error {
+	args := m.Called(ctx, uid, credentialID)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockWebAuthnService) SetSecondFactor(ctx context.Context, uid uuid.UUID, enabled bool) error {
+	args := m.Called(ctx, uid, enabled)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"memorize/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebAuthnSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebAuthnSessionRepository) Save(ctx context.Context, session *models.WebAuthnSession, expiresIn time.Duration) error {
+	args := m.Called(ctx, session, expiresIn)
+
+	var r0 error
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(error)
+	}
+
+	return r0
+}
+
+func (m *MockWebAuthnSessionRepository) Consume(ctx context.Context, challenge []byte) (*models.WebAuthnSession, error) {
+	args := m.Called(ctx, challenge)
+
+	var r0 *models.WebAuthnSession
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*models.WebAuthnSession)
+	}
+
+	var r1 error
+	if args.Get(1) != nil {
+		r1 = args.Get(1).(error)
+	}
+
+	return r0, r1
+}
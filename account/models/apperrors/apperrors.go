@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+
+	"github.com/google/uuid"
 )
 
 type Type string
@@ -12,22 +14,63 @@ const (
 	Authorization        Type = "AUTHORIZATION"
 	BadRequest           Type = "BAD_REQUEST"
 	Conflict             Type = "CONFLICT"
+	Forbidden            Type = "FORBIDDEN"
 	Internal             Type = "INTERNAL"
 	NotFound             Type = "NOT_FOUND"
 	PayloadTooLarge      Type = "PAYLOAD_TOO_LARGE"
+	PreconditionFailed   Type = "PRECONDITION_FAILED"
+	PreconditionRequired Type = "PRECONDITION_REQUIRED"
 	ServiceUnavailable   Type = "SERVICE_UNAVAILABLE"
+	TooManyRequests      Type = "TOO_MANY_REQUESTS"
 	UnSupportedMediaType Type = "UN_SUPPORTED_MEDIATYPE"
 )
 
+// Code is stable machine readable reason of an error, clients match on it instead of message
+// errors without specific reason have code of their type
+type Code string
+
+const (
+	AccountDisabled      Code = "ACCOUNT_DISABLED"
+	CSRFTokenInvalid     Code = "CSRF_TOKEN_INVALID"
+	InsufficientScope    Code = "INSUFFICIENT_SCOPE"
+	InvalidCredentials   Code = "INVALID_CREDENTIALS"
+	InvalidLogin         Code = "INVALID_LOGIN"
+	InvalidRefreshToken  Code = "INVALID_REFRESH_TOKEN"
+	InvalidToken         Code = "INVALID_TOKEN"
+	LastSigninMethod     Code = "LAST_SIGNIN_METHOD"
+	LoginTaken           Code = "LOGIN_TAKEN"
+	MalformedJSON        Code = "MALFORMED_JSON"
+	MissingToken         Code = "MISSING_TOKEN"
+	SecondFactorRequired Code = "SECOND_FACTOR_REQUIRED"
+	SigninLinkInvalid    Code = "SIGNIN_LINK_INVALID"
+	TokenReused          Code = "TOKEN_REUSED"
+	ValidationFailed     Code = "VALIDATION_FAILED"
+)
+
 type Error struct {
 	Type    Type   `json:"type"`
+	Code    Code   `json:"code"`
 	Message string `json:"message"`
+	// values the message is about, like name of conflicting resource
+	Details map[string]interface{} `json:"details,omitempty"`
+	// identifies internal error in logs, so it can be reported by client
+	IncidentID string `json:"incidentId,omitempty"`
+
+	// underlying error, it is logged but never sent to clients
+	cause error
 }
 
 func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%v: %v", e.Message, e.cause)
+	}
 	return e.Message
 }
 
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
 func (e *Error) Status() int {
 	switch e.Type {
 	case Authorization:
@@ -36,14 +79,22 @@ func (e *Error) Status() int {
 		return http.StatusBadRequest
 	case Conflict:
 		return http.StatusConflict
+	case Forbidden:
+		return http.StatusForbidden
 	case Internal:
 		return http.StatusInternalServerError
 	case NotFound:
 		return http.StatusNotFound
 	case PayloadTooLarge:
 		return http.StatusRequestEntityTooLarge
+	case PreconditionFailed:
+		return http.StatusPreconditionFailed
+	case PreconditionRequired:
+		return http.StatusPreconditionRequired
 	case ServiceUnavailable:
 		return http.StatusServiceUnavailable
+	case TooManyRequests:
+		return http.StatusTooManyRequests
 	case UnSupportedMediaType:
 		return http.StatusUnsupportedMediaType
 	default:
@@ -51,6 +102,28 @@ func (e *Error) Status() int {
 	}
 }
 
+// Wrap sets underlying error that caused e
+func (e *Error) Wrap(cause error) *Error {
+	e.cause = cause
+	return e
+}
+
+// WithCode replaces generic code of error with specific one
+func (e *Error) WithCode(code Code) *Error {
+	e.Code = code
+	return e
+}
+
+// WithDetail adds value to details of error
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = map[string]interface{}{}
+	}
+
+	e.Details[key] = value
+	return e
+}
+
 func Status(err error) int {
 	var e *Error
 	if errors.As(err, &e) {
@@ -59,6 +132,15 @@ func Status(err error) int {
 	return http.StatusInternalServerError
 }
 
+// From returns application error in err chain, other errors are wrapped by internal error
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return NewInternal().Wrap(err)
+}
+
 /*
 * Error "Factories"
  */
@@ -66,6 +148,7 @@ func Status(err error) int {
 func NewAuthorization(reason string) *Error {
 	return &Error{
 		Type:    Authorization,
+		Code:    Code(Authorization),
 		Message: reason,
 	}
 }
@@ -73,6 +156,7 @@ func NewAuthorization(reason string) *Error {
 func NewBadRequest(reason string) *Error {
 	return &Error{
 		Type:    BadRequest,
+		Code:    Code(BadRequest),
 		Message: fmt.Sprintf("Bad request. Reason: %v", reason),
 	}
 }
@@ -80,41 +164,87 @@ func NewBadRequest(reason string) *Error {
 func NewConflict(name string, value string) *Error {
 	return &Error{
 		Type:    Conflict,
+		Code:    Code(Conflict),
 		Message: fmt.Sprintf("resource: %v with value: %v already exists", name, value),
+		Details: map[string]interface{}{"resource": name, "value": value},
+	}
+}
+
+func NewForbidden(reason string) *Error {
+	return &Error{
+		Type:    Forbidden,
+		Code:    Code(Forbidden),
+		Message: reason,
 	}
 }
 
+// NewInternal creates error with new incident id, cause should be set with Wrap
 func NewInternal() *Error {
 	return &Error{
-		Type:    Internal,
-		Message: "Internal server error.",
+		Type:       Internal,
+		Code:       Code(Internal),
+		Message:    "Internal server error.",
+		IncidentID: uuid.New().String(),
 	}
 }
 
 func NewNotFound(name string, value string) *Error {
 	return &Error{
 		Type:    NotFound,
+		Code:    Code(NotFound),
 		Message: fmt.Sprintf("resource: %v with value: %v not found", name, value),
+		Details: map[string]interface{}{"resource": name, "value": value},
 	}
 }
 
 func NewPayloadTooLarge(maxBodySize int64, contentLength int64) *Error {
 	return &Error{
 		Type:    PayloadTooLarge,
+		Code:    Code(PayloadTooLarge),
 		Message: fmt.Sprintf("Max payload size of %v exceeded. Actual payload size: %v", maxBodySize, contentLength),
+		Details: map[string]interface{}{"maxBodySize": maxBodySize, "contentLength": contentLength},
+	}
+}
+
+// NewPreconditionFailed is returned when resource changed since client has fetched it
+func NewPreconditionFailed(name string, value string) *Error {
+	return &Error{
+		Type:    PreconditionFailed,
+		Code:    Code(PreconditionFailed),
+		Message: fmt.Sprintf("resource: %v with value: %v has been changed, fetch it again", name, value),
+		Details: map[string]interface{}{"resource": name, "value": value},
+	}
+}
+
+func NewPreconditionRequired(header string) *Error {
+	return &Error{
+		Type:    PreconditionRequired,
+		Code:    Code(PreconditionRequired),
+		Message: fmt.Sprintf("Request must have %v header", header),
+		Details: map[string]interface{}{"header": header},
 	}
 }
 
 func NewServiceUnavailable() *Error {
 	return &Error{
 		Type:    ServiceUnavailable,
+		Code:    Code(ServiceUnavailable),
 		Message: "Service unavailable or timed out",
 	}
 }
 
+func NewTooManyRequests() *Error {
+	return &Error{
+		Type:    TooManyRequests,
+		Code:    Code(TooManyRequests),
+		Message: "Too many requests, try again later",
+	}
+}
+
 func NewUnsupportedMediaType(reason string) *Error {
 	return &Error{
 		Type:    UnSupportedMediaType,
+		Code:    Code(UnSupportedMediaType),
 		Message: reason,
 	}
 }
@@ -0,0 +1,56 @@
+package apperrors
+
+import "net/http"
+
+// error codes defined by RFC 6749, RFC 7009 and RFC 8707
+const (
+	InvalidRequest          = "invalid_request"
+	InvalidClient           = "invalid_client"
+	InvalidGrant            = "invalid_grant"
+	InvalidScope            = "invalid_scope"
+	UnauthorizedClient      = "unauthorized_client"
+	UnsupportedGrantType    = "unsupported_grant_type"
+	UnsupportedResponseType = "unsupported_response_type"
+	AccessDenied            = "access_denied"
+	ServerError             = "server_error"
+	InvalidTarget           = "invalid_target"
+)
+
+// OAuthError is returned by OAuth 2.0 endpoints in the format clients expect
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	// set when the error may be sent back to the client's redirect uri
+	RedirectURI string `json:"-"`
+}
+
+func (e *OAuthError) Error() string {
+	return e.Description
+}
+
+func (e *OAuthError) Status() int {
+	switch e.Code {
+	case InvalidClient:
+		return http.StatusUnauthorized
+	case ServerError:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func NewOAuthError(code string, description string) *OAuthError {
+	return &OAuthError{
+		Code:        code,
+		Description: description,
+	}
+}
+
+// error that can be delivered to the client by redirecting the user agent
+func NewOAuthRedirectError(code string, description string, redirectURI string) *OAuthError {
+	return &OAuthError{
+		Code:        code,
+		Description: description,
+		RedirectURI: redirectURI,
+	}
+}
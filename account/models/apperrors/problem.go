@@ -0,0 +1,82 @@
+package apperrors
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is media type of RFC 7807 error responses
+const ProblemContentType = "application/problem+json"
+
+// problem types are URNs derived from codes, they do not resolve to documentation
+const problemTypePrefix = "urn:memorize:problem:"
+
+// Problem is RFC 7807 representation of Error, code and details are extension members
+type Problem struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail,omitempty"`
+	Instance string                 `json:"instance,omitempty"`
+	Code     Code                   `json:"code"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	// set for internal errors
+	IncidentID string `json:"incidentId,omitempty"`
+}
+
+// Problem describes error that occurred for request to instance uri
+func (e *Error) Problem(instance string) *Problem {
+	status := e.Status()
+
+	return &Problem{
+		Type:       problemTypePrefix + strings.ToLower(strings.ReplaceAll(string(e.Code), "_", "-")),
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     e.Message,
+		Instance:   instance,
+		Code:       e.Code,
+		Details:    e.Details,
+		IncidentID: e.IncidentID,
+	}
+}
+
+// AppError converts problem received from API back to application error
+func (p *Problem) AppError() *Error {
+	return &Error{
+		Type:       StatusType(p.Status),
+		Code:       p.Code,
+		Message:    p.Detail,
+		Details:    p.Details,
+		IncidentID: p.IncidentID,
+	}
+}
+
+// StatusType is type of error responded with status, inverse of Error.Status
+func StatusType(status int) Type {
+	switch status {
+	case http.StatusBadRequest:
+		return BadRequest
+	case http.StatusUnauthorized:
+		return Authorization
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusPreconditionFailed:
+		return PreconditionFailed
+	case http.StatusPreconditionRequired:
+		return PreconditionRequired
+	case http.StatusRequestEntityTooLarge:
+		return PayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return UnSupportedMediaType
+	case http.StatusTooManyRequests:
+		return TooManyRequests
+	case http.StatusServiceUnavailable:
+		return ServiceUnavailable
+	default:
+		return Internal
+	}
+}
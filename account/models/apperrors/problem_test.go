@@ -0,0 +1,28 @@
+package apperrors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemRoundTrip(test *testing.T) {
+	errs := []*Error{
+		NewAuthorization("Provided token is invalid").WithCode(InvalidToken),
+		NewBadRequest("Bad request"),
+		NewConflict("login", "alice"),
+		NewNotFound("uid", "1"),
+		NewPreconditionFailed("uid", "1"),
+		NewPreconditionRequired("If-Match"),
+		NewTooManyRequests(),
+		NewInternal(),
+	}
+
+	for _, err := range errs {
+		appError := err.Problem("/details").AppError()
+
+		assert.Equal(test, err.Type, appError.Type)
+		assert.Equal(test, err.Code, appError.Code)
+		assert.Equal(test, err.Status(), appError.Status())
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links an account of an external identity provider to a user
+type UserIdentity struct {
+	Provider  string    `db:"provider" json:"provider"`
+	Subject   string    `db:"subject" json:"-"`
+	UserID    uuid.UUID `db:"uid" json:"-"`
+	Email     string    `db:"email" json:"email"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// ExternalProfile is what an external identity provider tells about its user
+type ExternalProfile struct {
+	Subject string
+	Login   string
+	Name    string
+	Email   string
+	Picture string
+}
+
+// SocialLoginState is kept between redirect to provider and callback
+type SocialLoginState struct {
+	State        string    `json:"-"`
+	Provider     string    `json:"provider"`
+	CodeVerifier string    `json:"codeVerifier"`
+	LinkUserID   uuid.UUID `json:"linkUserId"`
+}
+
+// IdentityProvider is an external OAuth 2.0 or OpenID Connect provider
+type IdentityProvider interface {
+	// url to send user agent to, redirect uri is part of provider configuration
+	AuthCodeURL(ctx context.Context, state string, codeChallenge string) (string, error)
+	// exchange authorization code for the profile of the user
+	Exchange(ctx context.Context, code string, codeVerifier string) (*ExternalProfile, error)
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"memorize/webauthn"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,36 +12,160 @@ import (
 type UserService interface {
 	// fetch user by uid
 	GetUser(ctx context.Context, userID uuid.UUID) (*User, error)
+	// fetch users by uids, unknown uids are skipped
+	GetUsers(ctx context.Context, userIDs []uuid.UUID) ([]*User, error)
 	// signup user if login avaliable
 	Signup(ctx context.Context, user *User) error
 	// signin user if credentials are right
 	Signin(ctx context.Context, user *User) (*User, error)
 	// update user details
 	UpdateDetails(ctx context.Context, user *User) error
+	// change details in mask of patch, returns updated user
+	PatchDetails(ctx context.Context, patch *UserPatch) (*User, error)
+	// replace user password
+	UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error
+	// disable or enable user account
+	SetDisabled(ctx context.Context, userID uuid.UUID, disabled bool) error
+	// fetch user by login, or by previous login while it is reserved
+	GetUserByLogin(ctx context.Context, login string) (*User, error)
+	// tell if user can change login to login
+	LoginAvailable(ctx context.Context, userID uuid.UUID, login string) (bool, error)
+	// change login of user, previous login stays reserved for user
+	ChangeLogin(ctx context.Context, userID uuid.UUID, login string) (*User, error)
 }
 
 // TokenService defines methods the handler layer expects
 type TokenService interface {
 	// create new pair of tokens
 	NewPairFromUser(ctx context.Context, user *User, previousToken string) (*TokenPair, error)
+	// create new pair of tokens for OAuth client, tokens carry only claims its scope grants
+	NewPairForClient(ctx context.Context, user *User, grant *ClientGrant, previousToken string) (*TokenPair, error)
 	// validate token
 	ValidateAccessToken(token string) (*User, error)
+	// client and scope of valid access token, nil for tokens of the account service itself
+	AccessTokenGrant(token string) (*ClientGrant, error)
 	// validate refresh token
 	ValidateRefreshToken(token string) (*RefreshToken, error)
 	// delete all users tokens
 	Signout(ctx context.Context, userID uuid.UUID) error
+	// list users refresh tokens
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]*Session, error)
+	// delete single refresh token
+	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID string) error
+	// create OpenID Connect id token
+	NewIDToken(user *User, options *IDTokenOptions) (string, error)
+	// public keys that verify access and id tokens
+	JWKS() *JSONWebKeySet
+	// create access token for service client
+	NewServiceToken(principal *ServicePrincipal, issuer string) (*AccessToken, error)
+	// validate service access token issued for audience
+	ValidateServiceToken(token string, audience string) (*ServicePrincipal, error)
+}
+
+// OAuthService defines methods the handler layer expects for OpenID Connect provider
+type OAuthService interface {
+	// register client, returns generated secret for confidential clients
+	RegisterClient(ctx context.Context, client *OAuthClient, confidential bool) (string, error)
+	// list registered clients
+	ListClients(ctx context.Context) ([]*OAuthClient, error)
+	// delete registered client
+	DeleteClient(ctx context.Context, clientID string) error
+	// check client, redirect uri and other authorization request parameters
+	ValidateAuthorizationRequest(ctx context.Context, request *AuthorizationRequest) error
+	// issue authorization code to signed in user, returns uri to redirect user agent to
+	Authorize(ctx context.Context, request *AuthorizationRequest, user *User) (string, error)
+	// exchange authorization code or refresh token for tokens
+	Token(ctx context.Context, request *OAuthTokenRequest) (*OAuthTokenResponse, error)
+	// describe access or refresh token to authenticated client
+	Introspect(ctx context.Context, request *TokenIntrospectionRequest) (*TokenIntrospectionResponse, error)
+	// revoke refresh token for authenticated client
+	Revoke(ctx context.Context, request *TokenRevocationRequest) error
+}
+
+// SocialService defines methods the handler layer expects for external identity providers
+type SocialService interface {
+	// names of configured providers
+	Providers() []string
+	// begin login, or linking if linkUserID is not nil, returns provider authorization url
+	Start(ctx context.Context, provider string, linkUserID uuid.UUID) (string, error)
+	// finish login or linking, returns signed in user
+	Callback(ctx context.Context, provider string, code string, state string) (*User, error)
+	// one-time code browser is redirected with after callback, front-end exchanges it for tokens
+	SigninCode(ctx context.Context, userID uuid.UUID) (string, error)
+	// exchange signin code for its user, code can be used only once
+	Signin(ctx context.Context, code string) (*User, error)
+	// remove link between user and provider
+	Unlink(ctx context.Context, provider string, userID uuid.UUID) error
+	// providers linked to user
+	ListIdentities(ctx context.Context, userID uuid.UUID) ([]*UserIdentity, error)
+}
+
+// PersonalAccessTokenService defines methods the handler layer expects for long-lived tokens
+type PersonalAccessTokenService interface {
+	// create token, plain token is returned only here
+	Create(ctx context.Context, userID uuid.UUID, token *PersonalAccessToken, expiresIn time.Duration) error
+	// list tokens of user
+	List(ctx context.Context, userID uuid.UUID) ([]*PersonalAccessToken, error)
+	// delete token of user
+	Revoke(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID) error
+	// validate plain token, returns its user and scopes
+	Validate(ctx context.Context, token string) (*User, []string, error)
+}
+
+// WebAuthnService defines methods the handler layer expects for passkeys
+type WebAuthnService interface {
+	// start passkey registration for user
+	BeginRegistration(ctx context.Context, userID uuid.UUID) (*webauthn.CreationOptions, error)
+	// verify authenticator response and store credential
+	FinishRegistration(ctx context.Context, userID uuid.UUID, name string, response *webauthn.AttestationResponse) (*WebAuthnCredential, error)
+	// start passwordless signin, login is optional for discoverable credentials
+	BeginSignin(ctx context.Context, login string) (*webauthn.RequestOptions, error)
+	// start passkey confirmation of password signin
+	BeginSecondFactor(ctx context.Context, userID uuid.UUID) (*webauthn.RequestOptions, error)
+	// verify authenticator response of signin or second factor, returns signed in user
+	FinishSignin(ctx context.Context, response *webauthn.AssertionResponse) (*User, error)
+	// list passkeys of user
+	ListCredentials(ctx context.Context, userID uuid.UUID) ([]*WebAuthnCredential, error)
+	// delete passkey of user
+	DeleteCredential(ctx context.Context, userID uuid.UUID, credentialID string) error
+	// require passkey after password signin
+	SetSecondFactor(ctx context.Context, userID uuid.UUID, enabled bool) error
+}
+
+// MagicLinkService defines methods the handler layer expects for passwordless signin by email
+type MagicLinkService interface {
+	// mail signin link to user, returns nonce that binds link to requesting device
+	Send(ctx context.Context, login string, clientIP string) (string, error)
+	// check link token and nonce of requesting device, returns signed in user
+	Verify(ctx context.Context, token string, nonce string) (*User, error)
 }
 
 // UserRepository defines methods the service layer expects
 type UserRepository interface {
 	// fetch user by id from database
 	FindByID(ctx context.Context, userID uuid.UUID) (*User, error)
+	// fetch users by ids from database
+	FindByIDs(ctx context.Context, userIDs []uuid.UUID) ([]*User, error)
 	// fetch user by login from database
 	FindByLogin(ctx context.Context, login string) (*User, error)
 	// create user record in database
 	Create(ctx context.Context, user *User) error
 	// update user record in database
 	Update(ctx context.Context, user *User) error
+	// update only columns in mask of patch, returns updated user
+	UpdateFields(ctx context.Context, patch *UserPatch) (*User, error)
+	// update user password hash in database
+	UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error
+	// update user disabled flag in database
+	UpdateDisabled(ctx context.Context, userID uuid.UUID, disabled bool) error
+	// update user second factor flag in database
+	UpdateSecondFactor(ctx context.Context, userID uuid.UUID, enabled bool) error
+	// fetch user whose previous login is still reserved
+	FindByPreviousLogin(ctx context.Context, login string) (*User, error)
+	// tell if login is neither used nor reserved by other users
+	LoginAvailable(ctx context.Context, userID uuid.UUID, login string) (bool, error)
+	// change login of user and reserve previous one until reservedUntil, unless user reached limit of changes
+	UpdateLogin(ctx context.Context, userID uuid.UUID, login string, reservedUntil time.Time, limit LoginChangeLimit) (*User, error)
 }
 
 // TokenRepository defines methods it expects a repository
@@ -51,4 +176,92 @@ type TokenRepository interface {
 	DeleteRefreshToken(ctx context.Context, userID string, previousTokenID string) error
 	// delete allUsers refresh tokens
 	DeleteUserRefreshTokens(ctx context.Context, userID string) error
+	// list refresh tokens of a user
+	ListRefreshTokens(ctx context.Context, userID string) ([]*Session, error)
+	// stores id of a sent signin link with an expiry time
+	SetMagicLink(ctx context.Context, linkID string, expiresIn time.Duration) error
+	// delete signin link, so it can be used only once
+	DeleteMagicLink(ctx context.Context, linkID string) error
+	// count attempts under key within window, returns count including this attempt
+	IncrementAttempts(ctx context.Context, key string, window time.Duration) (int64, error)
+}
+
+// OAuthClientRepository defines methods the service layer expects for registered clients
+type OAuthClientRepository interface {
+	// create client record in database
+	Create(ctx context.Context, client *OAuthClient) error
+	// fetch client by id from database
+	FindByID(ctx context.Context, clientID string) (*OAuthClient, error)
+	// fetch all clients from database
+	FindAll(ctx context.Context) ([]*OAuthClient, error)
+	// delete client record from database
+	Delete(ctx context.Context, clientID string) error
+}
+
+// PersonalAccessTokenRepository defines methods the service layer expects for long-lived tokens
+type PersonalAccessTokenRepository interface {
+	// create token record in database
+	Create(ctx context.Context, token *PersonalAccessToken) error
+	// fetch token by hash of plain token
+	FindByHash(ctx context.Context, tokenHash string) (*PersonalAccessToken, error)
+	// fetch tokens of user
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*PersonalAccessToken, error)
+	// delete token of user
+	Delete(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID) error
+	// set time token was last used
+	UpdateLastUsed(ctx context.Context, tokenID uuid.UUID, lastUsedAt time.Time) error
+}
+
+// WebAuthnCredentialRepository defines methods the service layer expects for passkeys
+type WebAuthnCredentialRepository interface {
+	// create credential record in database
+	Create(ctx context.Context, credential *WebAuthnCredential) error
+	// fetch credential by its id
+	FindByID(ctx context.Context, credentialID string) (*WebAuthnCredential, error)
+	// fetch credentials of user
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*WebAuthnCredential, error)
+	// store signature counter and time of use
+	UpdateSignCount(ctx context.Context, credentialID string, signCount uint32, lastUsedAt time.Time) error
+	// delete credential of user
+	Delete(ctx context.Context, userID uuid.UUID, credentialID string) error
+}
+
+// WebAuthnSessionRepository defines methods the service layer expects for pending ceremonies
+type WebAuthnSessionRepository interface {
+	// stores a session with an expiry time
+	Save(ctx context.Context, session *WebAuthnSession, expiresIn time.Duration) error
+	// fetch and delete session by its challenge, so it can be used only once
+	Consume(ctx context.Context, challenge []byte) (*WebAuthnSession, error)
+}
+
+// UserIdentityRepository defines methods the service layer expects for linked external accounts
+type UserIdentityRepository interface {
+	// create identity record in database
+	Create(ctx context.Context, identity *UserIdentity) error
+	// fetch identity by provider and its subject
+	FindBySubject(ctx context.Context, provider string, subject string) (*UserIdentity, error)
+	// fetch identities of user
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*UserIdentity, error)
+	// delete identity of user for provider
+	Delete(ctx context.Context, provider string, userID uuid.UUID) error
+}
+
+// SocialLoginStateRepository defines methods the service layer expects for pending social logins
+type SocialLoginStateRepository interface {
+	// stores a state with an expiry time
+	Save(ctx context.Context, state *SocialLoginState, expiresIn time.Duration) error
+	// fetch and delete state, so it can be used only once
+	Consume(ctx context.Context, state string) (*SocialLoginState, error)
+	// stores signin code of user with an expiry time
+	SaveSigninCode(ctx context.Context, code string, userID uuid.UUID, expiresIn time.Duration) error
+	// fetch and delete signin code, so it can be used only once
+	ConsumeSigninCode(ctx context.Context, code string) (uuid.UUID, error)
+}
+
+// AuthorizationCodeRepository defines methods the service layer expects for authorization codes
+type AuthorizationCodeRepository interface {
+	// stores a code with an expiry time
+	Save(ctx context.Context, code *AuthorizationCode, expiresIn time.Duration) error
+	// fetch and delete code, so it can be used only once
+	Consume(ctx context.Context, code string) (*AuthorizationCode, error)
 }
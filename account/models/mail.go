@@ -0,0 +1,16 @@
+package models
+
+import "context"
+
+// Mail is a plain text message to a user
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers mails to users
+type Mailer interface {
+	// send mail, returns when it is accepted by mail server
+	Send(ctx context.Context, mail *Mail) error
+}
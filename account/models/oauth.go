@@ -0,0 +1,167 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthClient is an application allowed to sign users in through this service
+// service clients sign in as themselves with client credentials instead
+type OAuthClient struct {
+	ClientID     string    `json:"clientId"`
+	SecretHash   string    `json:"-"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirectUris"`
+	Service      bool      `json:"service"`
+	Audiences    []string  `json:"audiences"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Public clients (SPA, mobile) have no secret and must use PKCE
+func (c *OAuthClient) Public() bool {
+	return c.SecretHash == ""
+}
+
+// AuthorizationRequest holds parameters of the authorization endpoint
+type AuthorizationRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type"`
+	ClientID            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	Nonce               string `form:"nonce" json:"nonce"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+}
+
+// AuthorizationCode is a single use code exchanged for tokens
+type AuthorizationCode struct {
+	Code                string    `json:"-"`
+	ClientID            string    `json:"clientId"`
+	UserID              uuid.UUID `json:"userId"`
+	RedirectURI         string    `json:"redirectUri"`
+	Scope               string    `json:"scope"`
+	Nonce               string    `json:"nonce"`
+	CodeChallenge       string    `json:"codeChallenge"`
+	CodeChallengeMethod string    `json:"codeChallengeMethod"`
+	AuthTime            int64     `json:"authTime"`
+}
+
+// OAuthTokenRequest holds parameters of the token endpoint
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	Audience     string `form:"audience"`
+}
+
+// OAuthTokenResponse is the token endpoint response defined by RFC 6749
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// TokenIntrospectionRequest holds parameters of the introspection endpoint defined by RFC 7662
+type TokenIntrospectionRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// TokenIntrospectionResponse describes a token, inactive tokens have only active set
+type TokenIntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	TokenType string `json:"token_type,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+// TokenRevocationRequest holds parameters of the revocation endpoint defined by RFC 7009
+type TokenRevocationRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// ServicePrincipal is a backend service authenticated with client credentials
+type ServicePrincipal struct {
+	ClientID string `json:"clientId"`
+	Audience string `json:"audience"`
+}
+
+// ClientGrant is OAuth client tokens were issued to and scope user granted it
+// tokens of the account service itself have no grant
+type ClientGrant struct {
+	ClientID string
+	Scope    string
+}
+
+// Scopes granted to client
+func (g *ClientGrant) Scopes() []string {
+	return strings.Fields(g.Scope)
+}
+
+// User is copy of user with only claims scope grants, sub and login are always granted
+// nil grant keeps every claim
+func (g *ClientGrant) User(user *User) *User {
+	if g == nil {
+		return user
+	}
+
+	scoped := *user
+	granted := map[string]bool{}
+	for _, scope := range g.Scopes() {
+		granted[scope] = true
+	}
+
+	if !granted["profile"] {
+		scoped.Name = ""
+		scoped.ImageURL = ""
+		scoped.Website = ""
+		scoped.Locale = ""
+	}
+
+	if !granted["email"] {
+		scoped.Email = ""
+	}
+
+	return &scoped
+}
+
+// IDTokenOptions holds OpenID Connect claims that depend on the client
+type IDTokenOptions struct {
+	Issuer   string
+	Audience string
+	Nonce    string
+	AuthTime int64
+}
+
+// JSONWebKey is a public RSA key as defined by RFC 7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JSONWebKeySet lists keys that verify tokens issued by this service
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
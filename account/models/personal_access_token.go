@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// prefix tells personal access tokens apart from JWTs
+const PersonalAccessTokenPrefix = "mpat_"
+
+// scopes personal access token can be limited to
+const (
+	ScopeProfileRead  = "profile:read"
+	ScopeProfileWrite = "profile:write"
+	ScopeSessions     = "sessions"
+)
+
+// all scopes personal access token can be created with
+var PersonalAccessTokenScopes = []string{ScopeProfileRead, ScopeProfileWrite, ScopeSessions}
+
+// PersonalAccessToken is a long-lived named token for scripts and integrations
+type PersonalAccessToken struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"-"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	TokenHash  string     `json:"-"`
+	Token      string     `json:"token,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
@@ -1,20 +1,34 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+)
 
 // RefreshToken store token properties
 type RefreshToken struct {
 	ID     uuid.UUID `json:"-"`
 	UserID uuid.UUID `json:"-"`
-	Token  string    `json:"refreshToken"`
+	Token  string    `json:"refreshToken,omitempty"`
+	// client the token was issued to, nil for tokens of the account service itself
+	Grant *ClientGrant `json:"-"`
+	// seconds until refresh token expires, set only on new tokens
+	ExpiresIn int64 `json:"-"`
 }
 
 // AccessToken store token properties
 type AccessToken struct {
-	Token string `json:"accessToken"`
+	Token     string `json:"accessToken"`
+	ExpiresIn int64  `json:"-"`
 }
 
 type TokenPair struct {
 	RefreshToken
 	AccessToken
 }
+
+// Session is a stored refresh token of a user
+type Session struct {
+	ID string `json:"id"`
+	// seconds until session expires, like ExpiresIn of tokens
+	ExpiresIn int64 `json:"expiresIn"`
+}
@@ -1,13 +1,77 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
-	UID      uuid.UUID `db:"uid" json:"uid"`
-	Login    string    `db:"login" json:"login"`
-	Email    string    `db:"email" json:"email"`
-	Password string    `db:"password" json:"-"`
-	Name     string    `db:"name" json:"name"`
-	ImageURL string    `db:"image_url" json:"imageUrl"`
-	Website  string    `db:"website" json:"website"`
+	UID          uuid.UUID `db:"uid" json:"uid"`
+	Login        string    `db:"login" json:"login"`
+	Email        string    `db:"email" json:"email"`
+	Password     string    `db:"password" json:"-"`
+	Name         string    `db:"name" json:"name"`
+	ImageURL     string    `db:"image_url" json:"imageUrl"`
+	Website      string    `db:"website" json:"website"`
+	Disabled     bool      `db:"disabled" json:"-"`
+	SecondFactor bool      `db:"second_factor" json:"secondFactor"`
+	Locale       string    `db:"locale" json:"locale"`
+	// forms login and email are compared by, Login and Email are display forms
+	NormalizedLogin string `db:"normalized_login" json:"-"`
+	NormalizedEmail string `db:"normalized_email" json:"-"`
+	// incremented by every update, it is the entity tag of user
+	Version   int64     `db:"version" json:"-"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// HasPassword is false for users created with external provider, they sign in with it or passkeys
+func (u *User) HasPassword() bool {
+	return u.Password != ""
+}
+
+// LoginChangeLimit is how many times user can change login since time
+type LoginChangeLimit struct {
+	Changes int
+	Since   time.Time
+}
+
+// columns of user details that can be changed by UserPatch
+const (
+	UserName    = "name"
+	UserEmail   = "email"
+	UserWebsite = "website"
+	UserLocale  = "locale"
+)
+
+// UserPatch changes some details of user, fields that are not in mask keep their value
+type UserPatch struct {
+	UID uuid.UUID
+	// columns to update, like UserName
+	Fields  []string
+	Name    string
+	Email   string
+	Website string
+	Locale  string
+	// update only if user still has this version, any version when zero
+	Version int64
+}
+
+// Values of fields in mask by column
+func (p *UserPatch) Values() map[string]string {
+	all := map[string]string{
+		UserName:    p.Name,
+		UserEmail:   p.Email,
+		UserWebsite: p.Website,
+		UserLocale:  p.Locale,
+	}
+
+	values := make(map[string]string, len(p.Fields))
+	for _, field := range p.Fields {
+		if value, ok := all[field]; ok {
+			values[field] = value
+		}
+	}
+
+	return values
 }
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ceremonies a WebAuthn challenge is issued for
+const (
+	CeremonyRegistration = "registration"
+	CeremonySignin       = "signin"
+	CeremonySecondFactor = "second_factor"
+)
+
+// WebAuthnCredential is a passkey or security key registered by user
+type WebAuthnCredential struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     uuid.UUID  `db:"uid" json:"-"`
+	Name       string     `db:"name" json:"name"`
+	PublicKey  []byte     `db:"public_key" json:"-"`
+	SignCount  uint32     `db:"sign_count" json:"-"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"lastUsedAt"`
+}
+
+// WebAuthnSession is a ceremony waiting for authenticator response
+type WebAuthnSession struct {
+	Challenge []byte    `json:"-"`
+	UserID    uuid.UUID `json:"userId"`
+	Ceremony  string    `json:"ceremony"`
+}
@@ -0,0 +1,119 @@
+// Package normalize maps logins and emails to keys they are compared by,
+// so spellings that look alike cannot belong to different users
+package normalize
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/secure/precis"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrInvalidLogin is returned for logins with characters PRECIS disallows in identifiers,
+// or with letters of scripts that are not used together
+var ErrInvalidLogin = errors.New("login has disallowed characters or mixes scripts")
+
+var (
+	// key is UsernameCaseMapped profile of RFC 8265 with NFKC and full case folding,
+	// so fullwidth, ligature and other compatibility variants of letters are one login
+	loginKey = precis.NewIdentifier(
+		precis.FoldWidth,
+		precis.FoldCase(),
+		precis.Norm(norm.NFKC),
+		precis.BidiRule,
+		precis.DisallowEmpty,
+	)
+
+	// display form keeps case of login as user typed it
+	loginDisplay = precis.UsernameCasePreserved
+
+	emailFold = cases.Fold()
+)
+
+// scripts that are commonly written together, any other letters of different scripts
+// make login invalid, like latin "a" among cyrillic ones
+var scriptCombinations = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// LoginKey is form of login that is unique among users
+func LoginKey(login string) (string, error) {
+	key, err := loginKey.String(login)
+	if err != nil || !singleScript(key) {
+		return "", ErrInvalidLogin
+	}
+
+	return key, nil
+}
+
+// Login is display form of login, it is stored and shown while LoginKey is compared
+func Login(login string) (string, error) {
+	if _, err := LoginKey(login); err != nil {
+		return "", err
+	}
+
+	display, err := loginDisplay.String(login)
+	if err != nil {
+		return "", ErrInvalidLogin
+	}
+
+	return display, nil
+}
+
+// EmailKey is form of email addresses are compared by, local parts are compared
+// without case too since mail providers treat them so
+func EmailKey(email string) string {
+	return emailFold.String(norm.NFKC.String(strings.TrimSpace(email)))
+}
+
+func singleScript(login string) bool {
+	scripts := map[string]bool{}
+
+	for _, char := range login {
+		if name := script(char); name != "" && name != "Common" && name != "Inherited" {
+			scripts[name] = true
+		}
+	}
+
+	if len(scripts) <= 1 {
+		return true
+	}
+
+	for _, combination := range scriptCombinations {
+		if contains(combination, scripts) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func script(char rune) string {
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, char) {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func contains(combination []string, scripts map[string]bool) bool {
+	allowed := map[string]bool{}
+	for _, name := range combination {
+		allowed[name] = true
+	}
+
+	for name := range scripts {
+		if !allowed[name] {
+			return false
+		}
+	}
+
+	return true
+}
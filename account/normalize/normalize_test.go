@@ -0,0 +1,62 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginKey(test *testing.T) {
+	test.Run("Variants of login have one key", func(test *testing.T) {
+		for _, login := range []string{"Alice", "ALICE", "ａｌｉｃｅ", "𝐚lice"} {
+			key, err := LoginKey(login)
+
+			assert.NoError(test, err, login)
+			assert.Equal(test, "alice", key, login)
+		}
+	})
+
+	test.Run("Case is folded", func(test *testing.T) {
+		key, err := LoginKey("Straße")
+
+		assert.NoError(test, err)
+		assert.Equal(test, "strasse", key)
+	})
+
+	test.Run("Scripts written together", func(test *testing.T) {
+		for _, login := range []string{"jürgen.o-k_1", "山田たろう", "tanaka山田", "σίσυφος"} {
+			_, err := LoginKey(login)
+
+			assert.NoError(test, err, login)
+		}
+	})
+
+	test.Run("Invalid logins", func(test *testing.T) {
+		// first letter of "аlice" is cyrillic, other one has zero width space
+		for _, login := range []string{"аlice", "alice smith", "alice\u200b", ""} {
+			_, err := LoginKey(login)
+
+			assert.ErrorIs(test, err, ErrInvalidLogin, login)
+		}
+	})
+}
+
+func TestLogin(test *testing.T) {
+	test.Run("Case is kept", func(test *testing.T) {
+		login, err := Login("Ｊürgen")
+
+		assert.NoError(test, err)
+		assert.Equal(test, "Jürgen", login)
+	})
+
+	test.Run("Compatibility characters are not displayed", func(test *testing.T) {
+		_, err := Login("𝐚lice")
+
+		assert.ErrorIs(test, err, ErrInvalidLogin)
+	})
+}
+
+func TestEmailKey(test *testing.T) {
+	assert.Equal(test, "alice@example.com", EmailKey(" Alice@Example.COM "))
+	assert.Equal(test, "alice@example.com", EmailKey("ａｌｉｃｅ@example.com"))
+}
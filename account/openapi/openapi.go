@@ -0,0 +1,215 @@
+// Package openapi builds OpenAPI 3 documents from Go request and response types
+// and validates JSON values against them.
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Version of the OpenAPI specification documents are written in
+const Version = "3.0.3"
+
+// gin path parameters like :uid are {uid} in OpenAPI paths
+var ginParamPattern = regexp.MustCompile(`[:*](\w+)`)
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+
+	// Go types of component schemas, to tell apart types of same name
+	types map[string]reflect.Type
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// PathItem holds operations of a path by lower case method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header of response, like ETag
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// New creates document of API served under baseURL
+func New(title string, version string, baseURL string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Servers: []Server{{URL: baseURL}},
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{},
+		},
+	}
+}
+
+// Path converts gin route path to OpenAPI path template
+func Path(ginPath string) string {
+	return ginParamPattern.ReplaceAllString(ginPath, "{$1}")
+}
+
+// Add registers operation for gin route, path parameters are added from the path
+func (d *Document) Add(method string, ginPath string, operation *Operation) {
+	path := Path(ginPath)
+
+	for _, match := range ginParamPattern.FindAllStringSubmatch(ginPath, -1) {
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	if operation.Responses == nil {
+		operation.Responses = map[string]*Response{}
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = PathItem{}
+		d.Paths[path] = item
+	}
+
+	item[strings.ToLower(method)] = operation
+}
+
+// Operation finds operation of gin route
+func (d *Document) Operation(method string, ginPath string) *Operation {
+	return d.Paths[Path(ginPath)][strings.ToLower(method)]
+}
+
+// JSONBody is request body of value type
+func (d *Document) JSONBody(value interface{}) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			"application/json": {Schema: d.Schema(value)},
+		},
+	}
+}
+
+// FormBody is url encoded request body of value type, fields are named by form tags
+func (d *Document) FormBody(value interface{}) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			"application/x-www-form-urlencoded": {Schema: d.formSchema(value)},
+		},
+	}
+}
+
+// QueryParameters describes fields of value type bound from query, they are named by form tags
+func (d *Document) QueryParameters(value interface{}) []*Parameter {
+	schema := d.formSchema(value)
+
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parameters := make([]*Parameter, 0, len(names))
+	for _, name := range names {
+		parameters = append(parameters, &Parameter{
+			Name:     name,
+			In:       "query",
+			Required: required[name],
+			Schema:   schema.Properties[name],
+		})
+	}
+
+	return parameters
+}
+
+// JSONResponse describes response with body of value type
+func (d *Document) JSONResponse(description string, value interface{}) *Response {
+	return &Response{
+		Description: description,
+		Content: map[string]*MediaType{
+			"application/json": {Schema: d.Schema(value)},
+		},
+	}
+}
+
+// EmptyResponse describes response without body
+func EmptyResponse(description string) *Response {
+	return &Response{Description: description}
+}
+
+// RedirectResponse describes response that sends user agent to Location
+func RedirectResponse(description string) *Response {
+	return &Response{
+		Description: description,
+		Headers: map[string]*Header{
+			"Location": {Schema: &Schema{Type: "string"}},
+		},
+	}
+}
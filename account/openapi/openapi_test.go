@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `json:"city" binding:"required"`
+}
+
+type person struct {
+	ID       uuid.UUID  `json:"id"`
+	Login    string     `json:"login" binding:"required,login"`
+	Password string     `json:"-"`
+	Email    string     `json:"email" binding:"omitempty,email"`
+	Locale   string     `json:"locale" binding:"omitempty,oneof=en de"`
+	Age      int        `json:"age" binding:"gte=0,lte=150"`
+	Tags     []string   `json:"tags" binding:"max=3,dive,max=10"`
+	Address  *address   `json:"address"`
+	Born     *time.Time `json:"born"`
+}
+
+type credentials struct {
+	ClientID string `form:"client_id" json:"clientId" binding:"required"`
+}
+
+func TestPath(test *testing.T) {
+	assert.Equal(test, "/users/{uid}", Path("/users/:uid"))
+	assert.Equal(test, "/oauth/{provider}/link", Path("/oauth/:provider/link"))
+	assert.Equal(test, "/me", Path("/me"))
+}
+
+func TestSchema(test *testing.T) {
+	doc := New("test", "1.0.0", "/api")
+
+	test.Run("Struct is component", func(test *testing.T) {
+		schema := doc.Schema(person{})
+
+		assert.Equal(test, "#/components/schemas/Person", schema.Ref)
+
+		component := doc.Components.Schemas["Person"]
+		assert.Equal(test, "object", component.Type)
+		assert.ElementsMatch(test, []string{"login"}, component.Required)
+		assert.NotContains(test, component.Properties, "Password")
+
+		assert.Equal(test, "uuid", component.Properties["id"].Format)
+		assert.Equal(test, `^[\p{L}\p{N}._-]+$`, component.Properties["login"].Pattern)
+		assert.Equal(test, "email", component.Properties["email"].Format)
+		assert.Equal(test, []string{"", "en", "de"}, component.Properties["locale"].Enum)
+		assert.Equal(test, 150.0, *component.Properties["age"].Maximum)
+		assert.Equal(test, 3, *component.Properties["tags"].MaxItems)
+		assert.Nil(test, component.Properties["tags"].Items.MaxLength)
+		assert.Equal(test, "#/components/schemas/Address", component.Properties["address"].Ref)
+		assert.Equal(test, "date-time", component.Properties["born"].Format)
+		assert.True(test, component.Properties["born"].Nullable)
+	})
+
+	test.Run("Form body is inline", func(test *testing.T) {
+		body := doc.FormBody(credentials{})
+		schema := body.Content["application/x-www-form-urlencoded"].Schema
+
+		assert.Empty(test, schema.Ref)
+		assert.Contains(test, schema.Properties, "client_id")
+		assert.Equal(test, []string{"client_id"}, schema.Required)
+	})
+
+	test.Run("Query parameters", func(test *testing.T) {
+		parameters := doc.QueryParameters(credentials{})
+
+		assert.Len(test, parameters, 1)
+		assert.Equal(test, "client_id", parameters[0].Name)
+		assert.Equal(test, "query", parameters[0].In)
+		assert.True(test, parameters[0].Required)
+		assert.Equal(test, "string", parameters[0].Schema.Type)
+	})
+
+	test.Run("Path parameters", func(test *testing.T) {
+		doc.Add("GET", "/users/:uid", &Operation{OperationID: "getUsersUid"})
+
+		operation := doc.Operation("GET", "/users/:uid")
+
+		assert.Equal(test, "getUsersUid", operation.OperationID)
+		assert.Equal(test, "uid", operation.Parameters[0].Name)
+		assert.Equal(test, "path", operation.Parameters[0].In)
+		assert.NotNil(test, doc.Paths["/users/{uid}"]["get"])
+	})
+}
+
+func TestValidate(test *testing.T) {
+	doc := New("test", "1.0.0", "/api")
+	schema := doc.Schema(person{})
+
+	validate := func(body string) []Violation {
+		var value interface{}
+		json.Unmarshal([]byte(body), &value)
+
+		return doc.Validate(schema, value)
+	}
+
+	test.Run("Valid", func(test *testing.T) {
+		violations := validate(`{"login":"alice","email":"","locale":"de","age":30,"tags":["a"],"address":{"city":"Berlin"},"born":null}`)
+
+		assert.Empty(test, violations)
+	})
+
+	test.Run("Invalid", func(test *testing.T) {
+		violations := validate(`{"email":"alice","locale":"fr","age":1.5,"tags":["a","b","c","d"],"address":{},"born":"yesterday"}`)
+
+		fields := []string{}
+		keywords := []string{}
+		for _, violation := range violations {
+			fields = append(fields, violation.Field)
+			keywords = append(keywords, violation.Keyword)
+		}
+
+		assert.Equal(test, []string{"login", "address.city", "age", "born", "email", "locale", "tags"}, fields)
+		assert.Equal(test, []string{"required", "required", "type", "format", "format", "enum", "maxItems"}, keywords)
+	})
+
+	test.Run("Pattern", func(test *testing.T) {
+		violations := validate(`{"login":"alice smith"}`)
+
+		assert.Len(test, violations, 1)
+		assert.Equal(test, "pattern", violations[0].Keyword)
+	})
+
+	test.Run("Array items", func(test *testing.T) {
+		violations := validate(`{"login":"alice","tags":["a",1]}`)
+
+		assert.Len(test, violations, 1)
+		assert.Equal(test, "tags[1]", violations[0].Field)
+	})
+}
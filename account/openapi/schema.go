@@ -0,0 +1,291 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"memorize/validation"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// Schema is subset of OpenAPI schema object the generator writes
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// Schema describes JSON form of value type, named structs are added to components
+// binding tags of request structs become constraints
+func (d *Document) Schema(value interface{}) *Schema {
+	return d.schemaOf(reflect.TypeOf(value), "json", true)
+}
+
+// schema of form fields, it is inlined as form names may differ from JSON names
+func (d *Document) formSchema(value interface{}) *Schema {
+	return d.schemaOf(reflect.TypeOf(value), "form", false)
+}
+
+// Resolve follows reference to component schema
+func (d *Document) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, schemaRefPrefix)]
+	}
+
+	return schema
+}
+
+func (d *Document) schemaOf(t reflect.Type, tag string, components bool) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: nullable}
+	case t == uuidType:
+		return &Schema{Type: "string", Format: "uuid", Nullable: nullable}
+	case implements(t, textMarshalerType):
+		return &Schema{Type: "string", Nullable: nullable}
+	case implements(t, jsonMarshalerType):
+		// binary values marshal themselves as encoded strings
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: nullable}
+		}
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32", Nullable: nullable}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64", Nullable: nullable}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0), Nullable: nullable}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Nullable: nullable}
+	case reflect.String:
+		return &Schema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: nullable}
+		}
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem(), tag, components), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem(), tag, components), Nullable: true}
+	case reflect.Struct:
+		if !components || t.Name() == "" {
+			return d.structSchema(t, tag, components)
+		}
+		return &Schema{Ref: schemaRefPrefix + d.component(t, tag)}
+	default:
+		// interface values may be anything
+		return &Schema{}
+	}
+}
+
+// component adds schema of named struct once, returns its name
+func (d *Document) component(t reflect.Type, tag string) string {
+	name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+
+	// same name in other package
+	if schema, ok := d.Components.Schemas[name]; ok && d.types[name] != t && schema != nil {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	if _, ok := d.Components.Schemas[name]; ok {
+		return name
+	}
+
+	if d.types == nil {
+		d.types = map[string]reflect.Type{}
+	}
+
+	// placeholder stops recursion of self referencing types
+	d.Components.Schemas[name] = nil
+	d.types[name] = t
+	d.Components.Schemas[name] = d.structSchema(t, tag, true)
+
+	return name
+}
+
+func (d *Document) structSchema(t reflect.Type, tag string, components bool) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, ok := fieldName(field, tag)
+		if !ok {
+			continue
+		}
+
+		// fields of embedded struct are fields of this one
+		if field.Anonymous && name == "" {
+			embedded := d.structSchema(indirect(field.Type), tag, components)
+			for property, propertySchema := range embedded.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := d.schemaOf(field.Type, tag, components)
+		if constrain(property, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+	}
+
+	return schema
+}
+
+// name of field in tag, ok is false for fields that are not serialized
+func fieldName(field reflect.StructField, tag string) (string, bool) {
+	if field.PkgPath != "" && !field.Anonymous {
+		return "", false
+	}
+
+	name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+	if name == "-" {
+		return "", false
+	}
+
+	return name, true
+}
+
+// constrain applies validator tags to schema of field, returns true for required fields
+func constrain(schema *Schema, binding string) bool {
+	required := false
+	omitempty := false
+
+	if binding == "" || schema.Ref != "" {
+		return strings.HasPrefix(binding, "required")
+	}
+
+rules:
+	for _, rule := range strings.Split(binding, ",") {
+		parts := strings.SplitN(rule, "=", 2)
+		param := ""
+		if len(parts) == 2 {
+			param = parts[1]
+		}
+
+		switch parts[0] {
+		case "dive":
+			// rules after dive apply to items
+			break rules
+		case "required":
+			required = true
+		case "omitempty":
+			omitempty = true
+		case "min", "gte":
+			limit(schema, param, true)
+		case "max", "lte":
+			limit(schema, param, false)
+		case "len":
+			limit(schema, param, true)
+			limit(schema, param, false)
+		case "email":
+			schema.Format = "email"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "uuid":
+			schema.Format = "uuid"
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		default:
+			if pattern, ok := validation.Patterns[parts[0]]; ok {
+				schema.Pattern = pattern.String()
+			}
+		}
+	}
+
+	// validator skips rules of empty values with omitempty
+	if omitempty && len(schema.Enum) > 0 {
+		schema.Enum = append([]string{""}, schema.Enum...)
+	}
+
+	return required
+}
+
+// sets lower or upper limit in units of schema type
+func limit(schema *Schema, param string, lower bool) {
+	value, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = &value
+		} else {
+			schema.MaxLength = &value
+		}
+	case "array":
+		if lower {
+			schema.MinItems = &value
+		} else {
+			schema.MaxItems = &value
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum = float(float64(value))
+		} else {
+			schema.Maximum = float(float64(value))
+		}
+	}
+}
+
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func float(value float64) *float64 {
+	return &value
+}
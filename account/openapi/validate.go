@@ -0,0 +1,208 @@
+package openapi
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Violation describes value that does not match schema
+type Violation struct {
+	// path of value in document, like "scopes[1]"
+	Field   string
+	Value   interface{}
+	Keyword string
+	Param   string
+	Message string
+}
+
+func (v Violation) Error() string {
+	if v.Field == "" {
+		return v.Message
+	}
+
+	return v.Field + ": " + v.Message
+}
+
+// Validate checks decoded JSON value against schema
+// value must be decoded into interface{}, numbers as float64
+func (d *Document) Validate(schema *Schema, value interface{}) []Violation {
+	return d.validate(schema, value, "", nil)
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, field string, violations []Violation) []Violation {
+	schema = d.Resolve(schema)
+	if schema == nil {
+		return violations
+	}
+
+	violation := func(keyword string, param string, message string) []Violation {
+		return append(violations, Violation{Field: field, Value: value, Keyword: keyword, Param: param, Message: message})
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return violations
+		}
+		return violation("type", schema.Type, "must not be null")
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if schema.Type != "" && schema.Type != "object" {
+			return violation("type", schema.Type, "must be "+schema.Type)
+		}
+		return d.validateObject(schema, value, field, violations)
+
+	case []interface{}:
+		if schema.Type != "" && schema.Type != "array" {
+			return violation("type", schema.Type, "must be "+schema.Type)
+		}
+		if schema.MinItems != nil && len(value) < *schema.MinItems {
+			violations = violation("minItems", strconv.Itoa(*schema.MinItems), fmt.Sprintf("must have at least %d items", *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+			violations = violation("maxItems", strconv.Itoa(*schema.MaxItems), fmt.Sprintf("must have at most %d items", *schema.MaxItems))
+		}
+		for i, item := range value {
+			violations = d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", field, i), violations)
+		}
+		return violations
+
+	case string:
+		if schema.Type != "" && schema.Type != "string" {
+			return violation("type", schema.Type, "must be "+schema.Type)
+		}
+		return validateString(schema, value, violation, violations)
+
+	case float64:
+		if schema.Type == "integer" && value != float64(int64(value)) {
+			return violation("type", schema.Type, "must be integer")
+		}
+		if schema.Type != "" && schema.Type != "integer" && schema.Type != "number" {
+			return violation("type", schema.Type, "must be "+schema.Type)
+		}
+		if schema.Minimum != nil && value < *schema.Minimum {
+			violations = violation("minimum", formatFloat(*schema.Minimum), "must be at least "+formatFloat(*schema.Minimum))
+		}
+		if schema.Maximum != nil && value > *schema.Maximum {
+			violations = violation("maximum", formatFloat(*schema.Maximum), "must be at most "+formatFloat(*schema.Maximum))
+		}
+		return violations
+
+	case bool:
+		if schema.Type != "" && schema.Type != "boolean" {
+			return violation("type", schema.Type, "must be "+schema.Type)
+		}
+		return violations
+
+	default:
+		return violation("type", schema.Type, fmt.Sprintf("unsupported value of type %T", value))
+	}
+}
+
+func (d *Document) validateObject(schema *Schema, value map[string]interface{}, field string, violations []Violation) []Violation {
+	for _, name := range schema.Required {
+		if _, ok := value[name]; !ok {
+			violations = append(violations, Violation{
+				Field:   join(field, name),
+				Keyword: "required",
+				Message: "is required",
+			})
+		}
+	}
+
+	// sorted, so violations come in same order every time
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		if !ok {
+			// unknown properties are allowed like encoding/json does
+			property = schema.AdditionalProperties
+		}
+
+		violations = d.validate(property, value[name], join(field, name), violations)
+	}
+
+	return violations
+}
+
+func validateString(schema *Schema, value string, violation func(string, string, string) []Violation, violations []Violation) []Violation {
+	length := len([]rune(value))
+
+	if schema.MinLength != nil && length < *schema.MinLength {
+		violations = violation("minLength", strconv.Itoa(*schema.MinLength), fmt.Sprintf("must be at least %d characters long", *schema.MinLength))
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		violations = violation("maxLength", strconv.Itoa(*schema.MaxLength), fmt.Sprintf("must be at most %d characters long", *schema.MaxLength))
+	}
+
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, allowed := range schema.Enum {
+			found = found || allowed == value
+		}
+		if !found {
+			violations = violation("enum", strings.Join(schema.Enum, " "), "must be one of "+strings.Join(schema.Enum, ", "))
+		}
+	}
+
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err == nil && !pattern.MatchString(value) {
+			violations = violation("pattern", schema.Pattern, "must match "+schema.Pattern)
+		}
+	}
+
+	// empty strings are left to required, like validator does
+	if value != "" && !validFormat(schema.Format, value) {
+		violations = violation("format", schema.Format, "must be valid "+schema.Format)
+	}
+
+	return violations
+}
+
+func validFormat(format string, value string) bool {
+	var err error
+
+	switch format {
+	case "email":
+		_, err = mail.ParseAddress(value)
+	case "uri":
+		var parsed *url.URL
+		parsed, err = url.Parse(value)
+		if err == nil && parsed.Scheme == "" {
+			return false
+		}
+	case "uuid":
+		_, err = uuid.Parse(value)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	}
+
+	return err == nil
+}
+
+func join(field string, name string) string {
+	if field == "" {
+		return name
+	}
+
+	return field + "." + name
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"context"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"strconv"
+)
+
+// GitHub endpoints, GitHub is OAuth 2.0 only and has no discovery
+const (
+	githubAuthURL  = "https://github.com/login/oauth/authorize"
+	githubTokenURL = "https://github.com/login/oauth/access_token"
+	githubUserURL  = "https://api.github.com/user"
+)
+
+// user returned by GitHub api
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type githubProvider struct {
+	Config
+	AuthURL    string
+	TokenURL   string
+	UserURL    string
+	HTTPClient *http.Client
+}
+
+// factory for GitHub provider
+func NewGitHubProvider(config *Config, httpClient *http.Client) models.IdentityProvider {
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"read:user"}
+	}
+
+	return &githubProvider{
+		Config: Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURI:  config.RedirectURI,
+			Scopes:       scopes,
+		},
+		AuthURL:    githubAuthURL,
+		TokenURL:   githubTokenURL,
+		UserURL:    githubUserURL,
+		HTTPClient: httpClient,
+	}
+}
+
+// url to send user agent to
+func (p *githubProvider) AuthCodeURL(ctx context.Context, state string, codeChallenge string) (string, error) {
+	authURL, err := authCodeURL(p.AuthURL, &p.Config, state, codeChallenge)
+	if err != nil {
+		log.Printf("Unable to build GitHub authorization url: %v\n", err)
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	return authURL, nil
+}
+
+// exchange code and read the user from GitHub api
+func (p *githubProvider) Exchange(ctx context.Context, code string, codeVerifier string) (*models.ExternalProfile, error) {
+	accessToken, err := exchangeCode(ctx, p.HTTPClient, p.TokenURL, &p.Config, code, codeVerifier)
+	if err != nil {
+		log.Printf("Unable to exchange code with GitHub: %v\n", err)
+		return nil, apperrors.NewAuthorization("Unable to sign in with identity provider")
+	}
+
+	user := &githubUser{}
+	if err := getJSON(ctx, p.HTTPClient, p.UserURL, accessToken, user); err != nil {
+		log.Printf("Unable to get GitHub user: %v\n", err)
+		return nil, apperrors.NewAuthorization("Unable to sign in with identity provider")
+	}
+
+	if user.ID == 0 {
+		log.Println("GitHub user has no id")
+		return nil, apperrors.NewAuthorization("Unable to sign in with identity provider")
+	}
+
+	// public email on GitHub profile is verified by GitHub
+	return &models.ExternalProfile{
+		Subject: strconv.FormatInt(user.ID, 10),
+		Login:   user.Login,
+		Name:    user.Name,
+		Email:   user.Email,
+		Picture: user.AvatarURL,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig of mail server used to send mails
+type SMTPConfig struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	SMTPConfig
+}
+
+// factory for mailer sending through SMTP server, authenticates when username is set
+func NewSMTPMailer(config *SMTPConfig) models.Mailer {
+	return &smtpMailer{
+		SMTPConfig: *config,
+	}
+}
+
+// send mail, net/smtp takes no context so its deadline is not applied
+func (m *smtpMailer) Send(ctx context.Context, mail *models.Mail) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, err := net.SplitHostPort(m.Addr)
+		if err != nil {
+			log.Printf("Invalid SMTP address %v: %v\n", m.Addr, err)
+			return apperrors.NewInternal().Wrap(err)
+		}
+
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	if err := smtp.SendMail(m.Addr, auth, m.From, []string{mail.To}, m.message(mail)); err != nil {
+		log.Printf("Unable to send mail through %v: %v\n", m.Addr, err)
+		return apperrors.NewServiceUnavailable()
+	}
+
+	return nil
+}
+
+func (m *smtpMailer) message(mail *models.Mail) []byte {
+	// header values come from our code and user email, line breaks would inject headers
+	header := strings.NewReplacer("\r", "", "\n", "")
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", header.Replace(m.From))
+	fmt.Fprintf(&message, "To: %s\r\n", header.Replace(mail.To))
+	fmt.Fprintf(&message, "Subject: %s\r\n", header.Replace(mail.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+
+	return []byte(message.String())
+}
+
+type logMailer struct{}
+
+// factory for mailer that only logs mails, for development without mail server
+func NewLogMailer() models.Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(ctx context.Context, mail *models.Mail) error {
+	log.Printf("Mail to %v: %v\n%v\n", mail.To, mail.Subject, mail.Body)
+	return nil
+}
@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Config of an external provider registered for this service
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string
+}
+
+// client used to talk to providers when none is configured
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// authorization url with PKCE parameters
+func authCodeURL(authURL string, config *Config, state string, codeChallenge string) (string, error) {
+	parsedURL, err := url.Parse(authURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	query := parsedURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", config.ClientID)
+	query.Set("redirect_uri", config.RedirectURI)
+	query.Set("scope", strings.Join(config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	parsedURL.RawQuery = query.Encode()
+
+	return parsedURL.String(), nil
+}
+
+// exchange authorization code at token endpoint, returns access token
+func exchangeCode(
+	ctx context.Context,
+	client *http.Client,
+	tokenURL string,
+	config *Config,
+	code string,
+	codeVerifier string,
+) (string, error) {
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {config.RedirectURI},
+		"client_id":     {config.ClientID},
+		"client_secret": {config.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	var response struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	if err := doJSON(client, request, &response); err != nil {
+		return "", err
+	}
+
+	// some providers report errors with status 200
+	if response.Error != "" {
+		return "", fmt.Errorf("token endpoint error: %v %v", response.Error, response.ErrorDescription)
+	}
+
+	if response.AccessToken == "" {
+		return "", fmt.Errorf("token endpoint returned no access token")
+	}
+
+	return response.AccessToken, nil
+}
+
+// GET json resource with bearer token
+func getJSON(ctx context.Context, client *http.Client, resourceURL string, accessToken string, result interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, resourceURL, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	return doJSON(client, request, result)
+}
+
+func doJSON(client *http.Client, request *http.Request, result interface{}) error {
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode >= 300 && response.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("%v %v returned status %v", request.Method, request.URL.Path, response.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("could not decode response of %v: %w", request.URL.Path, err)
+	}
+
+	return nil
+}
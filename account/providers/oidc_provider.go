@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"context"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// endpoints read from provider discovery document
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// claims read from userinfo endpoint
+type userinfoClaims struct {
+	Subject           string `json:"sub"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Picture           string `json:"picture"`
+}
+
+type oidcProvider struct {
+	Config
+	Issuer     string
+	HTTPClient *http.Client
+
+	discovery *discoveryDocument
+	mutex     sync.Mutex
+}
+
+// factory for generic OpenID Connect provider, endpoints are discovered from issuer
+func NewOIDCProvider(issuer string, config *Config, httpClient *http.Client) models.IdentityProvider {
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+
+	return &oidcProvider{
+		Config: Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURI:  config.RedirectURI,
+			Scopes:       scopes,
+		},
+		Issuer:     strings.TrimSuffix(issuer, "/"),
+		HTTPClient: httpClient,
+	}
+}
+
+// url to send user agent to
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, state string, codeChallenge string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := authCodeURL(discovery.AuthorizationEndpoint, &p.Config, state, codeChallenge)
+	if err != nil {
+		log.Printf("Unable to build authorization url for %v: %v\n", p.Issuer, err)
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	return authURL, nil
+}
+
+// exchange code and read the user from userinfo endpoint
+// userinfo is fetched over the same authenticated back channel, so id token is not needed
+func (p *oidcProvider) Exchange(ctx context.Context, code string, codeVerifier string) (*models.ExternalProfile, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := exchangeCode(ctx, p.HTTPClient, discovery.TokenEndpoint, &p.Config, code, codeVerifier)
+	if err != nil {
+		log.Printf("Unable to exchange code with %v: %v\n", p.Issuer, err)
+		return nil, apperrors.NewAuthorization("Unable to sign in with identity provider")
+	}
+
+	claims := &userinfoClaims{}
+	if err := getJSON(ctx, p.HTTPClient, discovery.UserinfoEndpoint, accessToken, claims); err != nil {
+		log.Printf("Unable to get userinfo from %v: %v\n", p.Issuer, err)
+		return nil, apperrors.NewAuthorization("Unable to sign in with identity provider")
+	}
+
+	if claims.Subject == "" {
+		log.Printf("Userinfo from %v has no subject\n", p.Issuer)
+		return nil, apperrors.NewAuthorization("Unable to sign in with identity provider")
+	}
+
+	profile := &models.ExternalProfile{
+		Subject: claims.Subject,
+		Login:   claims.PreferredUsername,
+		Name:    claims.Name,
+		Picture: claims.Picture,
+	}
+
+	// unverified email can belong to someone else
+	if claims.EmailVerified {
+		profile.Email = claims.Email
+	}
+
+	return profile, nil
+}
+
+// fetch discovery document once, retried on next call if it fails
+func (p *oidcProvider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	discovery := &discoveryDocument{}
+	discoveryURL := p.Issuer + "/.well-known/openid-configuration"
+
+	if err := getJSON(ctx, p.HTTPClient, discoveryURL, "", discovery); err != nil {
+		log.Printf("Unable to discover %v: %v\n", p.Issuer, err)
+		return nil, apperrors.NewServiceUnavailable()
+	}
+
+	if discovery.Issuer != p.Issuer {
+		log.Printf("Discovery document issuer %v does not match %v\n", discovery.Issuer, p.Issuer)
+		return nil, apperrors.NewServiceUnavailable()
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserinfoEndpoint == "" {
+		log.Printf("Discovery document of %v misses endpoints\n", p.Issuer)
+		return nil, apperrors.NewServiceUnavailable()
+	}
+
+	p.discovery = discovery
+
+	return discovery, nil
+}
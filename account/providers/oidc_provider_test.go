@@ -0,0 +1,92 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"memorize/mocks"
+	"memorize/models/apperrors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDCProvider(test *testing.T) {
+	server := mocks.NewMockOIDCServer("client", "secret", map[string]interface{}{
+		"sub":                "external-id",
+		"preferred_username": "alice",
+		"name":               "Alice",
+		"email":              "alice@mail.com",
+		"email_verified":     true,
+	})
+	defer server.Close()
+
+	config := &Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURI:  "http://localhost/oauth/test/callback",
+	}
+
+	codeVerifier := "verifier-verifier-verifier-verifier-verifier"
+	hash := sha256.Sum256([]byte(codeVerifier))
+	codeChallenge := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	test.Run("Sign in", func(test *testing.T) {
+		provider := NewOIDCProvider(server.URL, config, nil)
+
+		authURL, err := provider.AuthCodeURL(context.Background(), "state", codeChallenge)
+		assert.NoError(test, err)
+
+		code, state, err := server.Authorize(authURL)
+		assert.NoError(test, err)
+		assert.Equal(test, "state", state)
+
+		profile, err := provider.Exchange(context.Background(), code, codeVerifier)
+		assert.NoError(test, err)
+		assert.Equal(test, "external-id", profile.Subject)
+		assert.Equal(test, "alice", profile.Login)
+		assert.Equal(test, "Alice", profile.Name)
+		assert.Equal(test, "alice@mail.com", profile.Email)
+	})
+
+	test.Run("Wrong code verifier", func(test *testing.T) {
+		provider := NewOIDCProvider(server.URL, config, nil)
+
+		authURL, err := provider.AuthCodeURL(context.Background(), "state", codeChallenge)
+		assert.NoError(test, err)
+
+		code, _, err := server.Authorize(authURL)
+		assert.NoError(test, err)
+
+		profile, err := provider.Exchange(context.Background(), code, "other-verifier")
+		assert.Nil(test, profile)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("Unverified email is ignored", func(test *testing.T) {
+		unverified := mocks.NewMockOIDCServer("client", "secret", map[string]interface{}{
+			"sub":   "external-id",
+			"email": "alice@mail.com",
+		})
+		defer unverified.Close()
+
+		provider := NewOIDCProvider(unverified.URL, config, nil)
+
+		authURL, err := provider.AuthCodeURL(context.Background(), "state", codeChallenge)
+		assert.NoError(test, err)
+
+		code, _, err := unverified.Authorize(authURL)
+		assert.NoError(test, err)
+
+		profile, err := provider.Exchange(context.Background(), code, codeVerifier)
+		assert.NoError(test, err)
+		assert.Empty(test, profile.Email)
+	})
+
+	test.Run("Issuer mismatch", func(test *testing.T) {
+		provider := NewOIDCProvider(server.URL+"/other", config, nil)
+
+		_, err := provider.AuthCodeURL(context.Background(), "state", codeChallenge)
+		assert.Error(test, err)
+	})
+}
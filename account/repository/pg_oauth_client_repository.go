@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type pgOAuthClientRepository struct {
+	DB *sqlx.DB
+}
+
+// oauthClientRow maps oauth_clients table, redirect uris are postgres array
+type oauthClientRow struct {
+	ClientID     string         `db:"client_id"`
+	SecretHash   string         `db:"secret_hash"`
+	Name         string         `db:"name"`
+	RedirectURIs pq.StringArray `db:"redirect_uris"`
+	Service      bool           `db:"service"`
+	Audiences    pq.StringArray `db:"audiences"`
+	CreatedAt    time.Time      `db:"created_at"`
+}
+
+func (row *oauthClientRow) toModel() *models.OAuthClient {
+	return &models.OAuthClient{
+		ClientID:     row.ClientID,
+		SecretHash:   row.SecretHash,
+		Name:         row.Name,
+		RedirectURIs: []string(row.RedirectURIs),
+		Service:      row.Service,
+		Audiences:    []string(row.Audiences),
+		CreatedAt:    row.CreatedAt,
+	}
+}
+
+// factory for initializing oauth client repository
+func NewOAuthClientRepository(db *sqlx.DB) models.OAuthClientRepository {
+	return &pgOAuthClientRepository{
+		DB: db,
+	}
+}
+
+// create client record in database
+func (r *pgOAuthClientRepository) Create(ctx context.Context, client *models.OAuthClient) error {
+	query := `
+		INSERT INTO oauth_clients (client_id, secret_hash, name, redirect_uris, service, audiences)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at;
+	`
+
+	if err := r.DB.GetContext(
+		ctx,
+		&client.CreatedAt,
+		query,
+		client.ClientID,
+		client.SecretHash,
+		client.Name,
+		pq.StringArray(client.RedirectURIs),
+		client.Service,
+		pq.StringArray(client.Audiences),
+	); err != nil {
+
+		if err, ok := err.(*pq.Error); ok && err.Code.Name() == "unique_violation" {
+			return apperrors.NewConflict("client", client.ClientID)
+		}
+
+		log.Printf("Could not create oauth client: %v. Reason: %v\n", client.Name, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
+
+// fetch client by id from database
+func (r *pgOAuthClientRepository) FindByID(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	row := &oauthClientRow{}
+
+	query := "SELECT * FROM oauth_clients WHERE client_id=$1"
+
+	if err := r.DB.GetContext(ctx, row, query, clientID); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Unable to get oauth client: %v. Reason: %v\n", clientID, err)
+		}
+
+		return nil, apperrors.NewNotFound("client", clientID)
+	}
+
+	return row.toModel(), nil
+}
+
+// fetch all clients from database
+func (r *pgOAuthClientRepository) FindAll(ctx context.Context) ([]*models.OAuthClient, error) {
+	rows := []oauthClientRow{}
+
+	query := "SELECT * FROM oauth_clients ORDER BY created_at"
+
+	if err := r.DB.SelectContext(ctx, &rows, query); err != nil {
+		log.Printf("Unable to list oauth clients. Reason: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	clients := make([]*models.OAuthClient, 0, len(rows))
+	for i := range rows {
+		clients = append(clients, rows[i].toModel())
+	}
+
+	return clients, nil
+}
+
+// delete client record from database
+func (r *pgOAuthClientRepository) Delete(ctx context.Context, clientID string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM oauth_clients WHERE client_id=$1", clientID)
+	if err != nil {
+		log.Printf("Unable to delete oauth client: %v. Reason: %v\n", clientID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return apperrors.NewNotFound("client", clientID)
+	}
+
+	return nil
+}
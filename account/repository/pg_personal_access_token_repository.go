@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type pgPersonalAccessTokenRepository struct {
+	DB *sqlx.DB
+}
+
+// personalAccessTokenRow maps personal_access_tokens table, scopes are postgres array
+type personalAccessTokenRow struct {
+	ID         uuid.UUID      `db:"id"`
+	UserID     uuid.UUID      `db:"uid"`
+	Name       string         `db:"name"`
+	Scopes     pq.StringArray `db:"scopes"`
+	TokenHash  string         `db:"token_hash"`
+	CreatedAt  time.Time      `db:"created_at"`
+	ExpiresAt  *time.Time     `db:"expires_at"`
+	LastUsedAt *time.Time     `db:"last_used_at"`
+}
+
+func (row *personalAccessTokenRow) toModel() *models.PersonalAccessToken {
+	return &models.PersonalAccessToken{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		Name:       row.Name,
+		Scopes:     []string(row.Scopes),
+		TokenHash:  row.TokenHash,
+		CreatedAt:  row.CreatedAt,
+		ExpiresAt:  row.ExpiresAt,
+		LastUsedAt: row.LastUsedAt,
+	}
+}
+
+// factory for initializing personal access token repository
+func NewPersonalAccessTokenRepository(db *sqlx.DB) models.PersonalAccessTokenRepository {
+	return &pgPersonalAccessTokenRepository{
+		DB: db,
+	}
+}
+
+// create token record in database
+func (r *pgPersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (uid, name, scopes, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at;
+	`
+
+	if err := r.DB.QueryRowxContext(
+		ctx,
+		query,
+		token.UserID,
+		token.Name,
+		pq.StringArray(token.Scopes),
+		token.TokenHash,
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt); err != nil {
+		log.Printf("Could not create personal access token: %v for user: %v. Reason: %v\n", token.Name, token.UserID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
+
+// fetch token by hash of plain token
+func (r *pgPersonalAccessTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	row := &personalAccessTokenRow{}
+
+	query := "SELECT * FROM personal_access_tokens WHERE token_hash=$1"
+
+	if err := r.DB.GetContext(ctx, row, query, tokenHash); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Unable to get personal access token. Reason: %v\n", err)
+			return nil, apperrors.NewInternal().Wrap(err)
+		}
+
+		return nil, apperrors.NewNotFound("token", "personal access token")
+	}
+
+	return row.toModel(), nil
+}
+
+// fetch tokens of user
+func (r *pgPersonalAccessTokenRepository) FindByUserID(ctx context.Context, uid uuid.UUID) ([]*models.PersonalAccessToken, error) {
+	rows := []personalAccessTokenRow{}
+
+	query := "SELECT * FROM personal_access_tokens WHERE uid=$1 ORDER BY created_at"
+
+	if err := r.DB.SelectContext(ctx, &rows, query, uid); err != nil {
+		log.Printf("Unable to get personal access tokens of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	tokens := make([]*models.PersonalAccessToken, 0, len(rows))
+	for i := range rows {
+		tokens = append(tokens, rows[i].toModel())
+	}
+
+	return tokens, nil
+}
+
+// delete token of user
+func (r *pgPersonalAccessTokenRepository) Delete(ctx context.Context, uid uuid.UUID, tokenID uuid.UUID) error {
+	query := "DELETE FROM personal_access_tokens WHERE uid=$1 AND id=$2"
+
+	result, err := r.DB.ExecContext(ctx, query, uid, tokenID)
+	if err != nil {
+		log.Printf("Unable to delete personal access token: %v of user: %v. Reason: %v\n", tokenID, uid, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return apperrors.NewNotFound("token", tokenID.String())
+	}
+
+	return nil
+}
+
+// set time token was last used
+func (r *pgPersonalAccessTokenRepository) UpdateLastUsed(ctx context.Context, tokenID uuid.UUID, lastUsedAt time.Time) error {
+	query := "UPDATE personal_access_tokens SET last_used_at=$1 WHERE id=$2"
+
+	if _, err := r.DB.ExecContext(ctx, query, lastUsedAt, tokenID); err != nil {
+		log.Printf("Unable to update last use of personal access token: %v. Reason: %v\n", tokenID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type pgUserIdentityRepository struct {
+	DB *sqlx.DB
+}
+
+// factory for initializing user identity repository
+func NewUserIdentityRepository(db *sqlx.DB) models.UserIdentityRepository {
+	return &pgUserIdentityRepository{
+		DB: db,
+	}
+}
+
+// create identity record in database
+func (r *pgUserIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (provider, subject, uid, email)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at;
+	`
+
+	if err := r.DB.GetContext(
+		ctx,
+		&identity.CreatedAt,
+		query,
+		identity.Provider,
+		identity.Subject,
+		identity.UserID,
+		identity.Email,
+	); err != nil {
+
+		if err, ok := err.(*pq.Error); ok && err.Code.Name() == "unique_violation" {
+			return apperrors.NewConflict("identity", identity.Provider)
+		}
+
+		log.Printf("Could not create %v identity for user: %v. Reason: %v\n", identity.Provider, identity.UserID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
+
+// fetch identity by provider and its subject
+func (r *pgUserIdentityRepository) FindBySubject(
+	ctx context.Context,
+	provider string,
+	subject string,
+) (*models.UserIdentity, error) {
+
+	identity := &models.UserIdentity{}
+
+	query := "SELECT * FROM user_identities WHERE provider=$1 AND subject=$2"
+
+	if err := r.DB.GetContext(ctx, identity, query, provider, subject); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Unable to get %v identity: %v. Reason: %v\n", provider, subject, err)
+			return nil, apperrors.NewInternal().Wrap(err)
+		}
+
+		return nil, apperrors.NewNotFound("identity", provider)
+	}
+
+	return identity, nil
+}
+
+// fetch identities of user
+func (r *pgUserIdentityRepository) FindByUserID(ctx context.Context, uid uuid.UUID) ([]*models.UserIdentity, error) {
+	identities := []*models.UserIdentity{}
+
+	query := "SELECT * FROM user_identities WHERE uid=$1 ORDER BY created_at"
+
+	if err := r.DB.SelectContext(ctx, &identities, query, uid); err != nil {
+		log.Printf("Unable to get identities of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	return identities, nil
+}
+
+// delete identity of user for provider
+func (r *pgUserIdentityRepository) Delete(ctx context.Context, provider string, uid uuid.UUID) error {
+	query := "DELETE FROM user_identities WHERE provider=$1 AND uid=$2"
+
+	result, err := r.DB.ExecContext(ctx, query, provider, uid)
+	if err != nil {
+		log.Printf("Unable to delete %v identity of user: %v. Reason: %v\n", provider, uid, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return apperrors.NewNotFound("identity", provider)
+	}
+
+	return nil
+}
@@ -2,9 +2,15 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"log"
 	"memorize/models"
 	"memorize/models/apperrors"
+	"memorize/normalize"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -23,10 +29,25 @@ func NewUserRepository(db *sqlx.DB) models.UserRepository {
 }
 
 // create user record in database
+// logins with same normalized form, or reserved by other user, are taken
 func (r *pgUserRepository) Create(ctx context.Context, user *models.User) error {
-	query := "INSERT INTO users (login, password) VALUES ($1, $2) RETURNING *"
+	key, err := loginKey(user.Login)
+	if err != nil {
+		return err
+	}
 
-	if err := r.DB.GetContext(ctx, user, query, user.Login, user.Password); err != nil {
+	query := `
+		INSERT INTO users (login, normalized_login, password)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (SELECT 1 FROM login_history WHERE normalized_login=$2 AND reserved_until > now())
+		RETURNING *
+	`
+
+	if err := r.DB.GetContext(ctx, user, query, user.Login, key, user.Password); err != nil {
+		// login is reserved
+		if err == sql.ErrNoRows {
+			return apperrors.NewConflict("login", user.Login).WithCode(apperrors.LoginTaken)
+		}
 
 		// check unique constraint
 		if err, ok := err.(*pq.Error); ok && err.Code.Name() == "unique_violation" {
@@ -36,10 +57,11 @@ func (r *pgUserRepository) Create(ctx context.Context, user *models.User) error
 				err.Code.Name(),
 			)
 
-			return apperrors.NewConflict("login", user.Login)
+			return apperrors.NewConflict("login", user.Login).WithCode(apperrors.LoginTaken)
 		}
 
 		log.Printf("Could not create a user with login: %v. Reason: %v", user.Login, err)
+		return apperrors.NewInternal().Wrap(err)
 	}
 
 	return nil
@@ -58,13 +80,38 @@ func (r *pgUserRepository) FindByID(ctx context.Context, uid uuid.UUID) (*models
 	return user, nil
 }
 
-// fetch user by login from databse
+// fetch users by ids from database, in order of ids
+func (r *pgUserRepository) FindByIDs(ctx context.Context, uids []uuid.UUID) ([]*models.User, error) {
+	users := []*models.User{}
+
+	query := "SELECT u.* FROM users u JOIN unnest($1::uuid[]) WITH ORDINALITY AS ids(uid, position) ON u.uid = ids.uid ORDER BY ids.position"
+
+	ids := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		ids = append(ids, uid.String())
+	}
+
+	if err := r.DB.SelectContext(ctx, &users, query, pq.Array(ids)); err != nil {
+		log.Printf("Unable to get users by ids. Reason: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	return users, nil
+}
+
+// fetch user by login from databse, logins are compared in normalized form
 func (r *pgUserRepository) FindByLogin(ctx context.Context, login string) (*models.User, error) {
 	user := &models.User{}
 
-	query := "SELECT * FROM users WHERE login=$1"
+	key, err := normalize.LoginKey(login)
+	if err != nil {
+		return nil, apperrors.NewNotFound("login", login)
+	}
+
+	// every stored login has a key, migration rejects logins that cannot be normalized
+	query := "SELECT * FROM users WHERE normalized_login=$1"
 
-	if err := r.DB.GetContext(ctx, user, query, login); err != nil {
+	if err := r.DB.GetContext(ctx, user, query, key); err != nil {
 		log.Printf("Unable to get user with login: %v. err %v\n", login, err)
 		return nil, apperrors.NewNotFound("login", login)
 	}
@@ -72,27 +119,307 @@ func (r *pgUserRepository) FindByLogin(ctx context.Context, login string) (*mode
 	return user, nil
 }
 
+// fetch user whose previous login is still reserved, latest change wins
+func (r *pgUserRepository) FindByPreviousLogin(ctx context.Context, login string) (*models.User, error) {
+	user := &models.User{}
+
+	key, err := normalize.LoginKey(login)
+	if err != nil {
+		return nil, apperrors.NewNotFound("login", login)
+	}
+
+	query := `
+		SELECT u.* FROM users u
+		JOIN login_history h ON h.uid = u.uid
+		WHERE h.normalized_login=$1 AND h.reserved_until > now()
+		ORDER BY h.changed_at DESC
+		LIMIT 1
+	`
+
+	if err := r.DB.GetContext(ctx, user, query, key); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.NewNotFound("login", login)
+		}
+
+		log.Printf("Unable to get user with previous login: %v. Reason: %v\n", login, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	return user, nil
+}
+
+// login is available when no other user has it or reserved it, user can take back own previous logins
+func (r *pgUserRepository) LoginAvailable(ctx context.Context, uid uuid.UUID, login string) (bool, error) {
+	key, err := normalize.LoginKey(login)
+	if err != nil {
+		return false, nil
+	}
+
+	query := `
+		SELECT NOT EXISTS (SELECT 1 FROM users WHERE normalized_login=$1 AND uid<>$2)
+		AND NOT EXISTS (SELECT 1 FROM login_history WHERE normalized_login=$1 AND uid<>$2 AND reserved_until > now())
+	`
+
+	var available bool
+	if err := r.DB.GetContext(ctx, &available, query, key, uid); err != nil {
+		log.Printf("Unable to check availability of login: %v. Reason: %v\n", login, err)
+		return false, apperrors.NewInternal().Wrap(err)
+	}
+
+	return available, nil
+}
+
+// change login of user and reserve previous one, in one transaction so login is never free in between
+// changing only display form of login reserves nothing
+func (r *pgUserRepository) UpdateLogin(ctx context.Context, uid uuid.UUID, login string, reservedUntil time.Time, limit models.LoginChangeLimit) (*models.User, error) {
+	key, err := loginKey(login)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("Unable to begin login change of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+	defer tx.Rollback()
+
+	user := &models.User{}
+	if err := tx.GetContext(ctx, user, "SELECT * FROM users WHERE uid=$1 FOR UPDATE", uid); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.NewNotFound("uid", uid.String())
+		}
+
+		log.Printf("Unable to lock user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	// counted while user is locked, so concurrent changes cannot both pass the limit
+	// login_changes keeps every change, unlike login_history that taking back previous login deletes from
+	var changes int
+	changesQuery := "SELECT count(*) FROM login_changes WHERE uid=$1 AND changed_at > $2"
+	if err := tx.GetContext(ctx, &changes, changesQuery, uid, limit.Since); err != nil {
+		log.Printf("Unable to count login changes of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	if changes >= limit.Changes {
+		log.Printf("Too many login changes of user: %v\n", uid)
+		return nil, apperrors.NewTooManyRequests()
+	}
+
+	var reserved bool
+	reservedQuery := "SELECT EXISTS (SELECT 1 FROM login_history WHERE normalized_login=$1 AND uid<>$2 AND reserved_until > now())"
+	if err := tx.GetContext(ctx, &reserved, reservedQuery, key, uid); err != nil {
+		log.Printf("Unable to check reservation of login: %v. Reason: %v\n", login, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	if reserved {
+		return nil, apperrors.NewConflict("login", login).WithCode(apperrors.LoginTaken)
+	}
+
+	// every change is recorded for rate limit, including ones back to previous login
+	changeQuery := "INSERT INTO login_changes (uid, previous_login) SELECT uid, login FROM users WHERE uid=$1 AND login<>$2"
+	if _, err := tx.ExecContext(ctx, changeQuery, uid, login); err != nil {
+		log.Printf("Unable to record login change of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	// previous login of user is reserved, unless new one only changes its display form
+	historyQuery := `
+		INSERT INTO login_history (uid, login, normalized_login, reserved_until)
+		SELECT uid, login, normalized_login, $2 FROM users WHERE uid=$1 AND normalized_login<>$3
+	`
+	if _, err := tx.ExecContext(ctx, historyQuery, uid, reservedUntil, key); err != nil {
+		log.Printf("Unable to reserve previous login of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	// taking back own previous login ends its reservation
+	if _, err := tx.ExecContext(ctx, "DELETE FROM login_history WHERE uid=$1 AND normalized_login=$2", uid, key); err != nil {
+		log.Printf("Unable to release previous login of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	query := "UPDATE users SET login=$1, normalized_login=$2, version=version+1, updated_at=now() WHERE uid=$3 RETURNING *"
+	if err := tx.GetContext(ctx, user, query, login, key, uid); err != nil {
+		if err, ok := err.(*pq.Error); ok && err.Code.Name() == "unique_violation" {
+			return nil, apperrors.NewConflict("login", login).WithCode(apperrors.LoginTaken)
+		}
+
+		log.Printf("Failed to change login of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Unable to commit login change of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	return user, nil
+}
+
 // Update updates a user's properties
-// TODO fix user mutation
+// replaces all details of user, UpdateFields changes some of them
+// user must still have its version unless it is zero
 func (r *pgUserRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users 
-		SET name=:name, email=:email, website=:website
+		SET name=:name, email=:email, normalized_email=:normalized_email, website=:website, locale=:locale,
+			version=version+1, updated_at=now()
 		WHERE uid=:uid
-		RETURNING *;
 	`
 
+	if user.Version != 0 {
+		query += " AND version=:version"
+	}
+
+	query += " RETURNING *;"
+
+	user.NormalizedEmail = normalize.EmailKey(user.Email)
+
 	preparedQuery, err := r.DB.PrepareNamedContext(ctx, query)
 
 	if err != nil {
 		log.Printf("Unable to prepare user update query: %v\n", err)
-		return apperrors.NewInternal()
+		return apperrors.NewInternal().Wrap(err)
 	}
 
 	if err := preparedQuery.GetContext(ctx, user, user); err != nil {
+		if err == sql.ErrNoRows {
+			return r.notUpdated(ctx, user.UID)
+		}
+
 		log.Printf("Failed to update details for user: %v\n", user)
-		return apperrors.NewInternal()
+		return apperrors.NewInternal().Wrap(err)
 	}
 
 	return nil
 }
+
+// update only columns in mask of patch, user is fetched when mask is empty
+func (r *pgUserRepository) UpdateFields(ctx context.Context, patch *models.UserPatch) (*models.User, error) {
+	values := patch.Values()
+	if len(values) == 0 {
+		user, err := r.FindByID(ctx, patch.UID)
+		if err == nil && patch.Version != 0 && user.Version != patch.Version {
+			return nil, apperrors.NewPreconditionFailed("uid", patch.UID.String())
+		}
+
+		return user, err
+	}
+
+	if email, ok := values[models.UserEmail]; ok {
+		values["normalized_email"] = normalize.EmailKey(email)
+	}
+
+	// columns come from models, never from request, values are parameters
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	assignments := make([]string, 0, len(columns)+2)
+	args := make([]interface{}, 0, len(columns)+2)
+	for i, column := range columns {
+		assignments = append(assignments, fmt.Sprintf("%v=$%d", column, i+1))
+		args = append(args, values[column])
+	}
+	assignments = append(assignments, "version=version+1", "updated_at=now()")
+	args = append(args, patch.UID)
+
+	condition := fmt.Sprintf("uid=$%d", len(args))
+	if patch.Version != 0 {
+		args = append(args, patch.Version)
+		condition += fmt.Sprintf(" AND version=$%d", len(args))
+	}
+
+	query := fmt.Sprintf("UPDATE users SET %v WHERE %v RETURNING *", strings.Join(assignments, ", "), condition)
+
+	user := &models.User{}
+	if err := r.DB.GetContext(ctx, user, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, r.notUpdated(ctx, patch.UID)
+		}
+
+		log.Printf("Failed to update %v of user: %v. Reason: %v\n", columns, patch.UID, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	return user, nil
+}
+
+// notUpdated tells why conditional update did not touch user, it is missing or has other version
+func (r *pgUserRepository) notUpdated(ctx context.Context, uid uuid.UUID) error {
+	if _, err := r.FindByID(ctx, uid); err != nil {
+		return err
+	}
+
+	return apperrors.NewPreconditionFailed("uid", uid.String())
+}
+
+// update user password hash
+func (r *pgUserRepository) UpdatePassword(ctx context.Context, uid uuid.UUID, password string) error {
+	query := "UPDATE users SET password=$1, version=version+1, updated_at=now() WHERE uid=$2"
+
+	result, err := r.DB.ExecContext(ctx, query, password, uid)
+	if err != nil {
+		log.Printf("Failed to update password for user: %v. Reason: %v\n", uid, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return checkUserUpdated(result, uid)
+}
+
+// update user disabled flag
+func (r *pgUserRepository) UpdateDisabled(ctx context.Context, uid uuid.UUID, disabled bool) error {
+	query := "UPDATE users SET disabled=$1, version=version+1, updated_at=now() WHERE uid=$2"
+
+	result, err := r.DB.ExecContext(ctx, query, disabled, uid)
+	if err != nil {
+		log.Printf("Failed to update disabled flag for user: %v. Reason: %v\n", uid, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return checkUserUpdated(result, uid)
+}
+
+// update user second factor flag
+func (r *pgUserRepository) UpdateSecondFactor(ctx context.Context, uid uuid.UUID, enabled bool) error {
+	query := "UPDATE users SET second_factor=$1, version=version+1, updated_at=now() WHERE uid=$2"
+
+	result, err := r.DB.ExecContext(ctx, query, enabled, uid)
+	if err != nil {
+		log.Printf("Failed to update second factor flag for user: %v. Reason: %v\n", uid, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return checkUserUpdated(result, uid)
+}
+
+// returns NotFound if update did not touch any row
+func checkUserUpdated(result sql.Result, uid uuid.UUID) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Unable to get affected rows for user: %v. Reason: %v\n", uid, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	if rows == 0 {
+		return apperrors.NewNotFound("uid", uid.String())
+	}
+
+	return nil
+}
+
+// loginKey is normalized form of login that is unique among users
+func loginKey(login string) (string, error) {
+	key, err := normalize.LoginKey(login)
+	if err != nil {
+		return "", apperrors.NewBadRequest(err.Error()).WithCode(apperrors.InvalidLogin)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateLogin(test *testing.T) {
+	uid, _ := uuid.NewRandom()
+	reservedUntil := time.Now().Add(time.Hour)
+	limit := models.LoginChangeLimit{Changes: 3, Since: time.Now().Add(-time.Hour)}
+
+	newRepository := func(test *testing.T) (models.UserRepository, sqlmock.Sqlmock) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(test, err)
+		test.Cleanup(func() { db.Close() })
+
+		return NewUserRepository(sqlx.NewDb(db, "postgres")), mock
+	}
+
+	// changes are counted in transaction after user row is locked, so concurrent changes wait for each other
+	expectLockAndCount := func(mock sqlmock.Sqlmock, changes int) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM users WHERE uid=$1 FOR UPDATE")).
+			WithArgs(uid).
+			WillReturnRows(sqlmock.NewRows([]string{"uid", "login", "normalized_login"}).AddRow(uid, "alice", "alice"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM login_changes WHERE uid=$1 AND changed_at > $2")).
+			WithArgs(uid, limit.Since).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(changes))
+	}
+
+	test.Run("Limit reached", func(test *testing.T) {
+		repository, mock := newRepository(test)
+
+		expectLockAndCount(mock, limit.Changes)
+		mock.ExpectRollback()
+
+		user, err := repository.UpdateLogin(context.Background(), uid, "bob", reservedUntil, limit)
+
+		assert.Nil(test, user)
+		assert.Equal(test, http.StatusTooManyRequests, apperrors.Status(err))
+		assert.NoError(test, mock.ExpectationsWereMet())
+	})
+
+	test.Run("Change is recorded in same transaction", func(test *testing.T) {
+		repository, mock := newRepository(test)
+
+		expectLockAndCount(mock, limit.Changes-1)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM login_history")).
+			WithArgs("bob", uid).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO login_changes")).
+			WithArgs(uid, "bob").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO login_history")).
+			WithArgs(uid, reservedUntil, "bob").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM login_history")).
+			WithArgs(uid, "bob").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("UPDATE users SET login=$1")).
+			WithArgs("bob", "bob", uid).
+			WillReturnRows(sqlmock.NewRows([]string{"uid", "login", "normalized_login"}).AddRow(uid, "bob", "bob"))
+		mock.ExpectCommit()
+
+		user, err := repository.UpdateLogin(context.Background(), uid, "bob", reservedUntil, limit)
+
+		assert.NoError(test, err)
+		assert.Equal(test, "bob", user.Login)
+		assert.NoError(test, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreate(test *testing.T) {
+	test.Run("Database error", func(test *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(test, err)
+		defer db.Close()
+
+		repository := NewUserRepository(sqlx.NewDb(db, "postgres"))
+
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO users")).
+			WithArgs("alice", "alice", "password").
+			WillReturnError(errors.New("connection reset"))
+
+		err = repository.Create(context.Background(), &models.User{Login: "alice", Password: "password"})
+
+		assert.Equal(test, http.StatusInternalServerError, apperrors.Status(err))
+		assert.NoError(test, mock.ExpectationsWereMet())
+	})
+}
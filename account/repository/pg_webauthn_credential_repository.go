@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type pgWebAuthnCredentialRepository struct {
+	DB *sqlx.DB
+}
+
+// factory for initializing webauthn credential repository
+func NewWebAuthnCredentialRepository(db *sqlx.DB) models.WebAuthnCredentialRepository {
+	return &pgWebAuthnCredentialRepository{
+		DB: db,
+	}
+}
+
+// create credential record in database
+func (r *pgWebAuthnCredentialRepository) Create(ctx context.Context, credential *models.WebAuthnCredential) error {
+	query := `
+		INSERT INTO webauthn_credentials (id, uid, name, public_key, sign_count)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at;
+	`
+
+	if err := r.DB.GetContext(
+		ctx,
+		&credential.CreatedAt,
+		query,
+		credential.ID,
+		credential.UserID,
+		credential.Name,
+		credential.PublicKey,
+		credential.SignCount,
+	); err != nil {
+
+		if err, ok := err.(*pq.Error); ok && err.Code.Name() == "unique_violation" {
+			return apperrors.NewConflict("credential", credential.ID)
+		}
+
+		log.Printf("Could not create webauthn credential for user: %v. Reason: %v\n", credential.UserID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
+
+// fetch credential by its id
+func (r *pgWebAuthnCredentialRepository) FindByID(ctx context.Context, credentialID string) (*models.WebAuthnCredential, error) {
+	credential := &models.WebAuthnCredential{}
+
+	query := "SELECT * FROM webauthn_credentials WHERE id=$1"
+
+	if err := r.DB.GetContext(ctx, credential, query, credentialID); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Unable to get webauthn credential: %v. Reason: %v\n", credentialID, err)
+			return nil, apperrors.NewInternal().Wrap(err)
+		}
+
+		return nil, apperrors.NewNotFound("credential", credentialID)
+	}
+
+	return credential, nil
+}
+
+// fetch credentials of user
+func (r *pgWebAuthnCredentialRepository) FindByUserID(ctx context.Context, uid uuid.UUID) ([]*models.WebAuthnCredential, error) {
+	credentials := []*models.WebAuthnCredential{}
+
+	query := "SELECT * FROM webauthn_credentials WHERE uid=$1 ORDER BY created_at"
+
+	if err := r.DB.SelectContext(ctx, &credentials, query, uid); err != nil {
+		log.Printf("Unable to get webauthn credentials of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	return credentials, nil
+}
+
+// store signature counter and time of use
+func (r *pgWebAuthnCredentialRepository) UpdateSignCount(
+	ctx context.Context,
+	credentialID string,
+	signCount uint32,
+	lastUsedAt time.Time,
+) error {
+
+	query := "UPDATE webauthn_credentials SET sign_count=$1, last_used_at=$2 WHERE id=$3"
+
+	if _, err := r.DB.ExecContext(ctx, query, signCount, lastUsedAt, credentialID); err != nil {
+		log.Printf("Unable to update webauthn credential: %v. Reason: %v\n", credentialID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
+
+// delete credential of user
+func (r *pgWebAuthnCredentialRepository) Delete(ctx context.Context, uid uuid.UUID, credentialID string) error {
+	query := "DELETE FROM webauthn_credentials WHERE uid=$1 AND id=$2"
+
+	result, err := r.DB.ExecContext(ctx, query, uid, credentialID)
+	if err != nil {
+		log.Printf("Unable to delete webauthn credential: %v of user: %v. Reason: %v\n", credentialID, uid, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return apperrors.NewNotFound("credential", credentialID)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type redisAuthorizationCodeRepository struct {
+	Redis *redis.Client
+}
+
+// factory for initializing authorization code repository
+func NewAuthorizationCodeRepository(redisClient *redis.Client) models.AuthorizationCodeRepository {
+	return &redisAuthorizationCodeRepository{
+		Redis: redisClient,
+	}
+}
+
+func authorizationCodeKey(code string) string {
+	return fmt.Sprintf("oauth_code.%s", code)
+}
+
+// stores a code with an expiry time
+func (r *redisAuthorizationCodeRepository) Save(
+	ctx context.Context,
+	code *models.AuthorizationCode,
+	expiresIn time.Duration,
+) error {
+
+	value, err := json.Marshal(code)
+	if err != nil {
+		log.Printf("Could not encode authorization code for client: %v. Error: %v\n", code.ClientID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	if err := r.Redis.Set(ctx, authorizationCodeKey(code.Code), value, expiresIn).Err(); err != nil {
+		log.Printf("Could not SET authorization code to redis for client: %v. Error: %v\n", code.ClientID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
+
+// fetch and delete code, only the caller that deleted the key gets the code
+func (r *redisAuthorizationCodeRepository) Consume(ctx context.Context, code string) (*models.AuthorizationCode, error) {
+	key := authorizationCodeKey(code)
+
+	value, err := r.Redis.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, apperrors.NewNotFound("code", code)
+	}
+
+	if err != nil {
+		log.Printf("Could not GET authorization code from redis. Error: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	deleted, err := r.Redis.Del(ctx, key).Result()
+	if err != nil {
+		log.Printf("Could not delete authorization code from redis. Error: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	if deleted < 1 {
+		return nil, apperrors.NewNotFound("code", code)
+	}
+
+	authorizationCode := &models.AuthorizationCode{}
+	if err := json.Unmarshal(value, authorizationCode); err != nil {
+		log.Printf("Could not decode authorization code. Error: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	authorizationCode.Code = code
+
+	return authorizationCode, nil
+}
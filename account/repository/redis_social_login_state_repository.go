@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+type redisSocialLoginStateRepository struct {
+	Redis *redis.Client
+}
+
+// factory for initializing social login state repository
+func NewSocialLoginStateRepository(redisClient *redis.Client) models.SocialLoginStateRepository {
+	return &redisSocialLoginStateRepository{
+		Redis: redisClient,
+	}
+}
+
+func socialLoginStateKey(state string) string {
+	return fmt.Sprintf("social_state.%s", state)
+}
+
+func socialSigninCodeKey(code string) string {
+	return fmt.Sprintf("social_signin.%s", code)
+}
+
+// stores a state with an expiry time
+func (r *redisSocialLoginStateRepository) Save(
+	ctx context.Context,
+	state *models.SocialLoginState,
+	expiresIn time.Duration,
+) error {
+
+	value, err := json.Marshal(state)
+	if err != nil {
+		log.Printf("Could not encode %v login state. Error: %v\n", state.Provider, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	if err := r.Redis.Set(ctx, socialLoginStateKey(state.State), value, expiresIn).Err(); err != nil {
+		log.Printf("Could not SET %v login state to redis. Error: %v\n", state.Provider, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
+
+// fetch and delete state, only the caller that deleted the key gets the state
+func (r *redisSocialLoginStateRepository) Consume(ctx context.Context, state string) (*models.SocialLoginState, error) {
+	key := socialLoginStateKey(state)
+
+	value, err := r.Redis.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, apperrors.NewAuthorization("Login state is invalid or expired")
+	}
+
+	if err != nil {
+		log.Printf("Could not GET login state from redis. Error: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	deleted, err := r.Redis.Del(ctx, key).Result()
+	if err != nil {
+		log.Printf("Could not delete login state from redis. Error: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	if deleted < 1 {
+		return nil, apperrors.NewAuthorization("Login state is invalid or expired")
+	}
+
+	loginState := &models.SocialLoginState{}
+	if err := json.Unmarshal(value, loginState); err != nil {
+		log.Printf("Could not decode login state. Error: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	loginState.State = state
+
+	return loginState, nil
+}
+
+// stores signin code of user with an expiry time
+func (r *redisSocialLoginStateRepository) SaveSigninCode(
+	ctx context.Context,
+	code string,
+	userID uuid.UUID,
+	expiresIn time.Duration,
+) error {
+
+	if err := r.Redis.Set(ctx, socialSigninCodeKey(code), userID.String(), expiresIn).Err(); err != nil {
+		log.Printf("Could not SET signin code of user %v to redis. Error: %v\n", userID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
+
+// fetch and delete signin code, only the caller that deleted the key gets the user
+func (r *redisSocialLoginStateRepository) ConsumeSigninCode(ctx context.Context, code string) (uuid.UUID, error) {
+	key := socialSigninCodeKey(code)
+
+	value, err := r.Redis.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return uuid.Nil, apperrors.NewAuthorization("Signin code is invalid or expired")
+	}
+
+	if err != nil {
+		log.Printf("Could not GET signin code from redis. Error: %v\n", err)
+		return uuid.Nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	deleted, err := r.Redis.Del(ctx, key).Result()
+	if err != nil {
+		log.Printf("Could not delete signin code from redis. Error: %v\n", err)
+		return uuid.Nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	if deleted < 1 {
+		return uuid.Nil, apperrors.NewAuthorization("Signin code is invalid or expired")
+	}
+
+	userID, err := uuid.Parse(value)
+	if err != nil {
+		log.Printf("Could not parse user of signin code. Error: %v\n", err)
+		return uuid.Nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	return userID, nil
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"memorize/models"
 	"memorize/models/apperrors"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -33,7 +34,7 @@ func (r *redisTokenRepository) SetRefreshToken(
 	key := fmt.Sprintf("%s.%s", userID, tokenID)
 	if err := r.Redis.Set(ctx, key, 0, expiresIn).Err(); err != nil {
 		log.Printf("Could not SET refresh token to redis for userID/tokenID: %s/%s: %v\n", userID, tokenID, err)
-		return apperrors.NewInternal()
+		return apperrors.NewInternal().Wrap(err)
 	}
 
 	return nil
@@ -48,12 +49,12 @@ func (r *redisTokenRepository) DeleteRefreshToken(ctx context.Context, userID st
 
 	if err := result.Err(); err != nil {
 		log.Printf("Could not delete refresh token to redis for userID/tokenID: %s/%s: %v\n", userID, tokenID, err)
-		return apperrors.NewInternal()
+		return apperrors.NewInternal().Wrap(err)
 	}
 
 	if result.Val() < 1 {
 		log.Printf("Refresh token to redis for userID/tokenID %s/%s doesnot exist\n", userID, tokenID)
-		return apperrors.NewAuthorization("Invalid refresh token")
+		return apperrors.NewAuthorization("Invalid refresh token").WithCode(apperrors.TokenReused)
 	}
 
 	return nil
@@ -79,8 +80,88 @@ func (r *redisTokenRepository) DeleteUserRefreshTokens(ctx context.Context, user
 	}
 
 	if failCount > 0 {
-		return apperrors.NewInternal()
+		return apperrors.NewInternal().Wrap(fmt.Errorf("failed to delete %d refresh tokens", failCount))
 	}
 
 	return nil
 }
+
+// ListRefreshTokens returns ids and remaining lifetime of users refresh tokens
+func (r *redisTokenRepository) ListRefreshTokens(ctx context.Context, userID string) ([]*models.Session, error) {
+	prefix := fmt.Sprintf("%s.", userID)
+
+	interator := r.Redis.Scan(ctx, 0, prefix+"*", 5).Iterator()
+	sessions := []*models.Session{}
+
+	for interator.Next(ctx) {
+		expiresIn, err := r.Redis.TTL(ctx, interator.Val()).Result()
+		if err != nil {
+			log.Printf("Failed to get TTL of refresh token: %s. Error: %v\n", interator.Val(), err)
+			return nil, apperrors.NewInternal().Wrap(err)
+		}
+
+		sessions = append(sessions, &models.Session{
+			ID:        strings.TrimPrefix(interator.Val(), prefix),
+			ExpiresIn: int64(expiresIn / time.Second),
+		})
+	}
+
+	if err := interator.Err(); err != nil {
+		log.Printf("Failed to list refresh tokens for userID: %s. Error: %v\n", userID, err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	return sessions, nil
+}
+
+// SetMagicLink stores id of a signin link until it expires
+func (r *redisTokenRepository) SetMagicLink(ctx context.Context, linkID string, expiresIn time.Duration) error {
+	key := fmt.Sprintf("magic_link.%s", linkID)
+	if err := r.Redis.Set(ctx, key, 0, expiresIn).Err(); err != nil {
+		log.Printf("Could not SET magic link to redis for linkID: %s: %v\n", linkID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
+
+// DeleteMagicLink deletes signin link, fails if it was used or expired
+func (r *redisTokenRepository) DeleteMagicLink(ctx context.Context, linkID string) error {
+	key := fmt.Sprintf("magic_link.%s", linkID)
+
+	result := r.Redis.Del(ctx, key)
+
+	if err := result.Err(); err != nil {
+		log.Printf("Could not delete magic link from redis for linkID: %s: %v\n", linkID, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	if result.Val() < 1 {
+		log.Printf("Magic link in redis for linkID %s doesnot exist\n", linkID)
+		return apperrors.NewAuthorization("Signin link is invalid or expired").WithCode(apperrors.SigninLinkInvalid)
+	}
+
+	return nil
+}
+
+// window starts with first attempt, INCR and PEXPIRE run atomically so counter cannot outlive it
+var incrementAttempts = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// IncrementAttempts counts attempts in fixed window started by first attempt
+func (r *redisTokenRepository) IncrementAttempts(ctx context.Context, key string, window time.Duration) (int64, error) {
+	key = fmt.Sprintf("attempts.%s", key)
+
+	count, err := incrementAttempts.Run(ctx, r.Redis, []string{key}, window.Milliseconds()).Int64()
+	if err != nil {
+		log.Printf("Could not count attempts in redis for key: %s: %v\n", key, err)
+		return 0, apperrors.NewInternal().Wrap(err)
+	}
+
+	return count, nil
+}
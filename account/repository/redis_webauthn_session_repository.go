@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type redisWebAuthnSessionRepository struct {
+	Redis *redis.Client
+}
+
+// factory for initializing webauthn session repository
+func NewWebAuthnSessionRepository(redisClient *redis.Client) models.WebAuthnSessionRepository {
+	return &redisWebAuthnSessionRepository{
+		Redis: redisClient,
+	}
+}
+
+func webAuthnSessionKey(challenge []byte) string {
+	return fmt.Sprintf("webauthn_challenge.%s", base64.RawURLEncoding.EncodeToString(challenge))
+}
+
+// stores a session with an expiry time
+func (r *redisWebAuthnSessionRepository) Save(
+	ctx context.Context,
+	session *models.WebAuthnSession,
+	expiresIn time.Duration,
+) error {
+
+	value, err := json.Marshal(session)
+	if err != nil {
+		log.Printf("Could not encode webauthn %v session. Error: %v\n", session.Ceremony, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	if err := r.Redis.Set(ctx, webAuthnSessionKey(session.Challenge), value, expiresIn).Err(); err != nil {
+		log.Printf("Could not SET webauthn %v session to redis. Error: %v\n", session.Ceremony, err)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return nil
+}
+
+// fetch and delete session, only the caller that deleted the key gets the session
+func (r *redisWebAuthnSessionRepository) Consume(ctx context.Context, challenge []byte) (*models.WebAuthnSession, error) {
+	key := webAuthnSessionKey(challenge)
+
+	value, err := r.Redis.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, apperrors.NewAuthorization("Challenge is invalid or expired")
+	}
+
+	if err != nil {
+		log.Printf("Could not GET webauthn session from redis. Error: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	deleted, err := r.Redis.Del(ctx, key).Result()
+	if err != nil {
+		log.Printf("Could not delete webauthn session from redis. Error: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	if deleted < 1 {
+		return nil, apperrors.NewAuthorization("Challenge is invalid or expired")
+	}
+
+	session := &models.WebAuthnSession{}
+	if err := json.Unmarshal(value, session); err != nil {
+		log.Printf("Could not decode webauthn session. Error: %v\n", err)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	session.Challenge = challenge
+
+	return session, nil
+}
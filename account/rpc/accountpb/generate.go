@@ -0,0 +1,4 @@
+// Package accountpb holds generated protobuf and gRPC code of account API
+package accountpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative account.proto
@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/rpc/accountpb"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type contextKey string
+
+const (
+	userKey    contextKey = "user"
+	scopesKey  contextKey = "scopes"
+	serviceKey contextKey = "service"
+)
+
+// methods callable without authorization metadata
+var publicMethods = map[string]bool{
+	accountpb.TokenService_ValidateAccessToken_FullMethodName: true,
+}
+
+// methods of backend services, callable only with client credentials tokens issued for the service audience
+var serviceMethods = map[string]bool{
+	accountpb.UserService_GetUser_FullMethodName:  true,
+	accountpb.UserService_GetUsers_FullMethodName: true,
+}
+
+// methods callable with user tokens, with scope personal access tokens need for them, same as for http routes
+var methodScopes = map[string]string{
+	accountpb.TokenService_Signout_FullMethodName: models.ScopeSessions,
+}
+
+// Auth checks the authorization metadata of every method that is not public
+// service methods need a service token issued for audience, like middleware.AuthService,
+// and set the principal to the context
+// other methods need a user token, like middleware.AuthUser, and set the user to the context,
+// personal access tokens are accepted when p is not nil and are rejected for methods that need scope they do not have
+func Auth(s models.TokenService, p models.PersonalAccessTokenService, audience string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		token, err := bearerToken(ctx)
+		if err != nil {
+			return nil, statusError(err)
+		}
+
+		if serviceMethods[info.FullMethod] {
+			principal, err := s.ValidateServiceToken(token, audience)
+			if err != nil {
+				return nil, statusError(serviceTokenError(s, token))
+			}
+
+			return handler(context.WithValue(ctx, serviceKey, principal), req)
+		}
+
+		if _, ok := methodScopes[info.FullMethod]; !ok {
+			return nil, statusError(apperrors.NewForbidden("Method cannot be called with user token"))
+		}
+
+		if p != nil && strings.HasPrefix(token, models.PersonalAccessTokenPrefix) {
+			user, scopes, err := p.Validate(ctx, token)
+			if err != nil {
+				return nil, statusError(err)
+			}
+
+			if scope, ok := methodScopes[info.FullMethod]; ok && !hasScope(scopes, scope) {
+				return nil, statusError(apperrors.NewForbidden(fmt.Sprintf("Token does not have %v scope", scope)).WithCode(apperrors.InsufficientScope))
+			}
+
+			ctx = context.WithValue(ctx, userKey, user)
+			ctx = context.WithValue(ctx, scopesKey, scopes)
+
+			return handler(ctx, req)
+		}
+
+		user, err := s.ValidateAccessToken(token)
+		if err != nil {
+			return nil, statusError(apperrors.NewAuthorization("Provided token is invalid").WithCode(apperrors.InvalidToken))
+		}
+
+		return handler(context.WithValue(ctx, userKey, user), req)
+	}
+}
+
+// user tokens are valid but not allowed, other tokens are invalid
+func serviceTokenError(s models.TokenService, token string) error {
+	if strings.HasPrefix(token, models.PersonalAccessTokenPrefix) {
+		return apperrors.NewForbidden("Method needs service token")
+	}
+
+	if _, err := s.ValidateAccessToken(token); err == nil {
+		return apperrors.NewForbidden("Method needs service token")
+	}
+
+	return apperrors.NewAuthorization("Provided token is invalid").WithCode(apperrors.InvalidToken)
+}
+
+// user set by Auth
+func userFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(userKey).(*models.User)
+	return user, ok
+}
+
+// reads token from authorization metadata
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, value := range md.Get("authorization") {
+		if token := strings.TrimPrefix(value, "Bearer "); token != value && token != "" {
+			return token, nil
+		}
+	}
+
+	return "", apperrors.NewAuthorization("Must provide authorization metadata with format `Bearer {token}`").WithCode(apperrors.MissingToken)
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, granted := range scopes {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
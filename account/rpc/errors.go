@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"log"
+	"memorize/models/apperrors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Code maps application error type to gRPC status code
+func Code(t apperrors.Type) codes.Code {
+	switch t {
+	case apperrors.Authorization:
+		return codes.Unauthenticated
+	case apperrors.BadRequest:
+		return codes.InvalidArgument
+	case apperrors.Conflict:
+		return codes.AlreadyExists
+	case apperrors.Forbidden:
+		return codes.PermissionDenied
+	case apperrors.Internal:
+		return codes.Internal
+	case apperrors.NotFound:
+		return codes.NotFound
+	case apperrors.PayloadTooLarge:
+		return codes.ResourceExhausted
+	case apperrors.PreconditionFailed:
+		return codes.Aborted
+	case apperrors.PreconditionRequired:
+		return codes.FailedPrecondition
+	case apperrors.ServiceUnavailable:
+		return codes.Unavailable
+	case apperrors.TooManyRequests:
+		return codes.ResourceExhausted
+	case apperrors.UnSupportedMediaType:
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
+
+// statusError converts error of service layer to gRPC status error
+// errors that are not application errors become internal ones, so details are not leaked
+func statusError(err error) error {
+	e := apperrors.From(err)
+
+	if e.IncidentID != "" {
+		log.Printf("Incident %v: %v\n", e.IncidentID, e)
+		return status.Errorf(codes.Internal, "%v Incident id: %v", e.Message, e.IncidentID)
+	}
+
+	return status.Error(Code(e.Type), e.Message)
+}
@@ -0,0 +1,34 @@
+// Package rpc exposes account and token operations over gRPC
+package rpc
+
+import (
+	"memorize/models"
+	"memorize/rpc/accountpb"
+
+	"google.golang.org/grpc"
+)
+
+// hold services that will eventually be injected into gRPC server on initialization
+type Config struct {
+	UserService                models.UserService
+	TokenService               models.TokenService
+	PersonalAccessTokenService models.PersonalAccessTokenService
+	// audience of service tokens that can call UserService
+	ServiceAudience string
+}
+
+// initializes gRPC server with required injected services
+func NewServer(config *Config) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(Auth(config.TokenService, config.PersonalAccessTokenService, config.ServiceAudience)),
+	)
+
+	accountpb.RegisterUserServiceServer(server, &userServer{
+		UserService: config.UserService,
+	})
+	accountpb.RegisterTokenServiceServer(server, &tokenServer{
+		TokenService: config.TokenService,
+	})
+
+	return server
+}
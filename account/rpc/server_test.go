@@ -0,0 +1,189 @@
+package rpc
+
+import (
+	"context"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/rpc/accountpb"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// starts server on in-memory listener and returns connection to it
+func dialServer(test *testing.T, config *Config) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := NewServer(config)
+	go server.Serve(listener)
+	test.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(test, err)
+	test.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestServer(test *testing.T) {
+	uid, _ := uuid.NewRandom()
+	otherUID, _ := uuid.NewRandom()
+
+	user := &models.User{
+		UID:   uid,
+		Login: "alice",
+		Email: "alice@example.com",
+		Name:  "Alice",
+	}
+	other := &models.User{
+		UID:   otherUID,
+		Login: "bob",
+	}
+
+	validToken := "validToken"
+	readToken := models.PersonalAccessTokenPrefix + "read"
+	serviceToken := "serviceToken"
+	audience := "memorize-account"
+
+	mockUserService := new(mocks.MockUserService)
+	mockTokenService := new(mocks.MockTokenService)
+	mockPersonalAccessTokenService := new(mocks.MockPersonalAccessTokenService)
+
+	mockTokenService.On("ValidateAccessToken", validToken).Return(user, nil)
+	mockTokenService.On("ValidateAccessToken", "invalidToken").Return(nil, apperrors.NewAuthorization("Unable to verify user from idToken").WithCode(apperrors.InvalidToken))
+	mockPersonalAccessTokenService.On("Validate", mock.Anything, readToken).Return(user, []string{models.ScopeProfileRead}, nil)
+	mockTokenService.On("ValidateServiceToken", serviceToken, audience).Return(&models.ServicePrincipal{ClientID: "notes", Audience: audience}, nil)
+	mockTokenService.On("ValidateServiceToken", mock.Anything, audience).Return(nil, apperrors.NewAuthorization("Unable to verify service token").WithCode(apperrors.InvalidToken))
+
+	conn := dialServer(test, &Config{
+		UserService:                mockUserService,
+		TokenService:               mockTokenService,
+		PersonalAccessTokenService: mockPersonalAccessTokenService,
+		ServiceAudience:            audience,
+	})
+
+	users := accountpb.NewUserServiceClient(conn)
+	tokens := accountpb.NewTokenServiceClient(conn)
+
+	test.Run("GetUser", func(test *testing.T) {
+		mockUserService.On("GetUser", mock.Anything, otherUID).Return(other, nil)
+
+		response, err := users.GetUser(withToken(serviceToken), &accountpb.GetUserRequest{Uid: otherUID.String()})
+
+		assert.NoError(test, err)
+		assert.Equal(test, otherUID.String(), response.GetUser().GetUid())
+		assert.Equal(test, "bob", response.GetUser().GetLogin())
+	})
+
+	test.Run("GetUser maps application errors", func(test *testing.T) {
+		missing, _ := uuid.NewRandom()
+		mockUserService.On("GetUser", mock.Anything, missing).Return(nil, apperrors.NewNotFound("uid", missing.String()))
+
+		_, err := users.GetUser(withToken(serviceToken), &accountpb.GetUserRequest{Uid: missing.String()})
+
+		assert.Equal(test, codes.NotFound, status.Code(err))
+	})
+
+	test.Run("User token cannot get user", func(test *testing.T) {
+		_, err := users.GetUser(withToken(validToken), &accountpb.GetUserRequest{Uid: otherUID.String()})
+
+		assert.Equal(test, codes.PermissionDenied, status.Code(err))
+
+		_, err = users.GetUsers(withToken(readToken), &accountpb.GetUsersRequest{Uids: []string{otherUID.String()}})
+
+		assert.Equal(test, codes.PermissionDenied, status.Code(err))
+	})
+
+	test.Run("Missing authorization metadata", func(test *testing.T) {
+		_, err := users.GetUser(context.Background(), &accountpb.GetUserRequest{Uid: otherUID.String()})
+
+		assert.Equal(test, codes.Unauthenticated, status.Code(err))
+	})
+
+	test.Run("Invalid token", func(test *testing.T) {
+		_, err := users.GetUser(withToken("invalidToken"), &accountpb.GetUserRequest{Uid: otherUID.String()})
+
+		assert.Equal(test, codes.Unauthenticated, status.Code(err))
+	})
+
+	test.Run("GetUsers", func(test *testing.T) {
+		mockUserService.On("GetUsers", mock.Anything, []uuid.UUID{uid, otherUID}).Return([]*models.User{user, other}, nil)
+
+		response, err := users.GetUsers(withToken(serviceToken), &accountpb.GetUsersRequest{
+			Uids: []string{uid.String(), otherUID.String()},
+		})
+
+		assert.NoError(test, err)
+		assert.Len(test, response.GetUsers(), 2)
+		assert.Equal(test, "alice@example.com", response.GetUsers()[0].GetEmail())
+		assert.Equal(test, "bob", response.GetUsers()[1].GetLogin())
+	})
+
+	test.Run("GetUsers invalid uid", func(test *testing.T) {
+		_, err := users.GetUsers(withToken(serviceToken), &accountpb.GetUsersRequest{Uids: []string{"nope"}})
+
+		assert.Equal(test, codes.InvalidArgument, status.Code(err))
+	})
+
+	test.Run("ValidateAccessToken without authorization metadata", func(test *testing.T) {
+		response, err := tokens.ValidateAccessToken(context.Background(), &accountpb.ValidateAccessTokenRequest{
+			AccessToken: validToken,
+		})
+
+		assert.NoError(test, err)
+		assert.Equal(test, uid.String(), response.GetUser().GetUid())
+
+		_, err = tokens.ValidateAccessToken(context.Background(), &accountpb.ValidateAccessTokenRequest{
+			AccessToken: "invalidToken",
+		})
+
+		assert.Equal(test, codes.Unauthenticated, status.Code(err))
+	})
+
+	test.Run("Signout", func(test *testing.T) {
+		mockTokenService.On("Signout", mock.Anything, uid).Return(nil)
+
+		_, err := tokens.Signout(withToken(validToken), &accountpb.SignoutRequest{})
+
+		assert.NoError(test, err)
+		mockTokenService.AssertCalled(test, "Signout", mock.Anything, uid)
+	})
+
+	test.Run("Personal access token without scope", func(test *testing.T) {
+		_, err := tokens.Signout(withToken(readToken), &accountpb.SignoutRequest{})
+
+		assert.Equal(test, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestCode(test *testing.T) {
+	assert.Equal(test, codes.Unauthenticated, Code(apperrors.Authorization))
+	assert.Equal(test, codes.InvalidArgument, Code(apperrors.BadRequest))
+	assert.Equal(test, codes.AlreadyExists, Code(apperrors.Conflict))
+	assert.Equal(test, codes.PermissionDenied, Code(apperrors.Forbidden))
+	assert.Equal(test, codes.NotFound, Code(apperrors.NotFound))
+	assert.Equal(test, codes.ResourceExhausted, Code(apperrors.TooManyRequests))
+	assert.Equal(test, codes.Unavailable, Code(apperrors.ServiceUnavailable))
+	assert.Equal(test, codes.Internal, Code(apperrors.Type("UNKNOWN")))
+}
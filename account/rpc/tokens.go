@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"context"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/rpc/accountpb"
+)
+
+type tokenServer struct {
+	accountpb.UnimplementedTokenServiceServer
+
+	TokenService models.TokenService
+}
+
+func (s *tokenServer) ValidateAccessToken(
+	ctx context.Context,
+	req *accountpb.ValidateAccessTokenRequest,
+) (*accountpb.ValidateAccessTokenResponse, error) {
+	user, err := s.TokenService.ValidateAccessToken(req.GetAccessToken())
+	if err != nil {
+		return nil, statusError(apperrors.NewAuthorization("Provided token is invalid").WithCode(apperrors.InvalidToken))
+	}
+
+	return &accountpb.ValidateAccessTokenResponse{User: userMessage(user)}, nil
+}
+
+func (s *tokenServer) Signout(ctx context.Context, req *accountpb.SignoutRequest) (*accountpb.SignoutResponse, error) {
+	user, ok := userFromContext(ctx)
+	if !ok {
+		return nil, statusError(apperrors.NewAuthorization("Must provide authorization metadata with format `Bearer {token}`").WithCode(apperrors.MissingToken))
+	}
+
+	if err := s.TokenService.Signout(ctx, user.UID); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &accountpb.SignoutResponse{}, nil
+}
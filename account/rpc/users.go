@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"context"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/rpc/accountpb"
+
+	"github.com/google/uuid"
+)
+
+// most uids resolved by one GetUsers call
+const maxBatchSize = 100
+
+type userServer struct {
+	accountpb.UnimplementedUserServiceServer
+
+	UserService models.UserService
+}
+
+func (s *userServer) GetUser(ctx context.Context, req *accountpb.GetUserRequest) (*accountpb.GetUserResponse, error) {
+	uid, err := uuid.Parse(req.GetUid())
+	if err != nil {
+		return nil, statusError(apperrors.NewNotFound("user", req.GetUid()))
+	}
+
+	user, err := s.UserService.GetUser(ctx, uid)
+	if err != nil {
+		log.Printf("Unable to find user: %v\n%v", uid, err)
+		return nil, statusError(err)
+	}
+
+	return &accountpb.GetUserResponse{User: userMessage(user)}, nil
+}
+
+func (s *userServer) GetUsers(ctx context.Context, req *accountpb.GetUsersRequest) (*accountpb.GetUsersResponse, error) {
+	if len(req.GetUids()) > maxBatchSize {
+		return nil, statusError(apperrors.NewBadRequest("Too many uids, at most 100 can be requested"))
+	}
+
+	uids := make([]uuid.UUID, 0, len(req.GetUids()))
+
+	for _, value := range req.GetUids() {
+		uid, err := uuid.Parse(value)
+		if err != nil {
+			return nil, statusError(apperrors.NewBadRequest("Invalid uid " + value))
+		}
+
+		uids = append(uids, uid)
+	}
+
+	users, err := s.UserService.GetUsers(ctx, uids)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &accountpb.GetUsersResponse{
+		Users: make([]*accountpb.User, 0, len(users)),
+	}
+
+	for _, user := range users {
+		response.Users = append(response.Users, userMessage(user))
+	}
+
+	return response, nil
+}
+
+func userMessage(user *models.User) *accountpb.User {
+	return &accountpb.User{
+		Uid:          user.UID.String(),
+		Login:        user.Login,
+		Email:        user.Email,
+		Name:         user.Name,
+		ImageUrl:     user.ImageURL,
+		Website:      user.Website,
+		SecondFactor: user.SecondFactor,
+	}
+}
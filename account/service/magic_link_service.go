@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/normalize"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// links are requested at most this many times per login and per client address within window
+const (
+	magicLinkAttemptsPerLogin = 3
+	magicLinkAttemptsPerIP    = 10
+	magicLinkAttemptWindow    = 15 * time.Minute
+)
+
+const defaultMagicLinkExpiration = 15 * time.Minute
+
+type magicLinkService struct {
+	UserRepository  models.UserRepository
+	TokenRepository models.TokenRepository
+	Mailer          models.Mailer
+	Secret          []byte
+	LinkURL         string
+	Expiration      time.Duration
+}
+
+// config hold repositories, mailer and link settings injected into magic link service
+type MagicLinkServiceConfig struct {
+	UserRepository  models.UserRepository
+	TokenRepository models.TokenRepository
+	Mailer          models.Mailer
+	// key links are signed with
+	Secret string
+	// page that reads token from query and posts it to verify endpoint
+	LinkURL    string
+	Expiration time.Duration
+}
+
+// factory function for initializing MagicLinkService with its dependencies
+func NewMagicLinkService(config *MagicLinkServiceConfig) models.MagicLinkService {
+	expiration := config.Expiration
+	if expiration <= 0 {
+		expiration = defaultMagicLinkExpiration
+	}
+
+	return &magicLinkService{
+		UserRepository:  config.UserRepository,
+		TokenRepository: config.TokenRepository,
+		Mailer:          config.Mailer,
+		Secret:          []byte(config.Secret),
+		LinkURL:         config.LinkURL,
+		Expiration:      expiration,
+	}
+}
+
+// mail signin link to user
+// nonce is returned even if login does not exist or has no email, so response does not tell
+func (s *magicLinkService) Send(ctx context.Context, login string, clientIP string) (string, error) {
+	if err := s.limit(ctx, "magic_link_ip."+clientIP, magicLinkAttemptsPerIP); err != nil {
+		return "", err
+	}
+
+	// spellings of same login share the limit, invalid logins are limited as typed
+	loginKey, err := normalize.LoginKey(login)
+	if err != nil {
+		loginKey = login
+	}
+
+	if err := s.limit(ctx, "magic_link_login."+loginKey, magicLinkAttemptsPerLogin); err != nil {
+		return "", err
+	}
+
+	nonce, err := randomToken(32)
+	if err != nil {
+		log.Printf("Unable to generate magic link nonce: %v\n", err)
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	user, err := s.UserRepository.FindByLogin(ctx, login)
+	if err != nil {
+		if isType(err, apperrors.NotFound) {
+			return nonce, nil
+		}
+
+		return "", err
+	}
+
+	if user.Disabled || user.Email == "" {
+		log.Printf("Magic link is not sent to user: %v without email or disabled\n", user.UID)
+		return nonce, nil
+	}
+
+	linkID, err := uuid.NewRandom()
+	if err != nil {
+		log.Printf("Unable to generate magic link id: %v\n", err)
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	token, err := generateMagicLinkToken(user.UID, linkID.String(), hashNonce(nonce), s.Secret, s.Expiration)
+	if err != nil {
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	link, err := s.link(token)
+	if err != nil {
+		log.Printf("Unable to build magic link from %v: %v\n", s.LinkURL, err)
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	if err := s.TokenRepository.SetMagicLink(ctx, linkID.String(), s.Expiration); err != nil {
+		return "", err
+	}
+
+	mail := &models.Mail{
+		To:      user.Email,
+		Subject: "Sign in to Memorize",
+		Body: fmt.Sprintf(
+			"Hello %v,\n\nopen this link on the device you requested it from to sign in:\n\n%v\n\n"+
+				"The link works once and expires in %v minutes. If you did not request it, ignore this mail.\n",
+			user.Login, link, int(s.Expiration.Minutes()),
+		),
+	}
+
+	// failure to send is not returned, it would tell that login exists
+	if err := s.Mailer.Send(ctx, mail); err != nil {
+		log.Printf("Unable to send magic link to user: %v: %v\n", user.UID, err)
+	}
+
+	return nonce, nil
+}
+
+// check link token and nonce, link is used up only when nonce matches
+func (s *magicLinkService) Verify(ctx context.Context, token string, nonce string) (*models.User, error) {
+	claims, err := validateMagicLinkToken(token, s.Secret)
+	if err != nil {
+		log.Printf("Unable to validate magic link token: %v\n", err)
+		return nil, apperrors.NewAuthorization("Signin link is invalid or expired").WithCode(apperrors.SigninLinkInvalid)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.NonceHash), []byte(hashNonce(nonce))) != 1 {
+		log.Printf("Magic link: %v opened without nonce of requesting device\n", claims.Id)
+		return nil, apperrors.NewAuthorization("Signin link must be opened on the device it was requested from")
+	}
+
+	uid, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		log.Printf("Magic link: %v has invalid subject: %v\n", claims.Id, claims.Subject)
+		return nil, apperrors.NewAuthorization("Signin link is invalid or expired").WithCode(apperrors.SigninLinkInvalid)
+	}
+
+	if err := s.TokenRepository.DeleteMagicLink(ctx, claims.Id); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepository.FindByID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Disabled {
+		return nil, apperrors.NewAuthorization("Account is disabled").WithCode(apperrors.AccountDisabled)
+	}
+
+	return user, nil
+}
+
+func (s *magicLinkService) limit(ctx context.Context, key string, maxAttempts int64) error {
+	attempts, err := s.TokenRepository.IncrementAttempts(ctx, key, magicLinkAttemptWindow)
+	if err != nil {
+		return err
+	}
+
+	if attempts > maxAttempts {
+		log.Printf("Too many magic link requests for %v\n", key)
+		return apperrors.NewTooManyRequests()
+	}
+
+	return nil
+}
+
+func (s *magicLinkService) link(token string) (string, error) {
+	linkURL, err := url.Parse(s.LinkURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := linkURL.Query()
+	query.Set("token", token)
+	linkURL.RawQuery = query.Encode()
+
+	return linkURL.String(), nil
+}
+
+func hashNonce(nonce string) string {
+	hash := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMagicLinkService(test *testing.T) {
+	uid, _ := uuid.NewRandom()
+	user := &models.User{
+		UID:   uid,
+		Login: "alice",
+		Email: "alice@mail.com",
+	}
+
+	newService := func() (models.MagicLinkService, *mocks.MockUserRepository, *mocks.MockTokenRepository, *mocks.MockMailer) {
+		userRepository := new(mocks.MockUserRepository)
+		tokenRepository := new(mocks.MockTokenRepository)
+		mailer := new(mocks.MockMailer)
+
+		magicLinkService := NewMagicLinkService(&MagicLinkServiceConfig{
+			UserRepository:  userRepository,
+			TokenRepository: tokenRepository,
+			Mailer:          mailer,
+			Secret:          "magic link secret",
+			LinkURL:         "http://localhost/account/signin/link",
+		})
+
+		tokenRepository.On("IncrementAttempts", mock.Anything, mock.AnythingOfType("string"), magicLinkAttemptWindow).Return(int64(1), nil)
+
+		return magicLinkService, userRepository, tokenRepository, mailer
+	}
+
+	// sends link to alice, returns nonce and token from mailed link
+	send := func(test *testing.T, magicLinkService models.MagicLinkService, userRepository *mocks.MockUserRepository, tokenRepository *mocks.MockTokenRepository, mailer *mocks.MockMailer) (string, string) {
+		var mail *models.Mail
+
+		userRepository.On("FindByLogin", mock.Anything, "alice").Return(user, nil)
+		tokenRepository.On("SetMagicLink", mock.Anything, mock.AnythingOfType("string"), defaultMagicLinkExpiration).Return(nil)
+		mailer.
+			On("Send", mock.Anything, mock.AnythingOfType("*models.Mail")).
+			Run(func(args mock.Arguments) {
+				mail = args.Get(1).(*models.Mail)
+			}).
+			Return(nil)
+
+		nonce, err := magicLinkService.Send(context.Background(), "alice", "127.0.0.1")
+		assert.NoError(test, err)
+		assert.NotEmpty(test, nonce)
+		assert.Equal(test, "alice@mail.com", mail.To)
+
+		start := strings.Index(mail.Body, "http://localhost/account/signin/link?")
+		link, err := url.Parse(strings.Fields(mail.Body[start:])[0])
+		assert.NoError(test, err)
+
+		return nonce, link.Query().Get("token")
+	}
+
+	test.Run("Send and verify", func(test *testing.T) {
+		magicLinkService, userRepository, tokenRepository, mailer := newService()
+
+		nonce, token := send(test, magicLinkService, userRepository, tokenRepository, mailer)
+
+		tokenRepository.On("DeleteMagicLink", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+		userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+
+		signedIn, err := magicLinkService.Verify(context.Background(), token, nonce)
+		assert.NoError(test, err)
+		assert.Equal(test, user, signedIn)
+
+		linkID := tokenRepository.Calls[len(tokenRepository.Calls)-1].Arguments.String(1)
+		tokenRepository.AssertCalled(test, "SetMagicLink", mock.Anything, linkID, defaultMagicLinkExpiration)
+	})
+
+	test.Run("Link used twice", func(test *testing.T) {
+		magicLinkService, userRepository, tokenRepository, mailer := newService()
+
+		nonce, token := send(test, magicLinkService, userRepository, tokenRepository, mailer)
+
+		tokenRepository.
+			On("DeleteMagicLink", mock.Anything, mock.AnythingOfType("string")).
+			Return(apperrors.NewAuthorization("Signin link is invalid or expired").WithCode(apperrors.SigninLinkInvalid))
+
+		signedIn, err := magicLinkService.Verify(context.Background(), token, nonce)
+		assert.Nil(test, signedIn)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+		userRepository.AssertNotCalled(test, "FindByID", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Link opened on other device", func(test *testing.T) {
+		magicLinkService, userRepository, tokenRepository, mailer := newService()
+
+		_, token := send(test, magicLinkService, userRepository, tokenRepository, mailer)
+
+		signedIn, err := magicLinkService.Verify(context.Background(), token, "other device nonce")
+		assert.Nil(test, signedIn)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+		// link stays usable on the requesting device
+		tokenRepository.AssertNotCalled(test, "DeleteMagicLink", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Forged link", func(test *testing.T) {
+		magicLinkService, _, tokenRepository, _ := newService()
+
+		token, _ := generateMagicLinkToken(uid, "forged", hashNonce("nonce"), []byte("other secret"), time.Minute)
+
+		signedIn, err := magicLinkService.Verify(context.Background(), token, "nonce")
+		assert.Nil(test, signedIn)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+		tokenRepository.AssertNotCalled(test, "DeleteMagicLink", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Expired link", func(test *testing.T) {
+		magicLinkService, _, tokenRepository, _ := newService()
+
+		token, _ := generateMagicLinkToken(uid, "expired", hashNonce("nonce"), []byte("magic link secret"), -time.Minute)
+
+		signedIn, err := magicLinkService.Verify(context.Background(), token, "nonce")
+		assert.Nil(test, signedIn)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+		tokenRepository.AssertNotCalled(test, "DeleteMagicLink", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Unknown login gets nonce without mail", func(test *testing.T) {
+		magicLinkService, userRepository, _, mailer := newService()
+
+		userRepository.On("FindByLogin", mock.Anything, "nobody").Return(nil, apperrors.NewNotFound("login", "nobody"))
+
+		nonce, err := magicLinkService.Send(context.Background(), "nobody", "127.0.0.1")
+		assert.NoError(test, err)
+		assert.NotEmpty(test, nonce)
+		mailer.AssertNotCalled(test, "Send", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Mail failure gets nonce", func(test *testing.T) {
+		magicLinkService, userRepository, tokenRepository, mailer := newService()
+
+		userRepository.On("FindByLogin", mock.Anything, "alice").Return(user, nil)
+		tokenRepository.On("SetMagicLink", mock.Anything, mock.AnythingOfType("string"), defaultMagicLinkExpiration).Return(nil)
+		mailer.On("Send", mock.Anything, mock.AnythingOfType("*models.Mail")).Return(apperrors.NewInternal())
+
+		nonce, err := magicLinkService.Send(context.Background(), "alice", "127.0.0.1")
+		assert.NoError(test, err)
+		assert.NotEmpty(test, nonce)
+	})
+
+	test.Run("Rate limited by normalized login", func(test *testing.T) {
+		userRepository := new(mocks.MockUserRepository)
+		tokenRepository := new(mocks.MockTokenRepository)
+		mailer := new(mocks.MockMailer)
+
+		magicLinkService := NewMagicLinkService(&MagicLinkServiceConfig{
+			UserRepository:  userRepository,
+			TokenRepository: tokenRepository,
+			Mailer:          mailer,
+			Secret:          "magic link secret",
+			LinkURL:         "http://localhost/account/signin/link",
+		})
+
+		tokenRepository.On("IncrementAttempts", mock.Anything, "magic_link_ip.127.0.0.1", magicLinkAttemptWindow).Return(int64(1), nil)
+		tokenRepository.On("IncrementAttempts", mock.Anything, "magic_link_login.alice", magicLinkAttemptWindow).Return(int64(magicLinkAttemptsPerLogin+1), nil)
+
+		nonce, err := magicLinkService.Send(context.Background(), "ＡＬＩＣＥ", "127.0.0.1")
+		assert.Empty(test, nonce)
+		assert.Equal(test, apperrors.TooManyRequests, err.(*apperrors.Error).Type)
+		userRepository.AssertNotCalled(test, "FindByLogin", mock.Anything, mock.Anything)
+		mailer.AssertNotCalled(test, "Send", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,600 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// authorization codes are exchanged right after redirect
+const authorizationCodeExpiration = time.Minute
+
+// scopes this provider understands
+var supportedScopes = map[string]bool{
+	"openid":  true,
+	"profile": true,
+	"email":   true,
+}
+
+type oauthService struct {
+	OAuthClientRepository       models.OAuthClientRepository
+	AuthorizationCodeRepository models.AuthorizationCodeRepository
+	UserRepository              models.UserRepository
+	TokenRepository             models.TokenRepository
+	TokenService                models.TokenService
+	Issuer                      string
+}
+
+// config hold repositories and services injected into oauth service
+type OAuthServiceConfig struct {
+	OAuthClientRepository       models.OAuthClientRepository
+	AuthorizationCodeRepository models.AuthorizationCodeRepository
+	UserRepository              models.UserRepository
+	TokenRepository             models.TokenRepository
+	TokenService                models.TokenService
+	Issuer                      string
+}
+
+// factory function for initializing OAuthService with its dependencies
+func NewOAuthService(config *OAuthServiceConfig) models.OAuthService {
+	return &oauthService{
+		OAuthClientRepository:       config.OAuthClientRepository,
+		AuthorizationCodeRepository: config.AuthorizationCodeRepository,
+		UserRepository:              config.UserRepository,
+		TokenRepository:             config.TokenRepository,
+		TokenService:                config.TokenService,
+		Issuer:                      config.Issuer,
+	}
+}
+
+// register client, secret is returned only once and stored hashed
+func (s *oauthService) RegisterClient(
+	ctx context.Context,
+	client *models.OAuthClient,
+	confidential bool,
+) (string, error) {
+
+	if client.Name == "" {
+		return "", apperrors.NewBadRequest("client name is required")
+	}
+
+	if client.Service {
+		if err := validateServiceClient(client, confidential); err != nil {
+			return "", err
+		}
+	} else if err := validateRedirectURIs(client.RedirectURIs); err != nil {
+		return "", err
+	}
+
+	client.ClientID = uuid.New().String()
+
+	var secret string
+
+	if confidential {
+		var err error
+
+		secret, err = randomToken(32)
+		if err != nil {
+			log.Printf("Unable to generate secret for client: %v\n", client.Name)
+			return "", apperrors.NewInternal().Wrap(err)
+		}
+
+		client.SecretHash, err = HashPassword(secret)
+		if err != nil {
+			log.Printf("Unable to hash secret for client: %v\n", client.Name)
+			return "", apperrors.NewInternal().Wrap(err)
+		}
+	}
+
+	if err := s.OAuthClientRepository.Create(ctx, client); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// service clients only use client credentials, so they need a secret and audiences but no redirect uris
+func validateServiceClient(client *models.OAuthClient, confidential bool) error {
+	if !confidential {
+		return apperrors.NewBadRequest("service clients must have a secret")
+	}
+
+	if len(client.RedirectURIs) != 0 {
+		return apperrors.NewBadRequest("service clients cannot have redirect uris")
+	}
+
+	if len(client.Audiences) == 0 {
+		return apperrors.NewBadRequest("at least one audience is required")
+	}
+
+	return nil
+}
+
+func validateRedirectURIs(redirectURIs []string) error {
+	if len(redirectURIs) == 0 {
+		return apperrors.NewBadRequest("at least one redirect uri is required")
+	}
+
+	for _, redirectURI := range redirectURIs {
+		parsedURI, err := url.Parse(redirectURI)
+		if err != nil || !parsedURI.IsAbs() || parsedURI.Fragment != "" {
+			return apperrors.NewBadRequest(fmt.Sprintf("invalid redirect uri: %v", redirectURI))
+		}
+	}
+
+	return nil
+}
+
+// list registered clients
+func (s *oauthService) ListClients(ctx context.Context) ([]*models.OAuthClient, error) {
+	return s.OAuthClientRepository.FindAll(ctx)
+}
+
+// delete registered client
+func (s *oauthService) DeleteClient(ctx context.Context, clientID string) error {
+	return s.OAuthClientRepository.Delete(ctx, clientID)
+}
+
+// check authorization request, fills in redirect uri if client has only one
+// errors about client and redirect uri must not be redirected to the client
+func (s *oauthService) ValidateAuthorizationRequest(
+	ctx context.Context,
+	request *models.AuthorizationRequest,
+) error {
+
+	client, err := s.OAuthClientRepository.FindByID(ctx, request.ClientID)
+	if err != nil {
+		return apperrors.NewOAuthError(apperrors.InvalidRequest, "Unknown client")
+	}
+
+	if client.Service {
+		return apperrors.NewOAuthError(apperrors.UnauthorizedClient, "Service clients cannot sign users in")
+	}
+
+	if request.RedirectURI == "" && len(client.RedirectURIs) == 1 {
+		request.RedirectURI = client.RedirectURIs[0]
+	}
+
+	if !contains(client.RedirectURIs, request.RedirectURI) {
+		return apperrors.NewOAuthError(apperrors.InvalidRequest, "Redirect uri is not registered for client")
+	}
+
+	if request.ResponseType != "code" {
+		return apperrors.NewOAuthRedirectError(
+			apperrors.UnsupportedResponseType,
+			"Only code response type is supported",
+			request.RedirectURI,
+		)
+	}
+
+	for _, scope := range strings.Fields(request.Scope) {
+		if !supportedScopes[scope] {
+			return apperrors.NewOAuthRedirectError(
+				apperrors.InvalidScope,
+				fmt.Sprintf("Unsupported scope: %v", scope),
+				request.RedirectURI,
+			)
+		}
+	}
+
+	if request.CodeChallenge == "" && client.Public() {
+		return apperrors.NewOAuthRedirectError(
+			apperrors.InvalidRequest,
+			"Public clients must use PKCE",
+			request.RedirectURI,
+		)
+	}
+
+	if request.CodeChallenge != "" && request.CodeChallengeMethod != "S256" {
+		return apperrors.NewOAuthRedirectError(
+			apperrors.InvalidRequest,
+			"Only S256 code challenge method is supported",
+			request.RedirectURI,
+		)
+	}
+
+	return nil
+}
+
+// issue authorization code to signed in user
+func (s *oauthService) Authorize(
+	ctx context.Context,
+	request *models.AuthorizationRequest,
+	user *models.User,
+) (string, error) {
+
+	if err := s.ValidateAuthorizationRequest(ctx, request); err != nil {
+		return "", err
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		log.Printf("Unable to generate authorization code for user: %v\n", user.UID)
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	authorizationCode := &models.AuthorizationCode{
+		Code:                code,
+		ClientID:            request.ClientID,
+		UserID:              user.UID,
+		RedirectURI:         request.RedirectURI,
+		Scope:               request.Scope,
+		Nonce:               request.Nonce,
+		CodeChallenge:       request.CodeChallenge,
+		CodeChallengeMethod: request.CodeChallengeMethod,
+		AuthTime:            time.Now().Unix(),
+	}
+
+	if err := s.AuthorizationCodeRepository.Save(ctx, authorizationCode, authorizationCodeExpiration); err != nil {
+		return "", err
+	}
+
+	return RedirectURIWithParams(request.RedirectURI, map[string]string{
+		"code":  code,
+		"state": request.State,
+	})
+}
+
+// exchange authorization code, refresh token or client credentials for tokens
+func (s *oauthService) Token(
+	ctx context.Context,
+	request *models.OAuthTokenRequest,
+) (*models.OAuthTokenResponse, error) {
+
+	client, err := s.authenticateClient(ctx, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Service != (request.GrantType == "client_credentials") {
+		return nil, apperrors.NewOAuthError(apperrors.UnauthorizedClient, "Grant type is not allowed for client")
+	}
+
+	switch request.GrantType {
+	case "authorization_code":
+		return s.exchangeCode(ctx, client, request)
+	case "refresh_token":
+		return s.refresh(ctx, client, request)
+	case "client_credentials":
+		return s.clientCredentials(client, request)
+	default:
+		return nil, apperrors.NewOAuthError(apperrors.UnsupportedGrantType, "Unsupported grant type")
+	}
+}
+
+// check client secret, public clients must not send one
+func (s *oauthService) authenticateClient(
+	ctx context.Context,
+	clientID string,
+	secret string,
+) (*models.OAuthClient, error) {
+
+	client, err := s.OAuthClientRepository.FindByID(ctx, clientID)
+	if err != nil {
+		return nil, apperrors.NewOAuthError(apperrors.InvalidClient, "Client authentication failed")
+	}
+
+	if client.Public() {
+		if secret != "" {
+			return nil, apperrors.NewOAuthError(apperrors.InvalidClient, "Client authentication failed")
+		}
+
+		return client, nil
+	}
+
+	match, err := comparePasswords(client.SecretHash, secret)
+	if err != nil || !match {
+		return nil, apperrors.NewOAuthError(apperrors.InvalidClient, "Client authentication failed")
+	}
+
+	return client, nil
+}
+
+func (s *oauthService) exchangeCode(
+	ctx context.Context,
+	client *models.OAuthClient,
+	request *models.OAuthTokenRequest,
+) (*models.OAuthTokenResponse, error) {
+
+	code, err := s.AuthorizationCodeRepository.Consume(ctx, request.Code)
+	if err != nil {
+		return nil, oauthErrorFrom(err, apperrors.InvalidGrant, "Invalid authorization code")
+	}
+
+	if code.ClientID != client.ClientID || code.RedirectURI != request.RedirectURI {
+		return nil, apperrors.NewOAuthError(apperrors.InvalidGrant, "Invalid authorization code")
+	}
+
+	if code.CodeChallenge != "" && !verifyCodeChallenge(code.CodeChallenge, request.CodeVerifier) {
+		return nil, apperrors.NewOAuthError(apperrors.InvalidGrant, "Invalid code verifier")
+	}
+
+	user, err := s.UserRepository.FindByID(ctx, code.UserID)
+	if err != nil {
+		return nil, apperrors.NewOAuthError(apperrors.InvalidGrant, "User not found")
+	}
+
+	grant := &models.ClientGrant{
+		ClientID: client.ClientID,
+		Scope:    code.Scope,
+	}
+
+	tokens, err := s.TokenService.NewPairForClient(ctx, user, grant, "")
+	if err != nil {
+		return nil, oauthErrorFrom(err, apperrors.InvalidGrant, "Unable to issue tokens")
+	}
+
+	response := tokenResponse(tokens)
+	response.Scope = code.Scope
+
+	if contains(grant.Scopes(), "openid") {
+		response.IDToken, err = s.TokenService.NewIDToken(grant.User(user), &models.IDTokenOptions{
+			Issuer:   s.Issuer,
+			Audience: client.ClientID,
+			Nonce:    code.Nonce,
+			AuthTime: code.AuthTime,
+		})
+
+		if err != nil {
+			return nil, apperrors.NewOAuthError(apperrors.ServerError, "Unable to issue id token")
+		}
+	}
+
+	return response, nil
+}
+
+// refresh tokens are redeemed only by the client they were issued to,
+// tokens of the account service itself have no client and are never accepted here
+func (s *oauthService) refresh(
+	ctx context.Context,
+	client *models.OAuthClient,
+	request *models.OAuthTokenRequest,
+) (*models.OAuthTokenResponse, error) {
+
+	refreshToken, err := s.TokenService.ValidateRefreshToken(request.RefreshToken)
+	if err != nil || refreshToken.Grant == nil || refreshToken.Grant.ClientID != client.ClientID {
+		return nil, apperrors.NewOAuthError(apperrors.InvalidGrant, "Invalid refresh token")
+	}
+
+	user, err := s.UserRepository.FindByID(ctx, refreshToken.UserID)
+	if err != nil {
+		return nil, apperrors.NewOAuthError(apperrors.InvalidGrant, "User not found")
+	}
+
+	tokens, err := s.TokenService.NewPairForClient(ctx, user, refreshToken.Grant, refreshToken.ID.String())
+	if err != nil {
+		return nil, oauthErrorFrom(err, apperrors.InvalidGrant, "Invalid refresh token")
+	}
+
+	response := tokenResponse(tokens)
+	response.Scope = refreshToken.Grant.Scope
+
+	return response, nil
+}
+
+// issue access token to service client for one of its audiences
+func (s *oauthService) clientCredentials(
+	client *models.OAuthClient,
+	request *models.OAuthTokenRequest,
+) (*models.OAuthTokenResponse, error) {
+
+	audience := request.Audience
+	if audience == "" && len(client.Audiences) == 1 {
+		audience = client.Audiences[0]
+	}
+
+	if !contains(client.Audiences, audience) {
+		return nil, apperrors.NewOAuthError(apperrors.InvalidTarget, "Audience is not allowed for client")
+	}
+
+	accessToken, err := s.TokenService.NewServiceToken(&models.ServicePrincipal{
+		ClientID: client.ClientID,
+		Audience: audience,
+	}, s.Issuer)
+
+	if err != nil {
+		return nil, apperrors.NewOAuthError(apperrors.ServerError, "Unable to issue access token")
+	}
+
+	return &models.OAuthTokenResponse{
+		AccessToken: accessToken.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   accessToken.ExpiresIn,
+	}, nil
+}
+
+// describe token to confidential client as defined by RFC 7662
+// invalid, expired and revoked tokens are reported as inactive instead of an error
+func (s *oauthService) Introspect(
+	ctx context.Context,
+	request *models.TokenIntrospectionRequest,
+) (*models.TokenIntrospectionResponse, error) {
+
+	client, err := s.authenticateClient(ctx, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	// anyone could act as public client and probe stolen tokens
+	if client.Public() {
+		return nil, apperrors.NewOAuthError(apperrors.UnauthorizedClient, "Public clients cannot introspect tokens")
+	}
+
+	introspect := []func(context.Context, string) (*models.TokenIntrospectionResponse, error){
+		s.introspectAccessToken,
+		s.introspectRefreshToken,
+	}
+
+	// hint only changes which type is tried first
+	if request.TokenTypeHint == "refresh_token" {
+		introspect[0], introspect[1] = introspect[1], introspect[0]
+	}
+
+	for _, describe := range introspect {
+		response, err := describe(ctx, request.Token)
+		if err != nil || response.Active {
+			return response, err
+		}
+	}
+
+	return &models.TokenIntrospectionResponse{Active: false}, nil
+}
+
+func (s *oauthService) introspectAccessToken(
+	ctx context.Context,
+	token string,
+) (*models.TokenIntrospectionResponse, error) {
+
+	user, err := s.TokenService.ValidateAccessToken(token)
+	if err != nil {
+		return &models.TokenIntrospectionResponse{Active: false}, nil
+	}
+
+	issuedAt, expiresAt := tokenLifetime(token)
+
+	return &models.TokenIntrospectionResponse{
+		Active:    true,
+		TokenType: "Bearer",
+		Subject:   user.UID.String(),
+		Username:  user.Login,
+		Issuer:    s.Issuer,
+		ExpiresAt: expiresAt,
+		IssuedAt:  issuedAt,
+	}, nil
+}
+
+// refresh tokens are active only while they are stored and their user can sign in
+func (s *oauthService) introspectRefreshToken(
+	ctx context.Context,
+	token string,
+) (*models.TokenIntrospectionResponse, error) {
+
+	refreshToken, err := s.TokenService.ValidateRefreshToken(token)
+	if err != nil {
+		return &models.TokenIntrospectionResponse{Active: false}, nil
+	}
+
+	sessions, err := s.TokenRepository.ListRefreshTokens(ctx, refreshToken.UserID.String())
+	if err != nil {
+		return nil, apperrors.NewOAuthError(apperrors.ServerError, "Internal server error")
+	}
+
+	for _, session := range sessions {
+		if session.ID != refreshToken.ID.String() {
+			continue
+		}
+
+		user, err := s.UserRepository.FindByID(ctx, refreshToken.UserID)
+		if err != nil || user.Disabled {
+			break
+		}
+
+		issuedAt, _ := tokenLifetime(token)
+
+		return &models.TokenIntrospectionResponse{
+			Active:    true,
+			TokenType: "refresh_token",
+			Subject:   user.UID.String(),
+			Username:  user.Login,
+			Issuer:    s.Issuer,
+			ExpiresAt: time.Now().Unix() + session.ExpiresIn,
+			IssuedAt:  issuedAt,
+		}, nil
+	}
+
+	return &models.TokenIntrospectionResponse{Active: false}, nil
+}
+
+// revoke refresh token as defined by RFC 7009, only tokens issued to the requesting client are revoked
+// other tokens, including access tokens that are not stored, are not an error, so clients cannot probe tokens
+func (s *oauthService) Revoke(ctx context.Context, request *models.TokenRevocationRequest) error {
+	client, err := s.authenticateClient(ctx, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	refreshToken, err := s.TokenService.ValidateRefreshToken(request.Token)
+	if err != nil || refreshToken.Grant == nil || refreshToken.Grant.ClientID != client.ClientID {
+		return nil
+	}
+
+	err = s.TokenRepository.DeleteRefreshToken(ctx, refreshToken.UserID.String(), refreshToken.ID.String())
+
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) && appErr.Type == apperrors.Internal {
+		return apperrors.NewOAuthError(apperrors.ServerError, "Internal server error")
+	}
+
+	return nil
+}
+
+func tokenResponse(tokens *models.TokenPair) *models.OAuthTokenResponse {
+	return &models.OAuthTokenResponse{
+		AccessToken:  tokens.AccessToken.Token,
+		TokenType:    "Bearer",
+		ExpiresIn:    tokens.AccessToken.ExpiresIn,
+		RefreshToken: tokens.RefreshToken.Token,
+	}
+}
+
+// internal errors become server_error, everything else the given code
+func oauthErrorFrom(err error, code string, description string) *apperrors.OAuthError {
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) && appErr.Type == apperrors.Internal {
+		return apperrors.NewOAuthError(apperrors.ServerError, "Internal server error")
+	}
+
+	return apperrors.NewOAuthError(code, description)
+}
+
+// PKCE S256 verification as defined by RFC 7636
+func verifyCodeChallenge(challenge string, verifier string) bool {
+	if verifier == "" {
+		return false
+	}
+
+	hash := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// RedirectURIWithParams adds non empty params to the query of redirect uri
+func RedirectURIWithParams(redirectURI string, params map[string]string) (string, error) {
+	parsedURI, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", apperrors.NewBadRequest("invalid redirect uri")
+	}
+
+	query := parsedURI.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	parsedURI.RawQuery = query.Encode()
+
+	return parsedURI.String(), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
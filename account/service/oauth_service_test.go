@@ -0,0 +1,741 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidateAuthorizationRequest(test *testing.T) {
+	mockClientRepository := new(mocks.MockOAuthClientRepository)
+	oauthService := NewOAuthService(&OAuthServiceConfig{
+		OAuthClientRepository: mockClientRepository,
+	})
+
+	publicClient := &models.OAuthClient{
+		ClientID:     "public",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}
+
+	mockClientRepository.On("FindByID", mock.Anything, "public").Return(publicClient, nil)
+	mockClientRepository.On("FindByID", mock.Anything, "unknown").Return(nil, apperrors.NewNotFound("client", "unknown"))
+
+	validRequest := func() *models.AuthorizationRequest {
+		return &models.AuthorizationRequest{
+			ResponseType:        "code",
+			ClientID:            "public",
+			RedirectURI:         "https://app.example.com/callback",
+			Scope:               "openid profile",
+			CodeChallenge:       "challenge",
+			CodeChallengeMethod: "S256",
+		}
+	}
+
+	test.Run("Valid request", func(test *testing.T) {
+		err := oauthService.ValidateAuthorizationRequest(context.TODO(), validRequest())
+		assert.NoError(test, err)
+	})
+
+	test.Run("Fills in single redirect uri", func(test *testing.T) {
+		request := validRequest()
+		request.RedirectURI = ""
+
+		err := oauthService.ValidateAuthorizationRequest(context.TODO(), request)
+		assert.NoError(test, err)
+		assert.Equal(test, "https://app.example.com/callback", request.RedirectURI)
+	})
+
+	test.Run("Unknown client is not redirected", func(test *testing.T) {
+		request := validRequest()
+		request.ClientID = "unknown"
+
+		err := oauthService.ValidateAuthorizationRequest(context.TODO(), request)
+		assert.Equal(test, "", err.(*apperrors.OAuthError).RedirectURI)
+	})
+
+	test.Run("Unregistered redirect uri is not redirected", func(test *testing.T) {
+		request := validRequest()
+		request.RedirectURI = "https://evil.example.com/callback"
+
+		err := oauthService.ValidateAuthorizationRequest(context.TODO(), request)
+		assert.Equal(test, "", err.(*apperrors.OAuthError).RedirectURI)
+	})
+
+	test.Run("Errors after redirect uri check are redirected", func(test *testing.T) {
+		cases := map[string]func(request *models.AuthorizationRequest){
+			apperrors.UnsupportedResponseType: func(request *models.AuthorizationRequest) {
+				request.ResponseType = "token"
+			},
+			apperrors.InvalidScope: func(request *models.AuthorizationRequest) {
+				request.Scope = "openid admin"
+			},
+			apperrors.InvalidRequest: func(request *models.AuthorizationRequest) {
+				request.CodeChallenge = ""
+			},
+		}
+
+		for code, change := range cases {
+			request := validRequest()
+			change(request)
+
+			err := oauthService.ValidateAuthorizationRequest(context.TODO(), request)
+			oauthErr := err.(*apperrors.OAuthError)
+
+			assert.Equal(test, code, oauthErr.Code)
+			assert.Equal(test, request.RedirectURI, oauthErr.RedirectURI)
+		}
+	})
+}
+
+func TestAuthorize(test *testing.T) {
+	mockClientRepository := new(mocks.MockOAuthClientRepository)
+	mockCodeRepository := new(mocks.MockAuthorizationCodeRepository)
+	oauthService := NewOAuthService(&OAuthServiceConfig{
+		OAuthClientRepository:       mockClientRepository,
+		AuthorizationCodeRepository: mockCodeRepository,
+	})
+
+	client := &models.OAuthClient{
+		ClientID:     "client",
+		SecretHash:   "hash.salt",
+		RedirectURIs: []string{"https://app.example.com/callback?app=1"},
+	}
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid}
+
+	mockClientRepository.On("FindByID", mock.Anything, "client").Return(client, nil)
+
+	var savedCode *models.AuthorizationCode
+	mockCodeRepository.
+		On("Save", mock.Anything, mock.AnythingOfType("*models.AuthorizationCode"), authorizationCodeExpiration).
+		Run(func(args mock.Arguments) {
+			savedCode = args.Get(1).(*models.AuthorizationCode)
+		}).
+		Return(nil)
+
+	redirectURL, err := oauthService.Authorize(context.TODO(), &models.AuthorizationRequest{
+		ResponseType: "code",
+		ClientID:     "client",
+		Scope:        "openid",
+		State:        "xyz",
+		Nonce:        "n-0S6",
+	}, user)
+	assert.NoError(test, err)
+
+	parsedURL, _ := url.Parse(redirectURL)
+	assert.Equal(test, "app.example.com", parsedURL.Host)
+	assert.Equal(test, "1", parsedURL.Query().Get("app"))
+	assert.Equal(test, "xyz", parsedURL.Query().Get("state"))
+	assert.Equal(test, savedCode.Code, parsedURL.Query().Get("code"))
+
+	assert.Equal(test, uid, savedCode.UserID)
+	assert.Equal(test, "n-0S6", savedCode.Nonce)
+	assert.Equal(test, "https://app.example.com/callback?app=1", savedCode.RedirectURI)
+}
+
+func TestOAuthToken(test *testing.T) {
+	mockClientRepository := new(mocks.MockOAuthClientRepository)
+	mockCodeRepository := new(mocks.MockAuthorizationCodeRepository)
+	mockUserRepository := new(mocks.MockUserRepository)
+	mockTokenService := new(mocks.MockTokenService)
+
+	oauthService := NewOAuthService(&OAuthServiceConfig{
+		OAuthClientRepository:       mockClientRepository,
+		AuthorizationCodeRepository: mockCodeRepository,
+		UserRepository:              mockUserRepository,
+		TokenService:                mockTokenService,
+		Issuer:                      "https://accounts.example.com",
+	})
+
+	secret := "clientsecret"
+	secretHash, _ := HashPassword(secret)
+
+	mockClientRepository.On("FindByID", mock.Anything, "confidential").Return(&models.OAuthClient{
+		ClientID:   "confidential",
+		SecretHash: secretHash,
+	}, nil)
+	mockClientRepository.On("FindByID", mock.Anything, "public").Return(&models.OAuthClient{
+		ClientID: "public",
+	}, nil)
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice"}
+	mockUserRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	hash := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	codeFor := func(code string) *models.AuthorizationCode {
+		return &models.AuthorizationCode{
+			Code:                code,
+			ClientID:            "public",
+			UserID:              uid,
+			RedirectURI:         "https://app.example.com/callback",
+			Scope:               "openid profile",
+			Nonce:               "nonce",
+			CodeChallenge:       challenge,
+			CodeChallengeMethod: "S256",
+			AuthTime:            1000,
+		}
+	}
+
+	tokens := &models.TokenPair{
+		AccessToken:  models.AccessToken{Token: "access", ExpiresIn: 900},
+		RefreshToken: models.RefreshToken{Token: "refresh"},
+	}
+
+	test.Run("Exchange code with PKCE", func(test *testing.T) {
+		mockCodeRepository.On("Consume", mock.Anything, "good").Return(codeFor("good"), nil)
+		grant := &models.ClientGrant{ClientID: "public", Scope: "openid profile"}
+
+		mockTokenService.On("NewPairForClient", mock.Anything, user, grant, "").Return(tokens, nil)
+		mockTokenService.On("NewIDToken", user, &models.IDTokenOptions{
+			Issuer:   "https://accounts.example.com",
+			Audience: "public",
+			Nonce:    "nonce",
+			AuthTime: 1000,
+		}).Return("idtoken", nil)
+
+		response, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "authorization_code",
+			Code:         "good",
+			RedirectURI:  "https://app.example.com/callback",
+			CodeVerifier: verifier,
+			ClientID:     "public",
+		})
+		assert.NoError(test, err)
+
+		assert.Equal(test, &models.OAuthTokenResponse{
+			AccessToken:  "access",
+			TokenType:    "Bearer",
+			ExpiresIn:    900,
+			RefreshToken: "refresh",
+			IDToken:      "idtoken",
+			Scope:        "openid profile",
+		}, response)
+	})
+
+	test.Run("Refresh token of client", func(test *testing.T) {
+		tokenID, _ := uuid.NewRandom()
+		grant := &models.ClientGrant{ClientID: "public", Scope: "openid"}
+
+		mockTokenService.
+			On("ValidateRefreshToken", "client-refresh").
+			Return(&models.RefreshToken{ID: tokenID, UserID: uid, Grant: grant}, nil)
+		mockTokenService.On("NewPairForClient", mock.Anything, user, grant, tokenID.String()).Return(tokens, nil)
+
+		response, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "refresh_token",
+			RefreshToken: "client-refresh",
+			ClientID:     "public",
+		})
+		assert.NoError(test, err)
+		assert.Equal(test, "openid", response.Scope)
+	})
+
+	test.Run("Refresh token of other client or account service", func(test *testing.T) {
+		mockTokenService.
+			On("ValidateRefreshToken", "other-refresh").
+			Return(&models.RefreshToken{UserID: uid, Grant: &models.ClientGrant{ClientID: "confidential"}}, nil)
+		mockTokenService.
+			On("ValidateRefreshToken", "first-party-refresh").
+			Return(&models.RefreshToken{UserID: uid}, nil)
+
+		for _, refreshToken := range []string{"other-refresh", "first-party-refresh"} {
+			_, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+				GrantType:    "refresh_token",
+				RefreshToken: refreshToken,
+				ClientID:     "public",
+			})
+			assert.Equal(test, apperrors.InvalidGrant, err.(*apperrors.OAuthError).Code, refreshToken)
+		}
+	})
+
+	test.Run("Wrong code verifier", func(test *testing.T) {
+		mockCodeRepository.On("Consume", mock.Anything, "stolen").Return(codeFor("stolen"), nil)
+
+		_, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "authorization_code",
+			Code:         "stolen",
+			RedirectURI:  "https://app.example.com/callback",
+			CodeVerifier: "wrong",
+			ClientID:     "public",
+		})
+		assert.Equal(test, apperrors.InvalidGrant, err.(*apperrors.OAuthError).Code)
+	})
+
+	test.Run("Used code", func(test *testing.T) {
+		mockCodeRepository.On("Consume", mock.Anything, "used").Return(nil, apperrors.NewNotFound("code", "used"))
+
+		_, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType: "authorization_code",
+			Code:      "used",
+			ClientID:  "public",
+		})
+		assert.Equal(test, apperrors.InvalidGrant, err.(*apperrors.OAuthError).Code)
+	})
+
+	test.Run("Wrong client secret", func(test *testing.T) {
+		_, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "authorization_code",
+			Code:         "good",
+			ClientID:     "confidential",
+			ClientSecret: "wrong",
+		})
+		assert.Equal(test, apperrors.InvalidClient, err.(*apperrors.OAuthError).Code)
+	})
+
+	test.Run("Unsupported grant type", func(test *testing.T) {
+		_, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "password",
+			ClientID:     "confidential",
+			ClientSecret: secret,
+		})
+		assert.Equal(test, apperrors.UnsupportedGrantType, err.(*apperrors.OAuthError).Code)
+	})
+}
+
+func TestClientCredentials(test *testing.T) {
+	mockClientRepository := new(mocks.MockOAuthClientRepository)
+	mockTokenService := new(mocks.MockTokenService)
+
+	oauthService := NewOAuthService(&OAuthServiceConfig{
+		OAuthClientRepository: mockClientRepository,
+		TokenService:          mockTokenService,
+		Issuer:                "https://accounts.example.com",
+	})
+
+	secret := "clientsecret"
+	secretHash, _ := HashPassword(secret)
+
+	mockClientRepository.On("FindByID", mock.Anything, "service").Return(&models.OAuthClient{
+		ClientID:   "service",
+		SecretHash: secretHash,
+		Service:    true,
+		Audiences:  []string{"account", "billing"},
+	}, nil)
+	mockClientRepository.On("FindByID", mock.Anything, "confidential").Return(&models.OAuthClient{
+		ClientID:     "confidential",
+		SecretHash:   secretHash,
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}, nil)
+
+	test.Run("Issues token for audience", func(test *testing.T) {
+		mockTokenService.
+			On("NewServiceToken", &models.ServicePrincipal{ClientID: "service", Audience: "billing"}, "https://accounts.example.com").
+			Return(&models.AccessToken{Token: "servicetoken", ExpiresIn: 900}, nil)
+
+		response, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "client_credentials",
+			ClientID:     "service",
+			ClientSecret: secret,
+			Audience:     "billing",
+		})
+		assert.NoError(test, err)
+
+		assert.Equal(test, &models.OAuthTokenResponse{
+			AccessToken: "servicetoken",
+			TokenType:   "Bearer",
+			ExpiresIn:   900,
+		}, response)
+	})
+
+	test.Run("Audience is required when client has several", func(test *testing.T) {
+		_, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "client_credentials",
+			ClientID:     "service",
+			ClientSecret: secret,
+		})
+		assert.Equal(test, apperrors.InvalidTarget, err.(*apperrors.OAuthError).Code)
+	})
+
+	test.Run("Audience not allowed", func(test *testing.T) {
+		_, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "client_credentials",
+			ClientID:     "service",
+			ClientSecret: secret,
+			Audience:     "admin",
+		})
+		assert.Equal(test, apperrors.InvalidTarget, err.(*apperrors.OAuthError).Code)
+	})
+
+	test.Run("Wrong client secret", func(test *testing.T) {
+		_, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "client_credentials",
+			ClientID:     "service",
+			ClientSecret: "wrong",
+			Audience:     "account",
+		})
+		assert.Equal(test, apperrors.InvalidClient, err.(*apperrors.OAuthError).Code)
+	})
+
+	test.Run("Client is not a service", func(test *testing.T) {
+		_, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "client_credentials",
+			ClientID:     "confidential",
+			ClientSecret: secret,
+		})
+		assert.Equal(test, apperrors.UnauthorizedClient, err.(*apperrors.OAuthError).Code)
+	})
+
+	test.Run("Service cannot exchange codes", func(test *testing.T) {
+		_, err := oauthService.Token(context.TODO(), &models.OAuthTokenRequest{
+			GrantType:    "authorization_code",
+			Code:         "code",
+			ClientID:     "service",
+			ClientSecret: secret,
+		})
+		assert.Equal(test, apperrors.UnauthorizedClient, err.(*apperrors.OAuthError).Code)
+	})
+
+	test.Run("Service cannot sign users in", func(test *testing.T) {
+		err := oauthService.ValidateAuthorizationRequest(context.TODO(), &models.AuthorizationRequest{
+			ResponseType: "code",
+			ClientID:     "service",
+		})
+		assert.Equal(test, apperrors.UnauthorizedClient, err.(*apperrors.OAuthError).Code)
+	})
+}
+
+func TestRegisterClient(test *testing.T) {
+	mockClientRepository := new(mocks.MockOAuthClientRepository)
+	oauthService := NewOAuthService(&OAuthServiceConfig{
+		OAuthClientRepository: mockClientRepository,
+	})
+
+	mockClientRepository.On("Create", mock.Anything, mock.AnythingOfType("*models.OAuthClient")).Return(nil)
+
+	test.Run("Confidential client", func(test *testing.T) {
+		client := &models.OAuthClient{
+			Name:         "memorize",
+			RedirectURIs: []string{"https://memorize.example.com/callback"},
+		}
+
+		secret, err := oauthService.RegisterClient(context.TODO(), client, true)
+		assert.NoError(test, err)
+
+		assert.NotEmpty(test, client.ClientID)
+		match, _ := comparePasswords(client.SecretHash, secret)
+		assert.True(test, match)
+	})
+
+	test.Run("Public client", func(test *testing.T) {
+		client := &models.OAuthClient{
+			Name:         "mobile",
+			RedirectURIs: []string{"memorize://callback"},
+		}
+
+		secret, err := oauthService.RegisterClient(context.TODO(), client, false)
+		assert.NoError(test, err)
+
+		assert.Empty(test, secret)
+		assert.True(test, client.Public())
+	})
+
+	test.Run("Relative redirect uri", func(test *testing.T) {
+		client := &models.OAuthClient{
+			Name:         "broken",
+			RedirectURIs: []string{"/callback"},
+		}
+
+		_, err := oauthService.RegisterClient(context.TODO(), client, true)
+		assert.Equal(test, apperrors.BadRequest, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("Service client", func(test *testing.T) {
+		client := &models.OAuthClient{
+			Name:      "billing",
+			Service:   true,
+			Audiences: []string{"account"},
+		}
+
+		secret, err := oauthService.RegisterClient(context.TODO(), client, true)
+		assert.NoError(test, err)
+		assert.NotEmpty(test, secret)
+	})
+
+	test.Run("Service client without audience or secret", func(test *testing.T) {
+		_, err := oauthService.RegisterClient(context.TODO(), &models.OAuthClient{
+			Name:    "billing",
+			Service: true,
+		}, true)
+		assert.Equal(test, apperrors.BadRequest, err.(*apperrors.Error).Type)
+
+		_, err = oauthService.RegisterClient(context.TODO(), &models.OAuthClient{
+			Name:      "billing",
+			Service:   true,
+			Audiences: []string{"account"},
+		}, false)
+		assert.Equal(test, apperrors.BadRequest, err.(*apperrors.Error).Type)
+	})
+}
+
+func TestNewIDToken(test *testing.T) {
+	privateKey, publicKey := testKeyPair()
+
+	tokenService := NewTokenService(&TokenServiceConfig{
+		PrivateKey:         privateKey,
+		PublicKey:          publicKey,
+		TokenExpirationSec: 60,
+	})
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice", Email: "alice@mail.com"}
+
+	idToken, err := tokenService.NewIDToken(user, &models.IDTokenOptions{
+		Issuer:   "https://accounts.example.com",
+		Audience: "client",
+		Nonce:    "nonce",
+	})
+	assert.NoError(test, err)
+
+	claims := &oidcTokenClaims{}
+	token, err := parseWithKeySet(idToken, claims, tokenService.JWKS())
+	assert.NoError(test, err)
+	assert.True(test, token.Valid)
+
+	assert.Equal(test, uid.String(), claims.Subject)
+	assert.Equal(test, "client", claims.Audience)
+	assert.Equal(test, "nonce", claims.Nonce)
+	assert.Equal(test, "alice", claims.PreferredUsername)
+	assert.Equal(test, "alice@mail.com", claims.Email)
+}
+
+func TestServiceToken(test *testing.T) {
+	privateKey, publicKey := testKeyPair()
+
+	tokenService := NewTokenService(&TokenServiceConfig{
+		PrivateKey:         privateKey,
+		PublicKey:          publicKey,
+		TokenExpirationSec: 60,
+	})
+
+	accessToken, err := tokenService.NewServiceToken(&models.ServicePrincipal{
+		ClientID: "billing",
+		Audience: "account",
+	}, "https://accounts.example.com")
+	assert.NoError(test, err)
+	assert.Equal(test, int64(60), accessToken.ExpiresIn)
+
+	test.Run("Valid for audience", func(test *testing.T) {
+		principal, err := tokenService.ValidateServiceToken(accessToken.Token, "account")
+		assert.NoError(test, err)
+		assert.Equal(test, &models.ServicePrincipal{ClientID: "billing", Audience: "account"}, principal)
+	})
+
+	test.Run("Other audience", func(test *testing.T) {
+		_, err := tokenService.ValidateServiceToken(accessToken.Token, "admin")
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("Not accepted as user token", func(test *testing.T) {
+		user, err := tokenService.ValidateAccessToken(accessToken.Token)
+		assert.Nil(test, user)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("User token not accepted as service token", func(test *testing.T) {
+		uid, _ := uuid.NewRandom()
+		userToken, _ := generateToken(&models.User{UID: uid}, privateKey, 60)
+
+		_, err := tokenService.ValidateServiceToken(userToken, "account")
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+}
+
+// generated once, key generation is slow
+var testPrivateKey *rsa.PrivateKey
+
+func testKeyPair() (*rsa.PrivateKey, *rsa.PublicKey) {
+	if testPrivateKey == nil {
+		testPrivateKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	}
+
+	return testPrivateKey, &testPrivateKey.PublicKey
+}
+
+// verify token with the key from key set matching kid header, like a relying party would
+func parseWithKeySet(tokenString string, claims jwt.Claims, keySet *models.JSONWebKeySet) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		for _, key := range keySet.Keys {
+			if key.KeyID != token.Header["kid"] {
+				continue
+			}
+
+			modulus, _ := base64.RawURLEncoding.DecodeString(key.Modulus)
+			exponent, _ := base64.RawURLEncoding.DecodeString(key.Exponent)
+
+			return &rsa.PublicKey{
+				N: new(big.Int).SetBytes(modulus),
+				E: int(new(big.Int).SetBytes(exponent).Int64()),
+			}, nil
+		}
+
+		return nil, fmt.Errorf("no key with kid %v", token.Header["kid"])
+	})
+}
+
+func TestIntrospectAndRevoke(test *testing.T) {
+	mockClientRepository := new(mocks.MockOAuthClientRepository)
+	mockUserRepository := new(mocks.MockUserRepository)
+	mockTokenRepository := new(mocks.MockTokenRepository)
+	mockTokenService := new(mocks.MockTokenService)
+
+	oauthService := NewOAuthService(&OAuthServiceConfig{
+		OAuthClientRepository: mockClientRepository,
+		UserRepository:        mockUserRepository,
+		TokenRepository:       mockTokenRepository,
+		TokenService:          mockTokenService,
+		Issuer:                "https://accounts.example.com",
+	})
+
+	secret := "clientsecret"
+	secretHash, _ := HashPassword(secret)
+
+	mockClientRepository.On("FindByID", mock.Anything, "resource").Return(&models.OAuthClient{
+		ClientID:   "resource",
+		SecretHash: secretHash,
+		Service:    true,
+	}, nil)
+	mockClientRepository.On("FindByID", mock.Anything, "public").Return(&models.OAuthClient{
+		ClientID: "public",
+	}, nil)
+
+	uid, _ := uuid.NewRandom()
+	tokenID, _ := uuid.NewRandom()
+	revokedID, _ := uuid.NewRandom()
+	otherID, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice"}
+
+	invalidErr := apperrors.NewAuthorization("Unable to verify user from idToken").WithCode(apperrors.InvalidToken)
+
+	mockUserRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+	mockTokenService.On("ValidateAccessToken", "access").Return(user, nil)
+	mockTokenService.On("ValidateAccessToken", mock.Anything).Return(nil, invalidErr)
+	grant := &models.ClientGrant{ClientID: "public", Scope: "openid"}
+	mockTokenService.On("ValidateRefreshToken", "refresh").Return(&models.RefreshToken{ID: tokenID, UserID: uid, Grant: grant}, nil)
+	mockTokenService.On("ValidateRefreshToken", "revoked").Return(&models.RefreshToken{ID: revokedID, UserID: uid, Grant: grant}, nil)
+	mockTokenService.On("ValidateRefreshToken", "other").Return(&models.RefreshToken{ID: otherID, UserID: uid, Grant: &models.ClientGrant{ClientID: "other"}}, nil)
+	mockTokenService.On("ValidateRefreshToken", "session").Return(&models.RefreshToken{ID: otherID, UserID: uid}, nil)
+	mockTokenService.On("ValidateRefreshToken", mock.Anything).Return(nil, invalidErr)
+	mockTokenRepository.On("ListRefreshTokens", mock.Anything, uid.String()).Return([]*models.Session{
+		{ID: tokenID.String(), ExpiresIn: 3600},
+	}, nil)
+
+	introspect := func(token string, hint string) (*models.TokenIntrospectionResponse, error) {
+		return oauthService.Introspect(context.TODO(), &models.TokenIntrospectionRequest{
+			Token:         token,
+			TokenTypeHint: hint,
+			ClientID:      "resource",
+			ClientSecret:  secret,
+		})
+	}
+
+	test.Run("Active access token", func(test *testing.T) {
+		response, err := introspect("access", "")
+		assert.NoError(test, err)
+
+		assert.True(test, response.Active)
+		assert.Equal(test, "Bearer", response.TokenType)
+		assert.Equal(test, uid.String(), response.Subject)
+		assert.Equal(test, "alice", response.Username)
+		assert.Equal(test, "https://accounts.example.com", response.Issuer)
+	})
+
+	test.Run("Active refresh token", func(test *testing.T) {
+		response, err := introspect("refresh", "refresh_token")
+		assert.NoError(test, err)
+
+		assert.True(test, response.Active)
+		assert.Equal(test, "refresh_token", response.TokenType)
+		assert.Equal(test, uid.String(), response.Subject)
+		assert.InDelta(test, time.Now().Add(time.Hour).Unix(), response.ExpiresAt, 5)
+	})
+
+	test.Run("Revoked refresh token is inactive", func(test *testing.T) {
+		response, err := introspect("revoked", "")
+		assert.NoError(test, err)
+
+		assert.Equal(test, &models.TokenIntrospectionResponse{Active: false}, response)
+	})
+
+	test.Run("Invalid token is inactive", func(test *testing.T) {
+		response, err := introspect("garbage", "access_token")
+		assert.NoError(test, err)
+
+		assert.False(test, response.Active)
+	})
+
+	test.Run("Public client cannot introspect", func(test *testing.T) {
+		_, err := oauthService.Introspect(context.TODO(), &models.TokenIntrospectionRequest{
+			Token:    "access",
+			ClientID: "public",
+		})
+		assert.Equal(test, apperrors.UnauthorizedClient, err.(*apperrors.OAuthError).Code)
+	})
+
+	test.Run("Wrong client secret", func(test *testing.T) {
+		_, err := oauthService.Introspect(context.TODO(), &models.TokenIntrospectionRequest{
+			Token:        "access",
+			ClientID:     "resource",
+			ClientSecret: "wrong",
+		})
+		assert.Equal(test, apperrors.InvalidClient, err.(*apperrors.OAuthError).Code)
+	})
+
+	test.Run("Revoke refresh token", func(test *testing.T) {
+		mockTokenRepository.On("DeleteRefreshToken", mock.Anything, uid.String(), tokenID.String()).Return(nil)
+
+		err := oauthService.Revoke(context.TODO(), &models.TokenRevocationRequest{
+			Token:    "refresh",
+			ClientID: "public",
+		})
+		assert.NoError(test, err)
+
+		mockTokenRepository.AssertCalled(test, "DeleteRefreshToken", mock.Anything, uid.String(), tokenID.String())
+	})
+
+	test.Run("Revoking revoked or invalid token succeeds", func(test *testing.T) {
+		mockTokenRepository.
+			On("DeleteRefreshToken", mock.Anything, uid.String(), revokedID.String()).
+			Return(apperrors.NewAuthorization("Invalid refresh token").WithCode(apperrors.TokenReused))
+
+		assert.NoError(test, oauthService.Revoke(context.TODO(), &models.TokenRevocationRequest{
+			Token:    "revoked",
+			ClientID: "public",
+		}))
+		assert.NoError(test, oauthService.Revoke(context.TODO(), &models.TokenRevocationRequest{
+			Token:    "garbage",
+			ClientID: "public",
+		}))
+	})
+
+	test.Run("Tokens not issued to client are not revoked", func(test *testing.T) {
+		for _, token := range []string{"other", "session", "access"} {
+			err := oauthService.Revoke(context.TODO(), &models.TokenRevocationRequest{
+				Token:    token,
+				ClientID: "public",
+			})
+			assert.NoError(test, err)
+		}
+
+		mockTokenRepository.AssertNotCalled(test, "DeleteRefreshToken", mock.Anything, uid.String(), otherID.String())
+	})
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// last used time is written at most once per interval to spare database
+const lastUsedUpdateInterval = time.Minute
+
+type personalAccessTokenService struct {
+	PersonalAccessTokenRepository models.PersonalAccessTokenRepository
+	UserRepository                models.UserRepository
+}
+
+// config hold repositories injected into personal access token service
+type PersonalAccessTokenServiceConfig struct {
+	PersonalAccessTokenRepository models.PersonalAccessTokenRepository
+	UserRepository                models.UserRepository
+}
+
+// factory function for initializing PersonalAccessTokenService with its dependencies
+func NewPersonalAccessTokenService(config *PersonalAccessTokenServiceConfig) models.PersonalAccessTokenService {
+	return &personalAccessTokenService{
+		PersonalAccessTokenRepository: config.PersonalAccessTokenRepository,
+		UserRepository:                config.UserRepository,
+	}
+}
+
+// create token, only its hash is stored so plain token is returned once
+func (s *personalAccessTokenService) Create(
+	ctx context.Context,
+	uid uuid.UUID,
+	token *models.PersonalAccessToken,
+	expiresIn time.Duration,
+) error {
+
+	scopes, err := normalizeScopes(token.Scopes)
+	if err != nil {
+		return err
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		log.Printf("Unable to generate personal access token for user: %v\n", uid)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	token.UserID = uid
+	token.Scopes = scopes
+	token.Token = models.PersonalAccessTokenPrefix + secret
+	token.TokenHash = hashPersonalAccessToken(token.Token)
+
+	if expiresIn > 0 {
+		expiresAt := time.Now().Add(expiresIn)
+		token.ExpiresAt = &expiresAt
+	}
+
+	return s.PersonalAccessTokenRepository.Create(ctx, token)
+}
+
+// list tokens of user
+func (s *personalAccessTokenService) List(ctx context.Context, uid uuid.UUID) ([]*models.PersonalAccessToken, error) {
+	return s.PersonalAccessTokenRepository.FindByUserID(ctx, uid)
+}
+
+// delete token of user
+func (s *personalAccessTokenService) Revoke(ctx context.Context, uid uuid.UUID, tokenID uuid.UUID) error {
+	return s.PersonalAccessTokenRepository.Delete(ctx, uid, tokenID)
+}
+
+// validate plain token, returns its user and scopes
+func (s *personalAccessTokenService) Validate(ctx context.Context, plainToken string) (*models.User, []string, error) {
+	invalid := apperrors.NewAuthorization("Provided token is invalid").WithCode(apperrors.InvalidToken)
+
+	if !strings.HasPrefix(plainToken, models.PersonalAccessTokenPrefix) {
+		return nil, nil, invalid
+	}
+
+	token, err := s.PersonalAccessTokenRepository.FindByHash(ctx, hashPersonalAccessToken(plainToken))
+	if err != nil {
+		if isType(err, apperrors.NotFound) {
+			return nil, nil, invalid
+		}
+
+		return nil, nil, err
+	}
+
+	now := time.Now()
+
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return nil, nil, invalid
+	}
+
+	user, err := s.UserRepository.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, nil, invalid
+	}
+
+	if user.Disabled {
+		return nil, nil, apperrors.NewAuthorization("Account is disabled").WithCode(apperrors.AccountDisabled)
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > lastUsedUpdateInterval {
+		// failing to record usage should not fail request
+		if err := s.PersonalAccessTokenRepository.UpdateLastUsed(ctx, token.ID, now); err != nil {
+			log.Printf("Unable to record use of personal access token: %v\n", token.ID)
+		}
+	}
+
+	return user, token.Scopes, nil
+}
+
+// check requested scopes and drop duplicates
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, apperrors.NewBadRequest("at least one scope is required")
+	}
+
+	normalized := []string{}
+
+	for _, scope := range scopes {
+		if !contains(models.PersonalAccessTokenScopes, scope) {
+			return nil, apperrors.NewBadRequest(fmt.Sprintf("unknown scope %v", scope))
+		}
+
+		if !contains(normalized, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+
+	return normalized, nil
+}
+
+// tokens are random, fast hash is enough and allows lookup by hash
+func hashPersonalAccessToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
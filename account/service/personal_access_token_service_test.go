@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPersonalAccessTokens(test *testing.T) {
+	uid, _ := uuid.NewRandom()
+	tokenID, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice"}
+
+	newService := func() (models.PersonalAccessTokenService, *mocks.MockPersonalAccessTokenRepository, *mocks.MockUserRepository) {
+		tokenRepository := new(mocks.MockPersonalAccessTokenRepository)
+		userRepository := new(mocks.MockUserRepository)
+
+		return NewPersonalAccessTokenService(&PersonalAccessTokenServiceConfig{
+			PersonalAccessTokenRepository: tokenRepository,
+			UserRepository:                userRepository,
+		}), tokenRepository, userRepository
+	}
+
+	test.Run("Create stores only hash", func(test *testing.T) {
+		tokenService, tokenRepository, _ := newService()
+
+		tokenRepository.On("Create", mock.Anything, mock.AnythingOfType("*models.PersonalAccessToken")).Return(nil)
+
+		token := &models.PersonalAccessToken{
+			Name:   "backup script",
+			Scopes: []string{models.ScopeProfileRead, models.ScopeProfileRead},
+		}
+
+		err := tokenService.Create(context.Background(), uid, token, time.Hour)
+		assert.NoError(test, err)
+
+		assert.True(test, strings.HasPrefix(token.Token, models.PersonalAccessTokenPrefix))
+		assert.Equal(test, hashPersonalAccessToken(token.Token), token.TokenHash)
+		assert.NotContains(test, token.TokenHash, token.Token)
+		assert.Equal(test, uid, token.UserID)
+		assert.Equal(test, []string{models.ScopeProfileRead}, token.Scopes)
+		assert.WithinDuration(test, time.Now().Add(time.Hour), *token.ExpiresAt, time.Minute)
+	})
+
+	test.Run("Create without expiry", func(test *testing.T) {
+		tokenService, tokenRepository, _ := newService()
+
+		tokenRepository.On("Create", mock.Anything, mock.AnythingOfType("*models.PersonalAccessToken")).Return(nil)
+
+		token := &models.PersonalAccessToken{Name: "ci", Scopes: []string{models.ScopeSessions}}
+
+		err := tokenService.Create(context.Background(), uid, token, 0)
+		assert.NoError(test, err)
+		assert.Nil(test, token.ExpiresAt)
+	})
+
+	test.Run("Create with unknown scope", func(test *testing.T) {
+		tokenService, tokenRepository, _ := newService()
+
+		for _, scopes := range [][]string{nil, {"admin"}} {
+			err := tokenService.Create(context.Background(), uid, &models.PersonalAccessToken{Name: "ci", Scopes: scopes}, 0)
+			assert.Equal(test, apperrors.BadRequest, err.(*apperrors.Error).Type)
+		}
+
+		tokenRepository.AssertNotCalled(test, "Create", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Validate", func(test *testing.T) {
+		tokenService, tokenRepository, userRepository := newService()
+
+		plain := models.PersonalAccessTokenPrefix + "secret"
+		tokenRepository.On("FindByHash", mock.Anything, hashPersonalAccessToken(plain)).Return(&models.PersonalAccessToken{
+			ID:     tokenID,
+			UserID: uid,
+			Scopes: []string{models.ScopeProfileRead},
+		}, nil)
+		tokenRepository.On("UpdateLastUsed", mock.Anything, tokenID, mock.AnythingOfType("time.Time")).Return(nil)
+		userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+
+		validUser, scopes, err := tokenService.Validate(context.Background(), plain)
+		assert.NoError(test, err)
+		assert.Equal(test, user, validUser)
+		assert.Equal(test, []string{models.ScopeProfileRead}, scopes)
+
+		tokenRepository.AssertExpectations(test)
+	})
+
+	test.Run("Validate recently used token", func(test *testing.T) {
+		tokenService, tokenRepository, userRepository := newService()
+
+		lastUsedAt := time.Now().Add(-time.Second)
+		plain := models.PersonalAccessTokenPrefix + "secret"
+		tokenRepository.On("FindByHash", mock.Anything, hashPersonalAccessToken(plain)).Return(&models.PersonalAccessToken{
+			ID:         tokenID,
+			UserID:     uid,
+			LastUsedAt: &lastUsedAt,
+		}, nil)
+		userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+
+		_, _, err := tokenService.Validate(context.Background(), plain)
+		assert.NoError(test, err)
+
+		tokenRepository.AssertNotCalled(test, "UpdateLastUsed", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Validate expired token", func(test *testing.T) {
+		tokenService, tokenRepository, _ := newService()
+
+		expiresAt := time.Now().Add(-time.Second)
+		plain := models.PersonalAccessTokenPrefix + "secret"
+		tokenRepository.On("FindByHash", mock.Anything, hashPersonalAccessToken(plain)).Return(&models.PersonalAccessToken{
+			ID:        tokenID,
+			UserID:    uid,
+			ExpiresAt: &expiresAt,
+		}, nil)
+
+		_, _, err := tokenService.Validate(context.Background(), plain)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("Validate unknown token", func(test *testing.T) {
+		tokenService, tokenRepository, _ := newService()
+
+		plain := models.PersonalAccessTokenPrefix + "unknown"
+		tokenRepository.
+			On("FindByHash", mock.Anything, hashPersonalAccessToken(plain)).
+			Return(nil, apperrors.NewNotFound("token", "personal access token"))
+
+		_, _, err := tokenService.Validate(context.Background(), plain)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("Validate token of disabled user", func(test *testing.T) {
+		tokenService, tokenRepository, userRepository := newService()
+
+		plain := models.PersonalAccessTokenPrefix + "secret"
+		tokenRepository.On("FindByHash", mock.Anything, hashPersonalAccessToken(plain)).Return(&models.PersonalAccessToken{
+			ID:     tokenID,
+			UserID: uid,
+		}, nil)
+		userRepository.On("FindByID", mock.Anything, uid).Return(&models.User{UID: uid, Disabled: true}, nil)
+
+		_, _, err := tokenService.Validate(context.Background(), plain)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+}
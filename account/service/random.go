@@ -0,0 +1,17 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// randomToken returns url safe string with given number of random bytes
+func randomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
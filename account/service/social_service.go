@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// time user has to finish login at provider
+const socialLoginStateExpiration = 10 * time.Minute
+
+// front-end exchanges signin code right after redirect from callback
+const socialSigninCodeExpiration = time.Minute
+
+// characters not allowed in logins created from external profiles
+var loginDisallowedCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+type socialService struct {
+	IdentityProviders            map[string]models.IdentityProvider
+	UserRepository               models.UserRepository
+	UserIdentityRepository       models.UserIdentityRepository
+	SocialLoginStateRepository   models.SocialLoginStateRepository
+	WebAuthnCredentialRepository models.WebAuthnCredentialRepository
+}
+
+// config hold providers and repositories injected into social service
+type SocialServiceConfig struct {
+	IdentityProviders            map[string]models.IdentityProvider
+	UserRepository               models.UserRepository
+	UserIdentityRepository       models.UserIdentityRepository
+	SocialLoginStateRepository   models.SocialLoginStateRepository
+	WebAuthnCredentialRepository models.WebAuthnCredentialRepository
+}
+
+// factory function for initializing SocialService with its dependencies
+func NewSocialService(config *SocialServiceConfig) models.SocialService {
+	return &socialService{
+		IdentityProviders:            config.IdentityProviders,
+		UserRepository:               config.UserRepository,
+		UserIdentityRepository:       config.UserIdentityRepository,
+		SocialLoginStateRepository:   config.SocialLoginStateRepository,
+		WebAuthnCredentialRepository: config.WebAuthnCredentialRepository,
+	}
+}
+
+// names of configured providers
+func (s *socialService) Providers() []string {
+	names := make([]string, 0, len(s.IdentityProviders))
+
+	for name := range s.IdentityProviders {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// begin login or linking, state and PKCE verifier are kept until callback
+func (s *socialService) Start(ctx context.Context, provider string, linkUserID uuid.UUID) (string, error) {
+	identityProvider, err := s.provider(provider)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomToken(32)
+	if err != nil {
+		log.Printf("Unable to generate state for %v login\n", provider)
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	codeVerifier, err := randomToken(32)
+	if err != nil {
+		log.Printf("Unable to generate code verifier for %v login\n", provider)
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	if err := s.SocialLoginStateRepository.Save(ctx, &models.SocialLoginState{
+		State:        state,
+		Provider:     provider,
+		CodeVerifier: codeVerifier,
+		LinkUserID:   linkUserID,
+	}, socialLoginStateExpiration); err != nil {
+		return "", err
+	}
+
+	codeChallenge := sha256.Sum256([]byte(codeVerifier))
+
+	return identityProvider.AuthCodeURL(ctx, state, base64.RawURLEncoding.EncodeToString(codeChallenge[:]))
+}
+
+// finish login or linking
+func (s *socialService) Callback(ctx context.Context, provider string, code string, state string) (*models.User, error) {
+	loginState, err := s.SocialLoginStateRepository.Consume(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	if loginState.Provider != provider {
+		return nil, apperrors.NewAuthorization("Login state is invalid or expired")
+	}
+
+	identityProvider, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := identityProvider.Exchange(ctx, code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.UserIdentityRepository.FindBySubject(ctx, provider, profile.Subject)
+	if err != nil && !isType(err, apperrors.NotFound) {
+		return nil, err
+	}
+
+	if loginState.LinkUserID != uuid.Nil {
+		return s.link(ctx, provider, profile, identity, loginState.LinkUserID)
+	}
+
+	if identity != nil {
+		return s.UserRepository.FindByID(ctx, identity.UserID)
+	}
+
+	user, err := s.createUser(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.UserIdentityRepository.Create(ctx, &models.UserIdentity{
+		Provider: provider,
+		Subject:  profile.Subject,
+		UserID:   user.UID,
+		Email:    profile.Email,
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// one-time code browser is redirected with, so tokens are not put in the url
+func (s *socialService) SigninCode(ctx context.Context, uid uuid.UUID) (string, error) {
+	code, err := randomToken(32)
+	if err != nil {
+		log.Printf("Unable to generate signin code for user: %v\n", uid)
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	if err := s.SocialLoginStateRepository.SaveSigninCode(ctx, code, uid, socialSigninCodeExpiration); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// exchange signin code for its user
+func (s *socialService) Signin(ctx context.Context, code string) (*models.User, error) {
+	uid, err := s.SocialLoginStateRepository.ConsumeSigninCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UserRepository.FindByID(ctx, uid)
+}
+
+// remove link between user and provider
+// users without password or passkey keep their last identity, they could not sign in without it
+func (s *socialService) Unlink(ctx context.Context, provider string, uid uuid.UUID) error {
+	user, err := s.UserRepository.FindByID(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	if !user.HasPassword() {
+		last, err := s.lastSigninMethod(ctx, provider, uid)
+		if err != nil {
+			return err
+		}
+
+		if last {
+			return apperrors.NewForbidden("Identity is the last way to sign in").WithCode(apperrors.LastSigninMethod)
+		}
+	}
+
+	return s.UserIdentityRepository.Delete(ctx, provider, uid)
+}
+
+// tells if user has no other identity and no passkey besides identity of provider
+func (s *socialService) lastSigninMethod(ctx context.Context, provider string, uid uuid.UUID) (bool, error) {
+	identities, err := s.UserIdentityRepository.FindByUserID(ctx, uid)
+	if err != nil {
+		return false, err
+	}
+
+	for _, identity := range identities {
+		if identity.Provider != provider {
+			return false, nil
+		}
+	}
+
+	credentials, err := s.WebAuthnCredentialRepository.FindByUserID(ctx, uid)
+	if err != nil {
+		return false, err
+	}
+
+	return len(credentials) == 0, nil
+}
+
+// providers linked to user
+func (s *socialService) ListIdentities(ctx context.Context, uid uuid.UUID) ([]*models.UserIdentity, error) {
+	return s.UserIdentityRepository.FindByUserID(ctx, uid)
+}
+
+// link external account to signed in user
+func (s *socialService) link(
+	ctx context.Context,
+	provider string,
+	profile *models.ExternalProfile,
+	identity *models.UserIdentity,
+	uid uuid.UUID,
+) (*models.User, error) {
+
+	if identity != nil && identity.UserID != uid {
+		return nil, apperrors.NewConflict("identity", provider)
+	}
+
+	if identity == nil {
+		if err := s.UserIdentityRepository.Create(ctx, &models.UserIdentity{
+			Provider: provider,
+			Subject:  profile.Subject,
+			UserID:   uid,
+			Email:    profile.Email,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.UserRepository.FindByID(ctx, uid)
+}
+
+// create user for first login with external account
+// user has no password, so the identity cannot be unlinked until user adds another way to sign in
+func (s *socialService) createUser(ctx context.Context, profile *models.ExternalProfile) (*models.User, error) {
+	login := loginDisallowedCharacters.ReplaceAllString(profile.Login, "")
+	if login == "" {
+		login = "user"
+	}
+
+	user := &models.User{
+		Login: login,
+	}
+
+	// login from provider may be taken, retry with random suffix
+	for attempt := 0; ; attempt++ {
+		err := s.UserRepository.Create(ctx, user)
+		if err == nil {
+			break
+		}
+
+		if !isType(err, apperrors.Conflict) || attempt == 3 {
+			return nil, err
+		}
+
+		suffix, err := randomToken(3)
+		if err != nil {
+			return nil, apperrors.NewInternal().Wrap(err)
+		}
+
+		user.Login = fmt.Sprintf("%v_%v", login, strings.ToLower(loginDisallowedCharacters.ReplaceAllString(suffix, "")))
+	}
+
+	if profile.Name == "" && profile.Email == "" {
+		return user, nil
+	}
+
+	user.Name = profile.Name
+	user.Email = profile.Email
+
+	if err := s.UserRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *socialService) provider(name string) (models.IdentityProvider, error) {
+	identityProvider, ok := s.IdentityProviders[name]
+	if !ok {
+		return nil, apperrors.NewNotFound("provider", name)
+	}
+
+	return identityProvider, nil
+}
+
+// isType checks type of application error
+func isType(err error, errorType apperrors.Type) bool {
+	var appErr *apperrors.Error
+	return errors.As(err, &appErr) && appErr.Type == errorType
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/providers"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSocialService(test *testing.T) {
+	server := mocks.NewMockOIDCServer("client", "secret", map[string]interface{}{
+		"sub":                "external-id",
+		"preferred_username": "alice",
+		"name":               "Alice",
+		"email":              "alice@mail.com",
+		"email_verified":     true,
+	})
+	defer server.Close()
+
+	identityProvider := providers.NewOIDCProvider(server.URL, &providers.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURI:  "http://localhost/oauth/test/callback",
+	}, nil)
+
+	// runs Start and login at mock provider, returns code, state and saved login state
+	signinAtProvider := func(
+		test *testing.T,
+		socialService models.SocialService,
+		stateRepository *mocks.MockSocialLoginStateRepository,
+		linkUserID uuid.UUID,
+	) (string, string, *models.SocialLoginState) {
+
+		var saved *models.SocialLoginState
+		stateRepository.
+			On("Save", mock.Anything, mock.AnythingOfType("*models.SocialLoginState"), socialLoginStateExpiration).
+			Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*models.SocialLoginState)
+			}).
+			Return(nil).Once()
+
+		authURL, err := socialService.Start(context.Background(), "test", linkUserID)
+		assert.NoError(test, err)
+
+		code, state, err := server.Authorize(authURL)
+		assert.NoError(test, err)
+		assert.Equal(test, saved.State, state)
+
+		return code, state, saved
+	}
+
+	newService := func() (models.SocialService, *mocks.MockUserRepository, *mocks.MockUserIdentityRepository, *mocks.MockSocialLoginStateRepository) {
+		userRepository := new(mocks.MockUserRepository)
+		identityRepository := new(mocks.MockUserIdentityRepository)
+		stateRepository := new(mocks.MockSocialLoginStateRepository)
+
+		socialService := NewSocialService(&SocialServiceConfig{
+			IdentityProviders:          map[string]models.IdentityProvider{"test": identityProvider},
+			UserRepository:             userRepository,
+			UserIdentityRepository:     identityRepository,
+			SocialLoginStateRepository: stateRepository,
+		})
+
+		return socialService, userRepository, identityRepository, stateRepository
+	}
+
+	test.Run("Providers", func(test *testing.T) {
+		socialService, _, _, _ := newService()
+
+		assert.Equal(test, []string{"test"}, socialService.Providers())
+	})
+
+	test.Run("Unknown provider", func(test *testing.T) {
+		socialService, _, _, _ := newService()
+
+		_, err := socialService.Start(context.Background(), "unknown", uuid.Nil)
+		assert.Equal(test, apperrors.NotFound, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("New user", func(test *testing.T) {
+		socialService, userRepository, identityRepository, stateRepository := newService()
+
+		code, state, saved := signinAtProvider(test, socialService, stateRepository, uuid.Nil)
+		stateRepository.On("Consume", mock.Anything, state).Return(saved, nil)
+
+		identityRepository.
+			On("FindBySubject", mock.Anything, "test", "external-id").
+			Return(nil, apperrors.NewNotFound("identity", "test"))
+
+		uid, _ := uuid.NewRandom()
+
+		userRepository.
+			On("Create", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+				return user.Login == "alice"
+			})).
+			Return(apperrors.NewConflict("login", "alice")).Once()
+		userRepository.
+			On("Create", mock.Anything, mock.AnythingOfType("*models.User")).
+			Run(func(args mock.Arguments) {
+				args.Get(1).(*models.User).UID = uid
+			}).
+			Return(nil).Once()
+		userRepository.
+			On("Update", mock.Anything, mock.AnythingOfType("*models.User")).
+			Return(nil)
+		identityRepository.
+			On("Create", mock.Anything, &models.UserIdentity{
+				Provider: "test",
+				Subject:  "external-id",
+				UserID:   uid,
+				Email:    "alice@mail.com",
+			}).
+			Return(nil)
+
+		user, err := socialService.Callback(context.Background(), "test", code, state)
+		assert.NoError(test, err)
+		assert.Equal(test, uid, user.UID)
+		assert.NotEqual(test, "alice", user.Login)
+		assert.Equal(test, "Alice", user.Name)
+		assert.Equal(test, "alice@mail.com", user.Email)
+		assert.False(test, user.HasPassword())
+
+		userRepository.AssertExpectations(test)
+		identityRepository.AssertExpectations(test)
+	})
+
+	test.Run("Existing identity", func(test *testing.T) {
+		socialService, userRepository, identityRepository, stateRepository := newService()
+
+		code, state, saved := signinAtProvider(test, socialService, stateRepository, uuid.Nil)
+		stateRepository.On("Consume", mock.Anything, state).Return(saved, nil)
+
+		uid, _ := uuid.NewRandom()
+		existing := &models.User{UID: uid, Login: "alice"}
+
+		identityRepository.
+			On("FindBySubject", mock.Anything, "test", "external-id").
+			Return(&models.UserIdentity{Provider: "test", Subject: "external-id", UserID: uid}, nil)
+		userRepository.On("FindByID", mock.Anything, uid).Return(existing, nil)
+
+		user, err := socialService.Callback(context.Background(), "test", code, state)
+		assert.NoError(test, err)
+		assert.Equal(test, existing, user)
+
+		userRepository.AssertNotCalled(test, "Create", mock.Anything, mock.Anything)
+		identityRepository.AssertNotCalled(test, "Create", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Link identity", func(test *testing.T) {
+		socialService, userRepository, identityRepository, stateRepository := newService()
+
+		uid, _ := uuid.NewRandom()
+		existing := &models.User{UID: uid, Login: "alice"}
+
+		code, state, saved := signinAtProvider(test, socialService, stateRepository, uid)
+		assert.Equal(test, uid, saved.LinkUserID)
+		stateRepository.On("Consume", mock.Anything, state).Return(saved, nil)
+
+		identityRepository.
+			On("FindBySubject", mock.Anything, "test", "external-id").
+			Return(nil, apperrors.NewNotFound("identity", "test"))
+		identityRepository.
+			On("Create", mock.Anything, mock.AnythingOfType("*models.UserIdentity")).
+			Return(nil)
+		userRepository.On("FindByID", mock.Anything, uid).Return(existing, nil)
+
+		user, err := socialService.Callback(context.Background(), "test", code, state)
+		assert.NoError(test, err)
+		assert.Equal(test, existing, user)
+
+		identityRepository.AssertExpectations(test)
+	})
+
+	test.Run("Link identity of other user", func(test *testing.T) {
+		socialService, _, identityRepository, stateRepository := newService()
+
+		uid, _ := uuid.NewRandom()
+		otherUID, _ := uuid.NewRandom()
+
+		code, state, saved := signinAtProvider(test, socialService, stateRepository, uid)
+		stateRepository.On("Consume", mock.Anything, state).Return(saved, nil)
+
+		identityRepository.
+			On("FindBySubject", mock.Anything, "test", "external-id").
+			Return(&models.UserIdentity{Provider: "test", Subject: "external-id", UserID: otherUID}, nil)
+
+		user, err := socialService.Callback(context.Background(), "test", code, state)
+		assert.Nil(test, user)
+		assert.Equal(test, apperrors.Conflict, err.(*apperrors.Error).Type)
+
+		identityRepository.AssertNotCalled(test, "Create", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Invalid state", func(test *testing.T) {
+		socialService, _, _, stateRepository := newService()
+
+		stateRepository.
+			On("Consume", mock.Anything, "invalid").
+			Return(nil, apperrors.NewAuthorization("Login state is invalid or expired"))
+
+		user, err := socialService.Callback(context.Background(), "test", "code", "invalid")
+		assert.Nil(test, user)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("State of other provider", func(test *testing.T) {
+		socialService, _, _, stateRepository := newService()
+
+		stateRepository.
+			On("Consume", mock.Anything, "state").
+			Return(&models.SocialLoginState{State: "state", Provider: "other"}, nil)
+
+		user, err := socialService.Callback(context.Background(), "test", "code", "state")
+		assert.Nil(test, user)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+}
+
+func TestSocialSigninCode(test *testing.T) {
+	userRepository := new(mocks.MockUserRepository)
+	stateRepository := new(mocks.MockSocialLoginStateRepository)
+
+	socialService := NewSocialService(&SocialServiceConfig{
+		UserRepository:             userRepository,
+		SocialLoginStateRepository: stateRepository,
+	})
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice"}
+
+	var saved string
+	stateRepository.
+		On("SaveSigninCode", mock.Anything, mock.AnythingOfType("string"), uid, socialSigninCodeExpiration).
+		Run(func(args mock.Arguments) {
+			saved = args.String(1)
+		}).
+		Return(nil)
+
+	code, err := socialService.SigninCode(context.Background(), uid)
+	assert.NoError(test, err)
+	assert.Equal(test, saved, code)
+
+	stateRepository.On("ConsumeSigninCode", mock.Anything, code).Return(uid, nil)
+	userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+
+	signedIn, err := socialService.Signin(context.Background(), code)
+	assert.NoError(test, err)
+	assert.Equal(test, user, signedIn)
+}
+
+func TestSocialUnlink(test *testing.T) {
+	uid, _ := uuid.NewRandom()
+
+	newService := func(user *models.User, identities []*models.UserIdentity, credentials []*models.WebAuthnCredential) (models.SocialService, *mocks.MockUserIdentityRepository) {
+		userRepository := new(mocks.MockUserRepository)
+		identityRepository := new(mocks.MockUserIdentityRepository)
+		credentialRepository := new(mocks.MockWebAuthnCredentialRepository)
+
+		userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+		identityRepository.On("FindByUserID", mock.Anything, uid).Return(identities, nil)
+		identityRepository.On("Delete", mock.Anything, "github", uid).Return(nil)
+		credentialRepository.On("FindByUserID", mock.Anything, uid).Return(credentials, nil)
+
+		return NewSocialService(&SocialServiceConfig{
+			UserRepository:               userRepository,
+			UserIdentityRepository:       identityRepository,
+			WebAuthnCredentialRepository: credentialRepository,
+		}), identityRepository
+	}
+
+	github := &models.UserIdentity{Provider: "github", UserID: uid}
+
+	test.Run("User with password", func(test *testing.T) {
+		socialService, identityRepository := newService(&models.User{UID: uid, Password: "hash.salt"}, []*models.UserIdentity{github}, nil)
+
+		err := socialService.Unlink(context.Background(), "github", uid)
+		assert.NoError(test, err)
+		identityRepository.AssertCalled(test, "Delete", mock.Anything, "github", uid)
+	})
+
+	test.Run("User with other identity or passkey", func(test *testing.T) {
+		google := &models.UserIdentity{Provider: "google", UserID: uid}
+		passkey := &models.WebAuthnCredential{UserID: uid}
+
+		socialService, _ := newService(&models.User{UID: uid}, []*models.UserIdentity{github, google}, nil)
+		assert.NoError(test, socialService.Unlink(context.Background(), "github", uid))
+
+		socialService, _ = newService(&models.User{UID: uid}, []*models.UserIdentity{github}, []*models.WebAuthnCredential{passkey})
+		assert.NoError(test, socialService.Unlink(context.Background(), "github", uid))
+	})
+
+	test.Run("Last sign in method", func(test *testing.T) {
+		socialService, identityRepository := newService(&models.User{UID: uid}, []*models.UserIdentity{github}, nil)
+
+		err := socialService.Unlink(context.Background(), "github", uid)
+		assert.Equal(test, apperrors.LastSigninMethod, err.(*apperrors.Error).Code)
+		identityRepository.AssertNotCalled(test, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -3,9 +3,12 @@ package service
 import (
 	"context"
 	"crypto/rsa"
+	"fmt"
 	"log"
 	"memorize/models"
 	"memorize/models/apperrors"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,6 +20,29 @@ type tokenService struct {
 	RefreshSecret             string
 	TokenExpirationSec        int64
 	RefreshTokenExpirationSec int64
+
+	// keys replaced by the last reload, still accepted until their grace period ends
+	previousPublicKey          *rsa.PublicKey
+	previousPublicKeyExpiresAt time.Time
+	previousRefreshSecret      string
+	previousSecretExpiresAt    time.Time
+
+	mutex sync.RWMutex
+}
+
+// ReloadableTokenService is a TokenService whose keys and expirations can be swapped at runtime
+type ReloadableTokenService interface {
+	models.TokenService
+	// replace keys and expirations, returns a description of what changed
+	Reload(config *TokenServiceConfig, gracePeriods GracePeriods) []string
+}
+
+// how long keys replaced by reload are still accepted
+type GracePeriods struct {
+	// access tokens signed with previous key live no longer than their expiration
+	SigningKey time.Duration
+	// refresh tokens live much longer, so previous secret is kept for their lifetime
+	RefreshSecret time.Duration
 }
 
 // parameter for creating token service
@@ -30,6 +56,7 @@ type TokenServiceConfig struct {
 }
 
 // function for initializing a UserService with its repository layer dependencie
+// the returned service also implements ReloadableTokenService
 func NewTokenService(config *TokenServiceConfig) models.TokenService {
 	return &tokenService{
 		TokenRepository:           config.TokenRepository,
@@ -49,6 +76,33 @@ func (s *tokenService) NewPairFromUser(
 	previousTokenID string,
 ) (*models.TokenPair, error) {
 
+	return s.newPair(ctx, user, nil, previousTokenID)
+}
+
+// NewPairForClient creates tokens bound to OAuth client of grant
+// access token carries only claims scope grants, refresh token can be redeemed only by the client
+func (s *tokenService) NewPairForClient(
+	ctx context.Context,
+	user *models.User,
+	grant *models.ClientGrant,
+	previousTokenID string,
+) (*models.TokenPair, error) {
+
+	return s.newPair(ctx, user, grant, previousTokenID)
+}
+
+func (s *tokenService) newPair(
+	ctx context.Context,
+	user *models.User,
+	grant *models.ClientGrant,
+	previousTokenID string,
+) (*models.TokenPair, error) {
+
+	if user.Disabled {
+		log.Printf("Refused to create tokens for disabled user: %v\n", user.UID)
+		return nil, apperrors.NewAuthorization("Account is disabled").WithCode(apperrors.AccountDisabled)
+	}
+
 	if previousTokenID != "" {
 		if err := s.TokenRepository.DeleteRefreshToken(ctx, user.UID.String(), previousTokenID); err != nil {
 			log.Printf("Cold not delete previous refresh token for uid: %v, tokne %v\n", user.UID.String(), previousTokenID)
@@ -57,14 +111,21 @@ func (s *tokenService) NewPairFromUser(
 		}
 	}
 
-	accessToken, err := generateToken(user, s.PrivateKey, s.TokenExpirationSec)
+	s.mutex.RLock()
+	privateKey := s.PrivateKey
+	refreshSecret := s.RefreshSecret
+	tokenExpirationSec := s.TokenExpirationSec
+	refreshTokenExpirationSec := s.RefreshTokenExpirationSec
+	s.mutex.RUnlock()
+
+	accessToken, err := generateClientToken(user, grant, privateKey, tokenExpirationSec)
 
 	if err != nil {
 		log.Printf("Error generating idToken for uid: %v, Error: %v\n", user.UID, err.Error())
-		return nil, apperrors.NewInternal()
+		return nil, apperrors.NewInternal().Wrap(err)
 	}
 
-	refreshToken, err := generateRefreshToken(user.UID, s.RefreshSecret, s.RefreshTokenExpirationSec)
+	refreshToken, err := generateClientRefreshToken(user.UID, grant, refreshSecret, refreshTokenExpirationSec)
 
 	if err != nil {
 		log.Printf("Error genaraating refreshToken for uid: %v. Error %v\n", user.UID, err.Error())
@@ -76,7 +137,7 @@ func (s *tokenService) NewPairFromUser(
 		refreshToken.ExpiresIn,
 	); err != nil {
 		log.Printf("Error storing tokenID for uid: %v. Error: %v\n", user.UID, err.Error())
-		return nil, apperrors.NewInternal()
+		return nil, apperrors.NewInternal().Wrap(err)
 	}
 
 	if previousTokenID != "" {
@@ -91,12 +152,15 @@ func (s *tokenService) NewPairFromUser(
 
 	return &models.TokenPair{
 		AccessToken: models.AccessToken{
-			Token: accessToken,
+			Token:     accessToken,
+			ExpiresIn: tokenExpirationSec,
 		},
 		RefreshToken: models.RefreshToken{
-			ID:     refreshToken.ID,
-			UserID: user.UID,
-			Token:  refreshToken.SignedToken,
+			ID:        refreshToken.ID,
+			UserID:    user.UID,
+			Token:     refreshToken.SignedToken,
+			ExpiresIn: refreshTokenExpirationSec,
+			Grant:     grant,
 		},
 	}, nil
 }
@@ -104,41 +168,251 @@ func (s *tokenService) NewPairFromUser(
 // validates the id token jwt string
 // it returns the user extract from the IDTokenCustomClaims
 func (s *tokenService) ValidateAccessToken(tokenString string) (*models.User, error) {
-	claims, err := validateAccessToken(tokenString, s.PublicKey) // uses public RSA key
+	claims, err := s.accessTokenClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	return userFromAccessToken(claims.User), nil
+}
+
+// AccessTokenGrant returns client and scope of access token issued to OAuth client
+func (s *tokenService) AccessTokenGrant(tokenString string) (*models.ClientGrant, error) {
+	claims, err := s.accessTokenClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.AuthorizedParty == "" {
+		return nil, nil
+	}
+
+	return &models.ClientGrant{
+		ClientID: claims.AuthorizedParty,
+		Scope:    claims.Scope,
+	}, nil
+}
+
+func (s *tokenService) accessTokenClaims(tokenString string) (*idTokenCustomClaims, error) {
+	s.mutex.RLock()
+	publicKey := s.PublicKey
+	previousPublicKey := s.previousPublicKeyLocked()
+	s.mutex.RUnlock()
+
+	claims, err := validateAccessToken(tokenString, publicKey) // uses public RSA key
+
+	// token may have been signed before the key was rotated
+	if err != nil && previousPublicKey != nil {
+		claims, err = validateAccessToken(tokenString, previousPublicKey)
+	}
 
 	if err != nil {
 		log.Printf("Unable to validate or parse idToken - Error: %v\n", err)
-		return nil, apperrors.NewAuthorization("Unable to verify user from idToken")
+		return nil, apperrors.NewAuthorization("Unable to verify user from idToken").WithCode(apperrors.InvalidToken)
+	}
+
+	return claims, nil
+}
+
+// NewServiceToken creates access token for service client
+func (s *tokenService) NewServiceToken(principal *models.ServicePrincipal, issuer string) (*models.AccessToken, error) {
+	s.mutex.RLock()
+	privateKey := s.PrivateKey
+	tokenExpirationSec := s.TokenExpirationSec
+	s.mutex.RUnlock()
+
+	token, err := generateServiceToken(principal, issuer, privateKey, tokenExpirationSec)
+	if err != nil {
+		log.Printf("Error generating service token for client: %v. Error: %v\n", principal.ClientID, err.Error())
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	return &models.AccessToken{
+		Token:     token,
+		ExpiresIn: tokenExpirationSec,
+	}, nil
+}
+
+// ValidateServiceToken checks service token and that it was issued for audience
+func (s *tokenService) ValidateServiceToken(tokenString string, audience string) (*models.ServicePrincipal, error) {
+	s.mutex.RLock()
+	publicKey := s.PublicKey
+	previousPublicKey := s.previousPublicKeyLocked()
+	s.mutex.RUnlock()
+
+	claims, err := validateServiceToken(tokenString, publicKey, audience)
+
+	if err != nil && previousPublicKey != nil {
+		claims, err = validateServiceToken(tokenString, previousPublicKey, audience)
+	}
+
+	if err != nil {
+		log.Printf("Unable to validate or parse service token - Error: %v\n", err)
+		return nil, apperrors.NewAuthorization("Unable to verify service from token")
 	}
 
-	return claims.User, nil
+	return &models.ServicePrincipal{
+		ClientID: claims.ClientID,
+		Audience: audience,
+	}, nil
 }
 
 // ValidateRefreshToken checks to make sure the JWT provided by a string is valid
 // and returns a RefreshToken if valid
 func (s *tokenService) ValidateRefreshToken(tokenString string) (*models.RefreshToken, error) {
-	claims, err := validateRefreshToken(tokenString, s.RefreshSecret)
+	s.mutex.RLock()
+	refreshSecret := s.RefreshSecret
+	previousRefreshSecret := s.previousRefreshSecretLocked()
+	s.mutex.RUnlock()
+
+	claims, err := validateRefreshToken(tokenString, refreshSecret)
+
+	// token may have been signed before the secret was rotated
+	if err != nil && previousRefreshSecret != "" {
+		claims, err = validateRefreshToken(tokenString, previousRefreshSecret)
+	}
 
 	if err != nil {
 		log.Printf("Unable to validate or parse refreshToken for token string: %s\n%v\n", tokenString, err)
-		return nil, apperrors.NewAuthorization("Unable to verify user from refresh token")
+		return nil, apperrors.NewAuthorization("Unable to verify user from refresh token").WithCode(apperrors.InvalidRefreshToken)
 	}
 
 	tokenUUID, err := uuid.Parse(claims.Id)
 
 	if err != nil {
 		log.Printf("Claims ID could not be parsed as UUID: %s\n%v\n", claims.Id, err)
-		return nil, apperrors.NewAuthorization("Unable to verify user from refresh token")
+		return nil, apperrors.NewAuthorization("Unable to verify user from refresh token").WithCode(apperrors.InvalidRefreshToken)
 	}
 
-	return &models.RefreshToken{
+	refreshToken := &models.RefreshToken{
 		Token:  tokenString,
 		ID:     tokenUUID,
 		UserID: claims.UserID,
-	}, nil
+	}
+
+	if claims.ClientID != "" {
+		refreshToken.Grant = &models.ClientGrant{
+			ClientID: claims.ClientID,
+			Scope:    claims.Scope,
+		}
+	}
+
+	return refreshToken, nil
 }
 
 // Signout delete all user tokens from token repository
 func (s *tokenService) Signout(ctx context.Context, userID uuid.UUID) error {
 	return s.TokenRepository.DeleteUserRefreshTokens(ctx, userID.String())
 }
+
+// ListSessions returns users refresh tokens
+func (s *tokenService) ListSessions(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	return s.TokenRepository.ListRefreshTokens(ctx, userID.String())
+}
+
+// RevokeSession delete single refresh token of a user
+func (s *tokenService) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	return s.TokenRepository.DeleteRefreshToken(ctx, userID.String(), sessionID)
+}
+
+// NewIDToken creates OpenID Connect id token signed with the same key as access tokens
+func (s *tokenService) NewIDToken(user *models.User, options *models.IDTokenOptions) (string, error) {
+	s.mutex.RLock()
+	privateKey := s.PrivateKey
+	tokenExpirationSec := s.TokenExpirationSec
+	s.mutex.RUnlock()
+
+	idToken, err := generateIDToken(user, privateKey, options, tokenExpirationSec)
+
+	if err != nil {
+		log.Printf("Error generating oidc id token for uid: %v, Error: %v\n", user.UID, err.Error())
+		return "", apperrors.NewInternal().Wrap(err)
+	}
+
+	return idToken, nil
+}
+
+// JWKS returns current public key and the previous one during its grace period
+func (s *tokenService) JWKS() *models.JSONWebKeySet {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keySet := &models.JSONWebKeySet{
+		Keys: []models.JSONWebKey{},
+	}
+
+	for _, key := range []*rsa.PublicKey{s.PublicKey, s.previousPublicKeyLocked()} {
+		if key == nil {
+			continue
+		}
+
+		jwk := jsonWebKey(key)
+		jwk.KeyID = keyID(key)
+		keySet.Keys = append(keySet.Keys, jwk)
+	}
+
+	return keySet
+}
+
+// Reload swaps keys, refresh secret and expirations
+// tokens signed with the replaced key or secret stay valid for their grace periods
+func (s *tokenService) Reload(config *TokenServiceConfig, gracePeriods GracePeriods) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var changes []string
+
+	if s.PublicKey == nil || !s.PublicKey.Equal(config.PublicKey) {
+		changes = append(changes, "signing key")
+		s.previousPublicKey = s.PublicKey
+		s.previousPublicKeyExpiresAt = time.Now().Add(gracePeriods.SigningKey)
+	}
+
+	if s.RefreshSecret != config.RefreshSecret {
+		changes = append(changes, "refresh secret")
+		s.previousRefreshSecret = s.RefreshSecret
+		s.previousSecretExpiresAt = time.Now().Add(gracePeriods.RefreshSecret)
+	}
+
+	if s.TokenExpirationSec != config.TokenExpirationSec {
+		changes = append(changes, fmt.Sprintf(
+			"token expiration %vs -> %vs",
+			s.TokenExpirationSec,
+			config.TokenExpirationSec,
+		))
+	}
+
+	if s.RefreshTokenExpirationSec != config.RefreshTokenExpirationSec {
+		changes = append(changes, fmt.Sprintf(
+			"refresh token expiration %vs -> %vs",
+			s.RefreshTokenExpirationSec,
+			config.RefreshTokenExpirationSec,
+		))
+	}
+
+	s.PrivateKey = config.PrivateKey
+	s.PublicKey = config.PublicKey
+	s.RefreshSecret = config.RefreshSecret
+	s.TokenExpirationSec = config.TokenExpirationSec
+	s.RefreshTokenExpirationSec = config.RefreshTokenExpirationSec
+
+	return changes
+}
+
+// previous public key if grace period is not over, caller must hold the mutex
+func (s *tokenService) previousPublicKeyLocked() *rsa.PublicKey {
+	if time.Now().After(s.previousPublicKeyExpiresAt) {
+		return nil
+	}
+
+	return s.previousPublicKey
+}
+
+// previous refresh secret if grace period is not over, caller must hold the mutex
+func (s *tokenService) previousRefreshSecretLocked() string {
+	if time.Now().After(s.previousSecretExpiresAt) {
+		return ""
+	}
+
+	return s.previousRefreshSecret
+}
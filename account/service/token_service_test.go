@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"fmt"
 	"io/ioutil"
 	"memorize/mocks"
@@ -120,7 +122,12 @@ func TestNewPairFromUser(test *testing.T) {
 		}
 
 		assert.ElementsMatch(test, expectedClaims, actualIDClaims)
-		assert.Empty(test, idTokenClaims.User.Password)
+
+		// password is not a claim of access token
+		mapClaims := jwt.MapClaims{}
+		_, _, err = new(jwt.Parser).ParseUnverified(tokenPair.AccessToken.Token, mapClaims)
+		assert.NoError(test, err)
+		assert.NotContains(test, mapClaims["user"], "password")
 
 		expiresAt := time.Unix(idTokenClaims.StandardClaims.ExpiresAt, 0)
 		expectedExpiresAt := time.Now().Add(time.Duration(tokenExpiration) * time.Second)
@@ -202,7 +209,7 @@ func TestValidateToken(test *testing.T) {
 	test.Run("Expired token", func(test *testing.T) {
 		token, _ := generateToken(user, privateKey, -1)
 
-		expectedErr := apperrors.NewAuthorization("Unable to verify user from idToken")
+		expectedErr := apperrors.NewAuthorization("Unable to verify user from idToken").WithCode(apperrors.InvalidToken)
 
 		_, err := tokenService.ValidateAccessToken(token)
 		assert.EqualError(test, err, expectedErr.Message)
@@ -211,7 +218,7 @@ func TestValidateToken(test *testing.T) {
 	test.Run("Invalid signature", func(test *testing.T) {
 		token, _ := generateToken(user, privateKey, -1)
 
-		expectedErr := apperrors.NewAuthorization("Unable to verify user from idToken")
+		expectedErr := apperrors.NewAuthorization("Unable to verify user from idToken").WithCode(apperrors.InvalidToken)
 
 		_, err := tokenService.ValidateAccessToken(token)
 		assert.EqualError(test, err, expectedErr.Message)
@@ -248,7 +255,7 @@ func TestValidateRefreshToken(test *testing.T) {
 	test.Run("Expired token", func(test *testing.T) {
 		testRefreshToken, _ := generateRefreshToken(user.UID, secret, -1)
 
-		expectedErr := apperrors.NewAuthorization("Unable to verify user from refresh token")
+		expectedErr := apperrors.NewAuthorization("Unable to verify user from refresh token").WithCode(apperrors.InvalidRefreshToken)
 
 		_, err := tokenService.ValidateRefreshToken(testRefreshToken.SignedToken)
 		assert.EqualError(test, err, expectedErr.Message)
@@ -288,3 +295,205 @@ func TestSignout(test *testing.T) {
 		assert.Equal(test, apperr.Type, apperrors.Internal)
 	})
 }
+
+func TestReload(test *testing.T) {
+	var idExp int64 = 15 * 60
+	var refreshExp int64 = 3 * 24 * 60 * 60
+
+	oldPrivateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newPrivateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	oldSecret := "oldrefreshsecret"
+	newSecret := "newrefreshsecret"
+
+	newConfig := func() *TokenServiceConfig {
+		return &TokenServiceConfig{
+			PrivateKey:                oldPrivateKey,
+			PublicKey:                 &oldPrivateKey.PublicKey,
+			RefreshSecret:             oldSecret,
+			TokenExpirationSec:        idExp,
+			RefreshTokenExpirationSec: refreshExp,
+		}
+	}
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{
+		UID:   uid,
+		Login: "alice",
+	}
+
+	test.Run("Nothing changed", func(test *testing.T) {
+		tokenService := NewTokenService(newConfig()).(ReloadableTokenService)
+
+		changes := tokenService.Reload(newConfig(), GracePeriods{SigningKey: time.Minute, RefreshSecret: time.Minute})
+		assert.Empty(test, changes)
+	})
+
+	test.Run("Previous key accepted during grace period", func(test *testing.T) {
+		tokenService := NewTokenService(newConfig()).(ReloadableTokenService)
+
+		oldToken, _ := generateToken(user, oldPrivateKey, idExp)
+		oldRefreshToken, _ := generateRefreshToken(user.UID, oldSecret, refreshExp)
+
+		config := newConfig()
+		config.PrivateKey = newPrivateKey
+		config.PublicKey = &newPrivateKey.PublicKey
+		config.RefreshSecret = newSecret
+		config.TokenExpirationSec = 60
+
+		changes := tokenService.Reload(config, GracePeriods{SigningKey: time.Minute, RefreshSecret: time.Minute})
+		assert.Len(test, changes, 3)
+
+		_, err := tokenService.ValidateAccessToken(oldToken)
+		assert.NoError(test, err)
+
+		_, err = tokenService.ValidateRefreshToken(oldRefreshToken.SignedToken)
+		assert.NoError(test, err)
+
+		newToken, _ := generateToken(user, newPrivateKey, idExp)
+		_, err = tokenService.ValidateAccessToken(newToken)
+		assert.NoError(test, err)
+	})
+
+	test.Run("Previous key rejected after grace period", func(test *testing.T) {
+		tokenService := NewTokenService(newConfig()).(ReloadableTokenService)
+
+		oldToken, _ := generateToken(user, oldPrivateKey, idExp)
+		oldRefreshToken, _ := generateRefreshToken(user.UID, oldSecret, refreshExp)
+
+		config := newConfig()
+		config.PrivateKey = newPrivateKey
+		config.PublicKey = &newPrivateKey.PublicKey
+		config.RefreshSecret = newSecret
+
+		tokenService.Reload(config, GracePeriods{SigningKey: -time.Second, RefreshSecret: -time.Second})
+
+		_, err := tokenService.ValidateAccessToken(oldToken)
+		assert.Error(test, err)
+
+		_, err = tokenService.ValidateRefreshToken(oldRefreshToken.SignedToken)
+		assert.Error(test, err)
+	})
+
+	test.Run("Previous refresh secret outlives previous key", func(test *testing.T) {
+		tokenService := NewTokenService(newConfig()).(ReloadableTokenService)
+
+		oldToken, _ := generateToken(user, oldPrivateKey, idExp)
+		oldRefreshToken, _ := generateRefreshToken(user.UID, oldSecret, refreshExp)
+
+		config := newConfig()
+		config.PrivateKey = newPrivateKey
+		config.PublicKey = &newPrivateKey.PublicKey
+		config.RefreshSecret = newSecret
+
+		tokenService.Reload(config, GracePeriods{SigningKey: -time.Second, RefreshSecret: time.Hour})
+
+		_, err := tokenService.ValidateAccessToken(oldToken)
+		assert.Error(test, err)
+
+		_, err = tokenService.ValidateRefreshToken(oldRefreshToken.SignedToken)
+		assert.NoError(test, err)
+	})
+}
+
+func TestNewPairFromDisabledUser(test *testing.T) {
+	mockTokenRepository := new(mocks.MockTokenRepository)
+	tokenService := NewTokenService(&TokenServiceConfig{
+		TokenRepository: mockTokenRepository,
+	})
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{
+		UID:      uid,
+		Login:    "alice",
+		Disabled: true,
+	}
+
+	tokens, err := tokenService.NewPairFromUser(context.Background(), user, "")
+
+	assert.Nil(test, tokens)
+	assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	mockTokenRepository.AssertNotCalled(test, "SetRefreshToken")
+}
+
+func TestNewPairForClient(test *testing.T) {
+	privateKey, publicKey := testKeyPair()
+
+	mockTokenRepository := new(mocks.MockTokenRepository)
+	mockTokenRepository.On("SetRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	tokenService := NewTokenService(&TokenServiceConfig{
+		TokenRepository:           mockTokenRepository,
+		PrivateKey:                privateKey,
+		PublicKey:                 publicKey,
+		RefreshSecret:             "refreshsecret",
+		TokenExpirationSec:        60,
+		RefreshTokenExpirationSec: 60,
+	})
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{UID: uid, Login: "alice", Name: "Alice", Email: "alice@mail.com"}
+	grant := &models.ClientGrant{ClientID: "client", Scope: "openid email"}
+
+	tokens, err := tokenService.NewPairForClient(context.Background(), user, grant, "")
+	assert.NoError(test, err)
+
+	test.Run("Access token has claims of scope", func(test *testing.T) {
+		tokenUser, err := tokenService.ValidateAccessToken(tokens.AccessToken.Token)
+		assert.NoError(test, err)
+		assert.Equal(test, "alice@mail.com", tokenUser.Email)
+		assert.Empty(test, tokenUser.Name)
+
+		tokenGrant, err := tokenService.AccessTokenGrant(tokens.AccessToken.Token)
+		assert.NoError(test, err)
+		assert.Equal(test, grant, tokenGrant)
+	})
+
+	test.Run("Refresh token is bound to client", func(test *testing.T) {
+		refreshToken, err := tokenService.ValidateRefreshToken(tokens.RefreshToken.Token)
+		assert.NoError(test, err)
+		assert.Equal(test, grant, refreshToken.Grant)
+	})
+
+	test.Run("Tokens of account service have no grant", func(test *testing.T) {
+		accessToken, _ := generateToken(user, privateKey, 60)
+		refreshToken, _ := generateRefreshToken(uid, "refreshsecret", 60)
+
+		tokenGrant, err := tokenService.AccessTokenGrant(accessToken)
+		assert.NoError(test, err)
+		assert.Nil(test, tokenGrant)
+
+		validated, err := tokenService.ValidateRefreshToken(refreshToken.SignedToken)
+		assert.NoError(test, err)
+		assert.Nil(test, validated.Grant)
+	})
+}
+
+func TestSessions(test *testing.T) {
+	mockTokenRepository := new(mocks.MockTokenRepository)
+	tokenService := NewTokenService(&TokenServiceConfig{
+		TokenRepository: mockTokenRepository,
+	})
+
+	uid, _ := uuid.NewRandom()
+
+	test.Run("List", func(test *testing.T) {
+		sessions := []*models.Session{
+			{ID: "first", ExpiresIn: 3600},
+			{ID: "second", ExpiresIn: 60},
+		}
+
+		mockTokenRepository.On("ListRefreshTokens", mock.Anything, uid.String()).Return(sessions, nil)
+
+		result, err := tokenService.ListSessions(context.Background(), uid)
+		assert.NoError(test, err)
+		assert.Equal(test, sessions, result)
+	})
+
+	test.Run("Revoke", func(test *testing.T) {
+		mockTokenRepository.On("DeleteRefreshToken", mock.Anything, uid.String(), "first").Return(nil)
+
+		err := tokenService.RevokeSession(context.Background(), uid, "first")
+		assert.NoError(test, err)
+		mockTokenRepository.AssertCalled(test, "DeleteRefreshToken", mock.Anything, uid.String(), "first")
+	})
+}
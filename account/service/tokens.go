@@ -2,35 +2,53 @@ package service
 
 import (
 	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"log"
+	"math/big"
 	"memorize/models"
 	"time"
 
+	"github.com/TheTenzou/memorize/account/tokenverifier"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 )
 
-// token claims
-type idTokenCustomClaims struct {
-	User *models.User `json:"user"`
-	jwt.StandardClaims
-}
+// token claims, shared with services that verify access tokens
+type idTokenCustomClaims = tokenverifier.Claims
 
 // generateToken generates an IDToken which is a jwt with myCustomClaims
 func generateToken(user *models.User, key *rsa.PrivateKey, expiration int64) (string, error) {
+	return generateClientToken(user, nil, key, expiration)
+}
+
+// generateClientToken generates access token of OAuth client, user in it has only claims grant allows
+func generateClientToken(
+	user *models.User,
+	grant *models.ClientGrant,
+	key *rsa.PrivateKey,
+	expiration int64,
+) (string, error) {
+
 	unixTime := time.Now().Unix()
 	tokenExpire := unixTime + expiration
 
 	claims := idTokenCustomClaims{
-		User: user,
+		User: accessTokenUser(grant.User(user)),
 		StandardClaims: jwt.StandardClaims{
 			IssuedAt:  unixTime,
 			ExpiresAt: tokenExpire,
 		},
 	}
 
+	if grant != nil {
+		claims.AuthorizedParty = grant.ClientID
+		claims.Scope = grant.Scope
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	setKeyID(token, key)
 	signedToken, err := token.SignedString(key)
 
 	if err != nil {
@@ -41,6 +59,157 @@ func generateToken(user *models.User, key *rsa.PrivateKey, expiration int64) (st
 	return signedToken, nil
 }
 
+// accessTokenUser is user as access tokens carry it, without fields that are not sent to clients
+func accessTokenUser(user *models.User) *tokenverifier.User {
+	return &tokenverifier.User{
+		UID:          user.UID,
+		Login:        user.Login,
+		Email:        user.Email,
+		Name:         user.Name,
+		ImageURL:     user.ImageURL,
+		Website:      user.Website,
+		SecondFactor: user.SecondFactor,
+		Locale:       user.Locale,
+		UpdatedAt:    user.UpdatedAt,
+	}
+}
+
+// userFromAccessToken is user of validated access token
+func userFromAccessToken(user *tokenverifier.User) *models.User {
+	return &models.User{
+		UID:          user.UID,
+		Login:        user.Login,
+		Email:        user.Email,
+		Name:         user.Name,
+		ImageURL:     user.ImageURL,
+		Website:      user.Website,
+		SecondFactor: user.SecondFactor,
+		Locale:       user.Locale,
+		UpdatedAt:    user.UpdatedAt,
+	}
+}
+
+// OpenID Connect id token claims
+type oidcTokenClaims struct {
+	Nonce             string `json:"nonce,omitempty"`
+	AuthTime          int64  `json:"auth_time,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Name              string `json:"name,omitempty"`
+	Email             string `json:"email,omitempty"`
+	Website           string `json:"website,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	Locale            string `json:"locale,omitempty"`
+	jwt.StandardClaims
+}
+
+// generateIDToken generates an OpenID Connect id token for the client in options
+func generateIDToken(
+	user *models.User,
+	key *rsa.PrivateKey,
+	options *models.IDTokenOptions,
+	expiration int64,
+) (string, error) {
+
+	unixTime := time.Now().Unix()
+
+	claims := oidcTokenClaims{
+		Nonce:             options.Nonce,
+		AuthTime:          options.AuthTime,
+		PreferredUsername: user.Login,
+		Name:              user.Name,
+		Email:             user.Email,
+		Website:           user.Website,
+		Picture:           user.ImageURL,
+		Locale:            user.Locale,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    options.Issuer,
+			Subject:   user.UID.String(),
+			Audience:  options.Audience,
+			IssuedAt:  unixTime,
+			ExpiresAt: unixTime + expiration,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	setKeyID(token, key)
+	signedToken, err := token.SignedString(key)
+
+	if err != nil {
+		log.Println("Failed to sign oidc id token string")
+		return "", err
+	}
+
+	return signedToken, nil
+}
+
+// service access token claims, subject is the client id
+type serviceTokenClaims struct {
+	ClientID string `json:"client_id"`
+	jwt.StandardClaims
+}
+
+// generateServiceToken generates an access token restricted to principal's audience
+func generateServiceToken(
+	principal *models.ServicePrincipal,
+	issuer string,
+	key *rsa.PrivateKey,
+	expiration int64,
+) (string, error) {
+
+	unixTime := time.Now().Unix()
+
+	claims := serviceTokenClaims{
+		ClientID: principal.ClientID,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    issuer,
+			Subject:   principal.ClientID,
+			Audience:  principal.Audience,
+			IssuedAt:  unixTime,
+			ExpiresAt: unixTime + expiration,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	setKeyID(token, key)
+	signedToken, err := token.SignedString(key)
+
+	if err != nil {
+		log.Println("Failed to sign service token string")
+		return "", err
+	}
+
+	return signedToken, nil
+}
+
+// put kid header so verifiers can pick the key from JWKS
+func setKeyID(token *jwt.Token, key *rsa.PrivateKey) {
+	if key != nil {
+		token.Header["kid"] = keyID(&key.PublicKey)
+	}
+}
+
+// keyID is RFC 7638 thumbprint of the public key
+func keyID(key *rsa.PublicKey) string {
+	jwk := jsonWebKey(key)
+
+	// members in lexicographic order as required by RFC 7638
+	thumbprintInput := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.Exponent, jwk.Modulus)
+	thumbprint := sha256.Sum256([]byte(thumbprintInput))
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint[:])
+}
+
+// jsonWebKey encodes public key without kid
+func jsonWebKey(key *rsa.PublicKey) models.JSONWebKey {
+	return models.JSONWebKey{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: "RS256",
+		Modulus:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
 // refreshTokenData holds the actual signed jwt string along with the ID
 // We return the id so it can be used without re-parsing the JWT from signed string
 type refreshTokenData struct {
@@ -51,12 +220,26 @@ type refreshTokenData struct {
 
 type refreshTokenCustomClaims struct {
 	UserID uuid.UUID `json:"uid"`
+	// OAuth client the token was issued to and its scope, only that client can redeem it
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
 	jwt.StandardClaims
 }
 
 // generateRefreshToken creates a refresh token
 // The refresh token stores only the user's ID, a string
 func generateRefreshToken(uid uuid.UUID, key string, expiration int64) (*refreshTokenData, error) {
+	return generateClientRefreshToken(uid, nil, key, expiration)
+}
+
+// generateClientRefreshToken creates a refresh token bound to OAuth client of grant
+func generateClientRefreshToken(
+	uid uuid.UUID,
+	grant *models.ClientGrant,
+	key string,
+	expiration int64,
+) (*refreshTokenData, error) {
+
 	currentTime := time.Now()
 	tokenExp := currentTime.Add(time.Duration(expiration) * time.Second)
 	tokenID, err := uuid.NewRandom()
@@ -75,6 +258,11 @@ func generateRefreshToken(uid uuid.UUID, key string, expiration int64) (*refresh
 		},
 	}
 
+	if grant != nil {
+		claims.ClientID = grant.ClientID
+		claims.Scope = grant.Scope
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signedToken, err := token.SignedString([]byte(key))
 
@@ -90,9 +278,25 @@ func generateRefreshToken(uid uuid.UUID, key string, expiration int64) (*refresh
 	}, nil
 }
 
+// tokenLifetime reads issued at and expiration time of token that was already validated
+func tokenLifetime(tokenString string) (int64, int64) {
+	claims := &jwt.StandardClaims{}
+
+	if _, _, err := new(jwt.Parser).ParseUnverified(tokenString, claims); err != nil {
+		return 0, 0
+	}
+
+	return claims.IssuedAt, claims.ExpiresAt
+}
+
 // returns the token's claims if the token is valid
 func validateAccessToken(tokenString string, key *rsa.PublicKey) (*idTokenCustomClaims, error) {
-	claims := &idTokenCustomClaims{}
+	return tokenverifier.ParseAccessToken(tokenString, key)
+}
+
+// returns the service token's claims if the token is valid and issued for audience
+func validateServiceToken(tokenString string, key *rsa.PublicKey, audience string) (*serviceTokenClaims, error) {
+	claims := &serviceTokenClaims{}
 
 	token, err := jwt.ParseWithClaims(
 		tokenString, claims,
@@ -106,13 +310,16 @@ func validateAccessToken(tokenString string, key *rsa.PublicKey) (*idTokenCustom
 	}
 
 	if !token.Valid {
-		return nil, fmt.Errorf("ID token is invalid")
+		return nil, fmt.Errorf("service token is invalid")
 	}
 
-	claims, ok := token.Claims.(*idTokenCustomClaims)
+	// user access tokens have no client id
+	if claims.ClientID == "" || claims.Subject != claims.ClientID {
+		return nil, fmt.Errorf("token is not a service token")
+	}
 
-	if !ok {
-		return nil, fmt.Errorf("ID token valid but couldn't parse claims")
+	if !claims.VerifyAudience(audience, true) {
+		return nil, fmt.Errorf("service token is issued for %v", claims.Audience)
 	}
 
 	return claims, nil
@@ -141,3 +348,65 @@ func validateRefreshToken(tokenString string, key string) (*refreshTokenCustomCl
 
 	return claims, nil
 }
+
+// audience of signin links, keeps them apart from other tokens signed with HMAC
+const magicLinkAudience = "magic_link"
+
+type magicLinkClaims struct {
+	// hash of nonce kept by device that requested the link
+	NonceHash string `json:"nonce"`
+	jwt.StandardClaims
+}
+
+// generateMagicLinkToken creates signed token of signin link for user
+func generateMagicLinkToken(uid uuid.UUID, linkID string, nonceHash string, key []byte, expiresIn time.Duration) (string, error) {
+	currentTime := time.Now()
+
+	claims := magicLinkClaims{
+		NonceHash: nonceHash,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   uid.String(),
+			Audience:  magicLinkAudience,
+			IssuedAt:  currentTime.Unix(),
+			ExpiresAt: currentTime.Add(expiresIn).Unix(),
+			Id:        linkID,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signedToken, err := token.SignedString(key)
+
+	if err != nil {
+		log.Println("Failed to sign magic link token string")
+		return "", err
+	}
+
+	return signedToken, nil
+}
+
+// returns the signin link's claims if its token is valid
+func validateMagicLinkToken(tokenString string, key []byte) (*magicLinkClaims, error) {
+	claims := &magicLinkClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+
+		return key, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, fmt.Errorf("magic link token is invalid")
+	}
+
+	if !claims.VerifyAudience(magicLinkAudience, true) || claims.Id == "" || claims.NonceHash == "" {
+		return nil, fmt.Errorf("token is not a magic link token")
+	}
+
+	return claims, nil
+}
@@ -6,7 +6,9 @@ import (
 	"memorize/mocks"
 	"memorize/models"
 	"memorize/models/apperrors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -259,3 +261,265 @@ func TestUpdateDetails(test *testing.T) {
 		mockUserRepository.AssertCalled(test, "Update", mockArguments...)
 	})
 }
+
+func TestPatchDetails(test *testing.T) {
+	mockUserRepository := new(mocks.MockUserRepository)
+	userService := NewUserService(&UserServiceConfig{
+		UserRepository: mockUserRepository,
+	})
+
+	uid, _ := uuid.NewRandom()
+
+	patch := &models.UserPatch{
+		UID:    uid,
+		Fields: []string{models.UserWebsite},
+	}
+	mockUser := &models.User{UID: uid, Name: "Alice"}
+
+	mockUserRepository.
+		On("UpdateFields", mock.Anything, patch).
+		Return(mockUser, nil)
+
+	user, err := userService.PatchDetails(context.TODO(), patch)
+
+	assert.NoError(test, err)
+	assert.Equal(test, mockUser, user)
+	mockUserRepository.AssertCalled(test, "UpdateFields", mock.Anything, patch)
+}
+
+func TestSigninDisabled(test *testing.T) {
+	login := "disabled"
+	password := "testPasswrod"
+	hashedPassword, _ := HashPassword(password)
+
+	mockUserRepository := new(mocks.MockUserRepository)
+	userService := NewUserService(&UserServiceConfig{
+		UserRepository: mockUserRepository,
+	})
+
+	uid, _ := uuid.NewRandom()
+	mockUserRepository.
+		On("FindByLogin", mock.Anything, login).
+		Return(&models.User{
+			UID:      uid,
+			Login:    login,
+			Password: hashedPassword,
+			Disabled: true,
+		}, nil)
+
+	ctx := context.TODO()
+
+	test.Run("Correct password", func(test *testing.T) {
+		loginUser, err := userService.Signin(ctx, &models.User{Login: login, Password: password})
+
+		assert.Nil(test, loginUser)
+		assert.EqualError(test, err, "Account is disabled")
+	})
+
+	test.Run("Wrong password does not tell account is disabled", func(test *testing.T) {
+		loginUser, err := userService.Signin(ctx, &models.User{Login: login, Password: "wrongPassword"})
+
+		assert.Nil(test, loginUser)
+		assert.Equal(test, apperrors.InvalidCredentials, err.(*apperrors.Error).Code)
+	})
+}
+
+func TestUpdatePassword(test *testing.T) {
+	mockUserRepository := new(mocks.MockUserRepository)
+	userService := NewUserService(&UserServiceConfig{
+		UserRepository: mockUserRepository,
+	})
+
+	test.Run("Stores hashed password", func(test *testing.T) {
+		uid, _ := uuid.NewRandom()
+		password := "newpassword"
+
+		var storedPassword string
+		mockUserRepository.
+			On("UpdatePassword", mock.Anything, uid, mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) {
+				storedPassword = args.Get(2).(string)
+			}).
+			Return(nil)
+
+		err := userService.UpdatePassword(context.TODO(), uid, password)
+		assert.NoError(test, err)
+
+		assert.NotEqual(test, password, storedPassword)
+		match, err := comparePasswords(storedPassword, password)
+		assert.NoError(test, err)
+		assert.True(test, match)
+	})
+
+	test.Run("Repository error", func(test *testing.T) {
+		uid, _ := uuid.NewRandom()
+		mockError := apperrors.NewNotFound("uid", uid.String())
+
+		mockUserRepository.
+			On("UpdatePassword", mock.Anything, uid, mock.AnythingOfType("string")).
+			Return(mockError)
+
+		err := userService.UpdatePassword(context.TODO(), uid, "newpassword")
+		assert.Equal(test, mockError, err)
+	})
+}
+
+func TestSetDisabled(test *testing.T) {
+	mockUserRepository := new(mocks.MockUserRepository)
+	userService := NewUserService(&UserServiceConfig{
+		UserRepository: mockUserRepository,
+	})
+
+	uid, _ := uuid.NewRandom()
+	mockUserRepository.On("UpdateDisabled", mock.Anything, uid, true).Return(nil)
+
+	err := userService.SetDisabled(context.TODO(), uid, true)
+
+	assert.NoError(test, err)
+	mockUserRepository.AssertCalled(test, "UpdateDisabled", mock.Anything, uid, true)
+}
+
+func TestGetUserByLogin(test *testing.T) {
+	uid, _ := uuid.NewRandom()
+	mockUser := &models.User{UID: uid, Login: "alice2"}
+
+	mockUserRepository := new(mocks.MockUserRepository)
+	userService := NewUserService(&UserServiceConfig{
+		UserRepository: mockUserRepository,
+	})
+
+	mockUserRepository.On("FindByLogin", mock.Anything, "alice2").Return(mockUser, nil)
+	mockUserRepository.On("FindByLogin", mock.Anything, mock.Anything).Return(nil, apperrors.NewNotFound("login", "alice"))
+	mockUserRepository.On("FindByPreviousLogin", mock.Anything, "alice").Return(mockUser, nil)
+	mockUserRepository.On("FindByPreviousLogin", mock.Anything, "bob").Return(nil, apperrors.NewNotFound("login", "bob"))
+
+	test.Run("Current login", func(test *testing.T) {
+		user, err := userService.GetUserByLogin(context.TODO(), "alice2")
+
+		assert.NoError(test, err)
+		assert.Equal(test, mockUser, user)
+		mockUserRepository.AssertNotCalled(test, "FindByPreviousLogin", mock.Anything, "alice2")
+	})
+
+	test.Run("Reserved previous login", func(test *testing.T) {
+		user, err := userService.GetUserByLogin(context.TODO(), "alice")
+
+		assert.NoError(test, err)
+		assert.Equal(test, mockUser, user)
+	})
+
+	test.Run("Unknown login", func(test *testing.T) {
+		user, err := userService.GetUserByLogin(context.TODO(), "bob")
+
+		assert.Nil(test, user)
+		assert.Equal(test, http.StatusNotFound, apperrors.Status(err))
+	})
+}
+
+func TestChangeLogin(test *testing.T) {
+	uid, _ := uuid.NewRandom()
+
+	test.Run("Previous login is reserved", func(test *testing.T) {
+		mockUserRepository := new(mocks.MockUserRepository)
+		userService := NewUserService(&UserServiceConfig{
+			UserRepository:   mockUserRepository,
+			LoginReservation: time.Hour,
+		})
+
+		mockUser := &models.User{UID: uid, Login: "alice2"}
+
+		mockUserRepository.
+			On("UpdateLogin", mock.Anything, uid, "alice2", mock.MatchedBy(func(reservedUntil time.Time) bool {
+				return reservedUntil.After(time.Now().Add(59*time.Minute)) && reservedUntil.Before(time.Now().Add(time.Hour))
+			}), mock.AnythingOfType("models.LoginChangeLimit")).
+			Return(mockUser, nil)
+
+		user, err := userService.ChangeLogin(context.TODO(), uid, "alice2")
+
+		assert.NoError(test, err)
+		assert.Equal(test, mockUser, user)
+		mockUserRepository.AssertExpectations(test)
+	})
+
+	test.Run("Too many changes", func(test *testing.T) {
+		mockUserRepository := new(mocks.MockUserRepository)
+		userService := NewUserService(&UserServiceConfig{
+			UserRepository: mockUserRepository,
+		})
+
+		mockUserRepository.
+			On("UpdateLogin", mock.Anything, uid, "alice2", mock.AnythingOfType("time.Time"), mock.MatchedBy(func(limit models.LoginChangeLimit) bool {
+				since := time.Now().Add(-loginChangeWindow)
+				return limit.Changes == loginChangesPerWindow && limit.Since.After(since.Add(-time.Minute)) && !limit.Since.After(since)
+			})).
+			Return(nil, apperrors.NewTooManyRequests())
+
+		user, err := userService.ChangeLogin(context.TODO(), uid, "alice2")
+
+		assert.Nil(test, user)
+		assert.Equal(test, http.StatusTooManyRequests, apperrors.Status(err))
+		mockUserRepository.AssertExpectations(test)
+	})
+
+	test.Run("Display form is stored", func(test *testing.T) {
+		mockUserRepository := new(mocks.MockUserRepository)
+		userService := NewUserService(&UserServiceConfig{
+			UserRepository: mockUserRepository,
+		})
+
+		mockUserRepository.On("UpdateLogin", mock.Anything, uid, "Alice2", mock.AnythingOfType("time.Time"), mock.AnythingOfType("models.LoginChangeLimit")).Return(&models.User{UID: uid}, nil)
+
+		_, err := userService.ChangeLogin(context.TODO(), uid, "Ａｌｉｃｅ2")
+
+		assert.NoError(test, err)
+		mockUserRepository.AssertExpectations(test)
+	})
+
+	test.Run("Mixed scripts", func(test *testing.T) {
+		mockUserRepository := new(mocks.MockUserRepository)
+		userService := NewUserService(&UserServiceConfig{
+			UserRepository: mockUserRepository,
+		})
+
+		// first letter is cyrillic
+		user, err := userService.ChangeLogin(context.TODO(), uid, "аlice2")
+
+		assert.Nil(test, user)
+		assert.Equal(test, http.StatusBadRequest, apperrors.Status(err))
+		mockUserRepository.AssertNotCalled(test, "UpdateLogin", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestLoginAvailable(test *testing.T) {
+	uid, _ := uuid.NewRandom()
+	key := "login_check." + uid.String()
+
+	mockUserRepository := new(mocks.MockUserRepository)
+	mockTokenRepository := new(mocks.MockTokenRepository)
+	userService := NewUserService(&UserServiceConfig{
+		UserRepository:  mockUserRepository,
+		TokenRepository: mockTokenRepository,
+	})
+
+	mockUserRepository.On("LoginAvailable", mock.Anything, uid, "alice2").Return(true, nil)
+
+	test.Run("Available", func(test *testing.T) {
+		mockTokenRepository.On("IncrementAttempts", mock.Anything, key, loginCheckWindow).Return(int64(1), nil).Once()
+
+		available, err := userService.LoginAvailable(context.TODO(), uid, "alice2")
+
+		assert.NoError(test, err)
+		assert.True(test, available)
+	})
+
+	test.Run("Too many checks", func(test *testing.T) {
+		mockTokenRepository.On("IncrementAttempts", mock.Anything, key, loginCheckWindow).Return(int64(loginChecksPerUser+1), nil).Once()
+
+		available, err := userService.LoginAvailable(context.TODO(), uid, "alice2")
+
+		assert.False(test, available)
+		assert.Equal(test, http.StatusTooManyRequests, apperrors.Status(err))
+	})
+
+	mockUserRepository.AssertNumberOfCalls(test, "LoginAvailable", 1)
+}
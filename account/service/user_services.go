@@ -5,23 +5,49 @@ import (
 	"log"
 	"memorize/models"
 	"memorize/models/apperrors"
+	"memorize/normalize"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// login is changed at most this many times within window, availability is checked
+// at most this many times per user within window, so logins cannot be enumerated
+const (
+	loginChangesPerWindow = 3
+	loginChangeWindow     = 30 * 24 * time.Hour
+	loginChecksPerUser    = 30
+	loginCheckWindow      = 15 * time.Minute
+)
+
+const defaultLoginReservation = 90 * 24 * time.Hour
+
 type userService struct {
-	UserRepository models.UserRepository
+	UserRepository   models.UserRepository
+	TokenRepository  models.TokenRepository
+	LoginReservation time.Duration
 }
 
 // config hold repositories that will eventually be injected into this this service layer
 type UserServiceConfig struct {
 	UserRepository models.UserRepository
+	// counts login availability checks
+	TokenRepository models.TokenRepository
+	// how long previous login stays reserved for its user after change
+	LoginReservation time.Duration
 }
 
 // factory function for initializing a UserService with its repository layer dependencies
 func NewUserService(config *UserServiceConfig) models.UserService {
+	loginReservation := config.LoginReservation
+	if loginReservation <= 0 {
+		loginReservation = defaultLoginReservation
+	}
+
 	return &userService{
-		UserRepository: config.UserRepository,
+		UserRepository:   config.UserRepository,
+		TokenRepository:  config.TokenRepository,
+		LoginReservation: loginReservation,
 	}
 }
 
@@ -30,13 +56,29 @@ func (s *userService) GetUser(ctx context.Context, uid uuid.UUID) (*models.User,
 	return s.UserRepository.FindByID(ctx, uid)
 }
 
-// signup user if login avaliable
+// fetch users by uids, unknown uids are skipped
+func (s *userService) GetUsers(ctx context.Context, uids []uuid.UUID) ([]*models.User, error) {
+	if len(uids) == 0 {
+		return []*models.User{}, nil
+	}
+
+	return s.UserRepository.FindByIDs(ctx, uids)
+}
+
+// signup user if login avaliable, login is stored in its display form
 func (s *userService) Signup(ctx context.Context, user *models.User) error {
+	login, err := displayLogin(user.Login)
+	if err != nil {
+		return err
+	}
+
+	user.Login = login
+
 	password, err := HashPassword(user.Password)
 
 	if err != nil {
 		log.Printf("Unable to signup user for login: %v\n", user.Login)
-		return apperrors.NewInternal()
+		return apperrors.NewInternal().Wrap(err)
 	}
 
 	user.Password = password
@@ -53,18 +95,28 @@ func (s *userService) Signin(ctx context.Context, user *models.User) (*models.Us
 
 	// Will return NotAuthorized to client to omit details of why
 	if err != nil {
-		return nil, apperrors.NewAuthorization("User with this login dont exist")
+		return nil, apperrors.NewAuthorization("User with this login dont exist").WithCode(apperrors.InvalidCredentials)
+	}
+
+	// users created with external provider have no password to sign in with
+	if !fetchedUser.HasPassword() {
+		return nil, apperrors.NewAuthorization("Invalid login and password combination").WithCode(apperrors.InvalidCredentials)
 	}
 
 	// verify password
 	match, err := comparePasswords(fetchedUser.Password, user.Password)
 
 	if err != nil {
-		return nil, apperrors.NewInternal()
+		return nil, apperrors.NewInternal().Wrap(err)
 	}
 
 	if !match {
-		return nil, apperrors.NewAuthorization("Invalid login and password combination")
+		return nil, apperrors.NewAuthorization("Invalid login and password combination").WithCode(apperrors.InvalidCredentials)
+	}
+
+	// checked after password, so disabled accounts are not told apart without it
+	if fetchedUser.Disabled {
+		return nil, apperrors.NewAuthorization("Account is disabled").WithCode(apperrors.AccountDisabled)
 	}
 
 	return fetchedUser, nil
@@ -74,3 +126,76 @@ func (s *userService) Signin(ctx context.Context, user *models.User) (*models.Us
 func (s *userService) UpdateDetails(ctx context.Context, user *models.User) error {
 	return s.UserRepository.Update(ctx, user)
 }
+
+// change details in mask of patch
+func (s *userService) PatchDetails(ctx context.Context, patch *models.UserPatch) (*models.User, error) {
+	return s.UserRepository.UpdateFields(ctx, patch)
+}
+
+// replace user password
+func (s *userService) UpdatePassword(ctx context.Context, uid uuid.UUID, password string) error {
+	hashedPassword, err := HashPassword(password)
+
+	if err != nil {
+		log.Printf("Unable to hash password for user: %v\n", uid)
+		return apperrors.NewInternal().Wrap(err)
+	}
+
+	return s.UserRepository.UpdatePassword(ctx, uid, hashedPassword)
+}
+
+// disable or enable user account
+func (s *userService) SetDisabled(ctx context.Context, uid uuid.UUID, disabled bool) error {
+	return s.UserRepository.UpdateDisabled(ctx, uid, disabled)
+}
+
+// fetch user by login, previous logins are resolved while they are reserved
+func (s *userService) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	user, err := s.UserRepository.FindByLogin(ctx, login)
+	if err == nil {
+		return user, nil
+	}
+
+	return s.UserRepository.FindByPreviousLogin(ctx, login)
+}
+
+// tell if user can change login to login
+func (s *userService) LoginAvailable(ctx context.Context, uid uuid.UUID, login string) (bool, error) {
+	attempts, err := s.TokenRepository.IncrementAttempts(ctx, "login_check."+uid.String(), loginCheckWindow)
+	if err != nil {
+		return false, err
+	}
+
+	if attempts > loginChecksPerUser {
+		log.Printf("Too many login availability checks of user: %v\n", uid)
+		return false, apperrors.NewTooManyRequests()
+	}
+
+	return s.UserRepository.LoginAvailable(ctx, uid, login)
+}
+
+// change login of user, previous login is reserved so nobody else can take it over
+func (s *userService) ChangeLogin(ctx context.Context, uid uuid.UUID, login string) (*models.User, error) {
+	login, err := displayLogin(login)
+	if err != nil {
+		return nil, err
+	}
+
+	// changes are counted by repository in transaction of the change, so concurrent ones cannot both pass
+	limit := models.LoginChangeLimit{
+		Changes: loginChangesPerWindow,
+		Since:   time.Now().Add(-loginChangeWindow),
+	}
+
+	return s.UserRepository.UpdateLogin(ctx, uid, login, time.Now().Add(s.LoginReservation), limit)
+}
+
+// displayLogin rejects logins that cannot be normalized, like ones mixing scripts
+func displayLogin(login string) (string, error) {
+	display, err := normalize.Login(login)
+	if err != nil {
+		return "", apperrors.NewBadRequest(err.Error()).WithCode(apperrors.InvalidLogin)
+	}
+
+	return display, nil
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"log"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/webauthn"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type webAuthnService struct {
+	RelyingParty                 *webauthn.Config
+	WebAuthnCredentialRepository models.WebAuthnCredentialRepository
+	WebAuthnSessionRepository    models.WebAuthnSessionRepository
+	UserRepository               models.UserRepository
+}
+
+// config hold relying party and repositories injected into webauthn service
+type WebAuthnServiceConfig struct {
+	RelyingParty                 *webauthn.Config
+	WebAuthnCredentialRepository models.WebAuthnCredentialRepository
+	WebAuthnSessionRepository    models.WebAuthnSessionRepository
+	UserRepository               models.UserRepository
+}
+
+// factory function for initializing WebAuthnService with its dependencies
+func NewWebAuthnService(config *WebAuthnServiceConfig) models.WebAuthnService {
+	return &webAuthnService{
+		RelyingParty:                 config.RelyingParty,
+		WebAuthnCredentialRepository: config.WebAuthnCredentialRepository,
+		WebAuthnSessionRepository:    config.WebAuthnSessionRepository,
+		UserRepository:               config.UserRepository,
+	}
+}
+
+// start passkey registration, user handle is the uid
+func (s *webAuthnService) BeginRegistration(ctx context.Context, uid uuid.UUID) (*webauthn.CreationOptions, error) {
+	user, err := s.UserRepository.FindByID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := s.WebAuthnCredentialRepository.FindByUserID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.newSession(ctx, uid, models.CeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := user.Name
+	if displayName == "" {
+		displayName = user.Login
+	}
+
+	return s.RelyingParty.NewCreationOptions(challenge, webauthn.UserEntity{
+		ID:          uid[:],
+		Name:        user.Login,
+		DisplayName: displayName,
+	}, credentialIDs(credentials)), nil
+}
+
+// verify authenticator response and store credential
+func (s *webAuthnService) FinishRegistration(
+	ctx context.Context,
+	uid uuid.UUID,
+	name string,
+	response *webauthn.AttestationResponse,
+) (*models.WebAuthnCredential, error) {
+
+	session, err := s.consumeSession(ctx, response.Response.ClientDataJSON, models.CeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID != uid {
+		return nil, apperrors.NewAuthorization("Challenge is invalid or expired")
+	}
+
+	verified, err := s.RelyingParty.VerifyRegistration(response, session.Challenge, false)
+	if err != nil {
+		log.Printf("Unable to verify passkey registration of user: %v. Reason: %v\n", uid, err)
+		return nil, apperrors.NewBadRequest("passkey registration could not be verified")
+	}
+
+	credential := &models.WebAuthnCredential{
+		ID:        base64.RawURLEncoding.EncodeToString(verified.ID),
+		UserID:    uid,
+		Name:      name,
+		PublicKey: verified.PublicKey,
+		SignCount: verified.SignCount,
+	}
+
+	if err := s.WebAuthnCredentialRepository.Create(ctx, credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// start passwordless signin, unknown login gets options for discoverable credentials
+// so response does not tell whether login exists
+func (s *webAuthnService) BeginSignin(ctx context.Context, login string) (*webauthn.RequestOptions, error) {
+	uid := uuid.Nil
+	var allow [][]byte
+
+	if login != "" {
+		if user, err := s.UserRepository.FindByLogin(ctx, login); err == nil {
+			credentials, err := s.WebAuthnCredentialRepository.FindByUserID(ctx, user.UID)
+			if err != nil {
+				return nil, err
+			}
+
+			uid = user.UID
+			allow = credentialIDs(credentials)
+		}
+	}
+
+	if uid != uuid.Nil && len(allow) == 0 {
+		uid = uuid.Nil
+	}
+
+	challenge, err := s.newSession(ctx, uid, models.CeremonySignin)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.RelyingParty.NewRequestOptions(challenge, allow, webauthn.UserVerificationRequired), nil
+}
+
+// start passkey confirmation of password signin
+func (s *webAuthnService) BeginSecondFactor(ctx context.Context, uid uuid.UUID) (*webauthn.RequestOptions, error) {
+	credentials, err := s.WebAuthnCredentialRepository.FindByUserID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(credentials) == 0 {
+		log.Printf("User: %v requires second factor but has no passkeys\n", uid)
+		return nil, apperrors.NewAuthorization("No passkey is registered for second factor")
+	}
+
+	challenge, err := s.newSession(ctx, uid, models.CeremonySecondFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.RelyingParty.NewRequestOptions(challenge, credentialIDs(credentials), webauthn.UserVerificationPreferred), nil
+}
+
+// verify signin or second factor response
+// passwordless signin requires user verification, as passkey replaces both factors
+func (s *webAuthnService) FinishSignin(ctx context.Context, response *webauthn.AssertionResponse) (*models.User, error) {
+	session, err := s.consumeSession(ctx, response.Response.ClientDataJSON, models.CeremonySignin, models.CeremonySecondFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	invalid := apperrors.NewAuthorization("Passkey could not be verified")
+
+	credential, err := s.WebAuthnCredentialRepository.FindByID(ctx, base64.RawURLEncoding.EncodeToString(response.RawID))
+	if err != nil {
+		if isType(err, apperrors.NotFound) {
+			return nil, invalid
+		}
+
+		return nil, err
+	}
+
+	if session.UserID != uuid.Nil && session.UserID != credential.UserID {
+		return nil, invalid
+	}
+
+	if session.Ceremony == models.CeremonySecondFactor && session.UserID == uuid.Nil {
+		return nil, invalid
+	}
+
+	if len(response.Response.UserHandle) != 0 && !bytes.Equal(response.Response.UserHandle, credential.UserID[:]) {
+		return nil, invalid
+	}
+
+	signCount, err := s.RelyingParty.VerifySignin(
+		response,
+		session.Challenge,
+		credential.PublicKey,
+		credential.SignCount,
+		session.Ceremony == models.CeremonySignin,
+	)
+
+	if err != nil {
+		log.Printf("Unable to verify passkey: %v. Reason: %v\n", credential.ID, err)
+		return nil, invalid
+	}
+
+	if err := s.WebAuthnCredentialRepository.UpdateSignCount(ctx, credential.ID, signCount, time.Now()); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepository.FindByID(ctx, credential.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Disabled {
+		return nil, apperrors.NewAuthorization("Account is disabled").WithCode(apperrors.AccountDisabled)
+	}
+
+	return user, nil
+}
+
+// list passkeys of user
+func (s *webAuthnService) ListCredentials(ctx context.Context, uid uuid.UUID) ([]*models.WebAuthnCredential, error) {
+	return s.WebAuthnCredentialRepository.FindByUserID(ctx, uid)
+}
+
+// delete passkey, last one cannot be deleted while second factor is required
+func (s *webAuthnService) DeleteCredential(ctx context.Context, uid uuid.UUID, credentialID string) error {
+	user, err := s.UserRepository.FindByID(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	if user.SecondFactor {
+		credentials, err := s.WebAuthnCredentialRepository.FindByUserID(ctx, uid)
+		if err != nil {
+			return err
+		}
+
+		if len(credentials) == 1 && credentials[0].ID == credentialID {
+			return apperrors.NewBadRequest("disable second factor before deleting the last passkey")
+		}
+	}
+
+	return s.WebAuthnCredentialRepository.Delete(ctx, uid, credentialID)
+}
+
+// require passkey after password signin, needs a registered passkey
+func (s *webAuthnService) SetSecondFactor(ctx context.Context, uid uuid.UUID, enabled bool) error {
+	if enabled {
+		credentials, err := s.WebAuthnCredentialRepository.FindByUserID(ctx, uid)
+		if err != nil {
+			return err
+		}
+
+		if len(credentials) == 0 {
+			return apperrors.NewBadRequest("register a passkey before enabling second factor")
+		}
+	}
+
+	return s.UserRepository.UpdateSecondFactor(ctx, uid, enabled)
+}
+
+func (s *webAuthnService) newSession(ctx context.Context, uid uuid.UUID, ceremony string) ([]byte, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		log.Printf("Unable to generate webauthn challenge for user: %v\n", uid)
+		return nil, apperrors.NewInternal().Wrap(err)
+	}
+
+	if err := s.WebAuthnSessionRepository.Save(ctx, &models.WebAuthnSession{
+		Challenge: challenge,
+		UserID:    uid,
+		Ceremony:  ceremony,
+	}, s.RelyingParty.Timeout); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// find ceremony by challenge the authenticator signed
+func (s *webAuthnService) consumeSession(
+	ctx context.Context,
+	clientDataJSON []byte,
+	ceremonies ...string,
+) (*models.WebAuthnSession, error) {
+
+	challenge, err := webauthn.ChallengeOf(clientDataJSON)
+	if err != nil || len(challenge) == 0 {
+		return nil, apperrors.NewBadRequest("invalid client data")
+	}
+
+	session, err := s.WebAuthnSessionRepository.Consume(ctx, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ceremony := range ceremonies {
+		if session.Ceremony == ceremony {
+			return session, nil
+		}
+	}
+
+	return nil, apperrors.NewAuthorization("Challenge is invalid or expired")
+}
+
+func credentialIDs(credentials []*models.WebAuthnCredential) [][]byte {
+	ids := make([][]byte, 0, len(credentials))
+
+	for _, credential := range credentials {
+		id, err := base64.RawURLEncoding.DecodeString(credential.ID)
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
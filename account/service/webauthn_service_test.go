@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"memorize/mocks"
+	"memorize/models"
+	"memorize/models/apperrors"
+	"memorize/webauthn"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWebAuthnService(test *testing.T) {
+	relyingParty := &webauthn.Config{
+		RPID:    "localhost",
+		RPName:  "Memorize",
+		Origins: []string{"http://localhost"},
+		Timeout: 5 * time.Minute,
+	}
+
+	uid, _ := uuid.NewRandom()
+	user := &models.User{
+		UID:   uid,
+		Login: "alice",
+		Name:  "Alice",
+	}
+
+	newService := func() (models.WebAuthnService, *mocks.MockUserRepository, *mocks.MockWebAuthnCredentialRepository, *mocks.MockWebAuthnSessionRepository) {
+		userRepository := new(mocks.MockUserRepository)
+		credentialRepository := new(mocks.MockWebAuthnCredentialRepository)
+		sessionRepository := new(mocks.MockWebAuthnSessionRepository)
+
+		webAuthnService := NewWebAuthnService(&WebAuthnServiceConfig{
+			RelyingParty:                 relyingParty,
+			WebAuthnCredentialRepository: credentialRepository,
+			WebAuthnSessionRepository:    sessionRepository,
+			UserRepository:               userRepository,
+		})
+
+		return webAuthnService, userRepository, credentialRepository, sessionRepository
+	}
+
+	// saved session is handed back when its challenge is consumed
+	expectSession := func(sessionRepository *mocks.MockWebAuthnSessionRepository) {
+		saved := &models.WebAuthnSession{}
+
+		sessionRepository.
+			On("Save", mock.Anything, mock.AnythingOfType("*models.WebAuthnSession"), relyingParty.Timeout).
+			Run(func(args mock.Arguments) {
+				*saved = *args.Get(1).(*models.WebAuthnSession)
+			}).
+			Return(nil).Once()
+
+		sessionRepository.
+			On("Consume", mock.Anything, mock.AnythingOfType("[]uint8")).
+			Return(saved, nil).Once()
+	}
+
+	// registers passkey of user at authenticator, returns stored credential
+	register := func(test *testing.T, authenticator *mocks.SoftwareAuthenticator) *models.WebAuthnCredential {
+		webAuthnService, userRepository, credentialRepository, sessionRepository := newService()
+
+		userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+		credentialRepository.On("FindByUserID", mock.Anything, uid).Return([]*models.WebAuthnCredential{}, nil)
+		credentialRepository.On("Create", mock.Anything, mock.AnythingOfType("*models.WebAuthnCredential")).Return(nil)
+		expectSession(sessionRepository)
+
+		options, err := webAuthnService.BeginRegistration(context.Background(), uid)
+		assert.NoError(test, err)
+		assert.Equal(test, "localhost", options.PublicKey.RelyingParty.ID)
+		assert.Equal(test, webauthn.URLEncodedBytes(uid[:]), options.PublicKey.User.ID)
+
+		response, err := authenticator.Create(options)
+		assert.NoError(test, err)
+
+		credential, err := webAuthnService.FinishRegistration(context.Background(), uid, "laptop", response)
+		assert.NoError(test, err)
+
+		return credential
+	}
+
+	test.Run("Registration", func(test *testing.T) {
+		authenticator := mocks.NewSoftwareAuthenticator("http://localhost")
+
+		credential := register(test, authenticator)
+
+		assert.Equal(test, uid, credential.UserID)
+		assert.Equal(test, "laptop", credential.Name)
+		assert.NotEmpty(test, credential.ID)
+		assert.NotEmpty(test, credential.PublicKey)
+	})
+
+	test.Run("Registration from other origin", func(test *testing.T) {
+		webAuthnService, userRepository, credentialRepository, sessionRepository := newService()
+
+		userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+		credentialRepository.On("FindByUserID", mock.Anything, uid).Return([]*models.WebAuthnCredential{}, nil)
+		expectSession(sessionRepository)
+
+		options, err := webAuthnService.BeginRegistration(context.Background(), uid)
+		assert.NoError(test, err)
+
+		response, err := mocks.NewSoftwareAuthenticator("http://evil.localhost").Create(options)
+		assert.NoError(test, err)
+
+		credential, err := webAuthnService.FinishRegistration(context.Background(), uid, "laptop", response)
+		assert.Nil(test, credential)
+		assert.Equal(test, apperrors.BadRequest, err.(*apperrors.Error).Type)
+		credentialRepository.AssertNotCalled(test, "Create", mock.Anything, mock.Anything)
+	})
+
+	test.Run("Registration of other user", func(test *testing.T) {
+		webAuthnService, userRepository, credentialRepository, sessionRepository := newService()
+
+		userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+		credentialRepository.On("FindByUserID", mock.Anything, uid).Return([]*models.WebAuthnCredential{}, nil)
+		expectSession(sessionRepository)
+
+		options, err := webAuthnService.BeginRegistration(context.Background(), uid)
+		assert.NoError(test, err)
+
+		response, err := mocks.NewSoftwareAuthenticator("http://localhost").Create(options)
+		assert.NoError(test, err)
+
+		otherUID, _ := uuid.NewRandom()
+		credential, err := webAuthnService.FinishRegistration(context.Background(), otherUID, "laptop", response)
+		assert.Nil(test, credential)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("Passwordless signin", func(test *testing.T) {
+		authenticator := mocks.NewSoftwareAuthenticator("http://localhost")
+		credential := register(test, authenticator)
+
+		webAuthnService, userRepository, credentialRepository, sessionRepository := newService()
+
+		userRepository.On("FindByLogin", mock.Anything, "alice").Return(user, nil)
+		userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+		credentialRepository.On("FindByUserID", mock.Anything, uid).Return([]*models.WebAuthnCredential{credential}, nil)
+		credentialRepository.On("FindByID", mock.Anything, credential.ID).Return(credential, nil)
+		credentialRepository.On("UpdateSignCount", mock.Anything, credential.ID, uint32(1), mock.AnythingOfType("time.Time")).Return(nil)
+		expectSession(sessionRepository)
+
+		options, err := webAuthnService.BeginSignin(context.Background(), "alice")
+		assert.NoError(test, err)
+		assert.Len(test, options.PublicKey.AllowCredentials, 1)
+		assert.Equal(test, webauthn.UserVerificationRequired, options.PublicKey.UserVerification)
+
+		response, err := authenticator.Get(options)
+		assert.NoError(test, err)
+
+		signedIn, err := webAuthnService.FinishSignin(context.Background(), response)
+		assert.NoError(test, err)
+		assert.Equal(test, user, signedIn)
+		credentialRepository.AssertExpectations(test)
+	})
+
+	test.Run("Discoverable signin of unknown login", func(test *testing.T) {
+		authenticator := mocks.NewSoftwareAuthenticator("http://localhost")
+		credential := register(test, authenticator)
+
+		webAuthnService, userRepository, credentialRepository, sessionRepository := newService()
+
+		userRepository.On("FindByLogin", mock.Anything, "nobody").Return(nil, apperrors.NewNotFound("login", "nobody"))
+		userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+		credentialRepository.On("FindByID", mock.Anything, credential.ID).Return(credential, nil)
+		credentialRepository.On("UpdateSignCount", mock.Anything, credential.ID, uint32(1), mock.AnythingOfType("time.Time")).Return(nil)
+		expectSession(sessionRepository)
+
+		options, err := webAuthnService.BeginSignin(context.Background(), "nobody")
+		assert.NoError(test, err)
+		assert.Empty(test, options.PublicKey.AllowCredentials)
+
+		response, err := authenticator.Get(options)
+		assert.NoError(test, err)
+
+		signedIn, err := webAuthnService.FinishSignin(context.Background(), response)
+		assert.NoError(test, err)
+		assert.Equal(test, user, signedIn)
+	})
+
+	test.Run("Passwordless signin without user verification", func(test *testing.T) {
+		authenticator := mocks.NewSoftwareAuthenticator("http://localhost")
+		credential := register(test, authenticator)
+		authenticator.UserVerified = false
+
+		webAuthnService, userRepository, credentialRepository, sessionRepository := newService()
+
+		userRepository.On("FindByLogin", mock.Anything, "alice").Return(user, nil)
+		credentialRepository.On("FindByUserID", mock.Anything, uid).Return([]*models.WebAuthnCredential{credential}, nil)
+		credentialRepository.On("FindByID", mock.Anything, credential.ID).Return(credential, nil)
+		expectSession(sessionRepository)
+
+		options, err := webAuthnService.BeginSignin(context.Background(), "alice")
+		assert.NoError(test, err)
+
+		response, err := authenticator.Get(options)
+		assert.NoError(test, err)
+
+		signedIn, err := webAuthnService.FinishSignin(context.Background(), response)
+		assert.Nil(test, signedIn)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+		credentialRepository.AssertNotCalled(test, "UpdateSignCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Second factor without user verification", func(test *testing.T) {
+		authenticator := mocks.NewSoftwareAuthenticator("http://localhost")
+		credential := register(test, authenticator)
+		authenticator.UserVerified = false
+
+		webAuthnService, userRepository, credentialRepository, sessionRepository := newService()
+
+		userRepository.On("FindByID", mock.Anything, uid).Return(user, nil)
+		credentialRepository.On("FindByUserID", mock.Anything, uid).Return([]*models.WebAuthnCredential{credential}, nil)
+		credentialRepository.On("FindByID", mock.Anything, credential.ID).Return(credential, nil)
+		credentialRepository.On("UpdateSignCount", mock.Anything, credential.ID, uint32(1), mock.AnythingOfType("time.Time")).Return(nil)
+		expectSession(sessionRepository)
+
+		options, err := webAuthnService.BeginSecondFactor(context.Background(), uid)
+		assert.NoError(test, err)
+
+		response, err := authenticator.Get(options)
+		assert.NoError(test, err)
+
+		signedIn, err := webAuthnService.FinishSignin(context.Background(), response)
+		assert.NoError(test, err)
+		assert.Equal(test, user, signedIn)
+	})
+
+	test.Run("Cloned authenticator", func(test *testing.T) {
+		authenticator := mocks.NewSoftwareAuthenticator("http://localhost")
+		credential := register(test, authenticator)
+		// original authenticator was already used more times than this copy
+		credential.SignCount = 5
+
+		webAuthnService, _, credentialRepository, sessionRepository := newService()
+
+		credentialRepository.On("FindByUserID", mock.Anything, uid).Return([]*models.WebAuthnCredential{credential}, nil)
+		credentialRepository.On("FindByID", mock.Anything, credential.ID).Return(credential, nil)
+		expectSession(sessionRepository)
+
+		options, err := webAuthnService.BeginSecondFactor(context.Background(), uid)
+		assert.NoError(test, err)
+
+		response, err := authenticator.Get(options)
+		assert.NoError(test, err)
+
+		signedIn, err := webAuthnService.FinishSignin(context.Background(), response)
+		assert.Nil(test, signedIn)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("Second factor of other user", func(test *testing.T) {
+		authenticator := mocks.NewSoftwareAuthenticator("http://localhost")
+		credential := register(test, authenticator)
+
+		otherUID, _ := uuid.NewRandom()
+		other := &models.WebAuthnCredential{ID: "b3RoZXI", UserID: otherUID}
+
+		webAuthnService, _, credentialRepository, sessionRepository := newService()
+
+		credentialRepository.On("FindByUserID", mock.Anything, otherUID).Return([]*models.WebAuthnCredential{other}, nil)
+		credentialRepository.On("FindByID", mock.Anything, credential.ID).Return(credential, nil)
+		expectSession(sessionRepository)
+
+		options, err := webAuthnService.BeginSecondFactor(context.Background(), otherUID)
+		assert.NoError(test, err)
+
+		// authenticator answers with passkey of alice anyway
+		options.PublicKey.AllowCredentials = nil
+		response, err := authenticator.Get(options)
+		assert.NoError(test, err)
+
+		signedIn, err := webAuthnService.FinishSignin(context.Background(), response)
+		assert.Nil(test, signedIn)
+		assert.Equal(test, apperrors.Authorization, err.(*apperrors.Error).Type)
+	})
+
+	test.Run("Delete last passkey with second factor", func(test *testing.T) {
+		webAuthnService, userRepository, credentialRepository, _ := newService()
+
+		secondFactorUser := &models.User{UID: uid, SecondFactor: true}
+		credential := &models.WebAuthnCredential{ID: "cGFzc2tleQ", UserID: uid}
+
+		userRepository.On("FindByID", mock.Anything, uid).Return(secondFactorUser, nil)
+		credentialRepository.On("FindByUserID", mock.Anything, uid).Return([]*models.WebAuthnCredential{credential}, nil)
+
+		err := webAuthnService.DeleteCredential(context.Background(), uid, credential.ID)
+		assert.Equal(test, apperrors.BadRequest, err.(*apperrors.Error).Type)
+		credentialRepository.AssertNotCalled(test, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	test.Run("Enable second factor without passkeys", func(test *testing.T) {
+		webAuthnService, userRepository, credentialRepository, _ := newService()
+
+		credentialRepository.On("FindByUserID", mock.Anything, uid).Return([]*models.WebAuthnCredential{}, nil)
+
+		err := webAuthnService.SetSecondFactor(context.Background(), uid, true)
+		assert.Equal(test, apperrors.BadRequest, err.(*apperrors.Error).Type)
+		userRepository.AssertNotCalled(test, "UpdateSecondFactor", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
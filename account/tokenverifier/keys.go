@@ -0,0 +1,283 @@
+package tokenverifier
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	defaultRefreshInterval    = time.Hour
+	defaultMinRefreshInterval = time.Minute
+	fetchTimeout              = 10 * time.Second
+)
+
+type staticKeySource struct {
+	key *rsa.PublicKey
+}
+
+// NewStaticKeySource uses PEM encoded public key of the account service for every token
+func NewStaticKeySource(publicKeyPEM []byte) (KeySource, error) {
+	key, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("tokenverifier: could not parse public key: %w", err)
+	}
+
+	return &staticKeySource{
+		key: key,
+	}, nil
+}
+
+// NewStaticKeySourceFromFile reads PEM encoded public key from file
+func NewStaticKeySourceFromFile(path string) (KeySource, error) {
+	publicKeyPEM, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("tokenverifier: could not read public key file: %w", err)
+	}
+
+	return NewStaticKeySource(publicKeyPEM)
+}
+
+func (s *staticKeySource) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	return s.key, nil
+}
+
+// public RSA key as defined by RFC 7517
+type jsonWebKey struct {
+	KeyType  string `json:"kty"`
+	Use      string `json:"use"`
+	KeyID    string `json:"kid"`
+	Modulus  string `json:"n"`
+	Exponent string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// JWKSOptions tune fetching of JWKS, zero values use defaults
+type JWKSOptions struct {
+	HTTPClient *http.Client
+	// how often keys are fetched in background, 1 hour by default
+	RefreshInterval time.Duration
+	// unknown key id fetches keys at most this often, 1 minute by default
+	MinRefreshInterval time.Duration
+}
+
+// JWKSKeySource caches keys published at JWKS endpoint of the account service
+// keys are refreshed in background and when a token has an unknown key id, so rotation needs no restart
+type JWKSKeySource struct {
+	url     string
+	options JWKSOptions
+
+	mutex     sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+
+	// one fetch at a time, tokens with the same unknown kid wait for it
+	fetchMutex sync.Mutex
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewJWKSKeySource fetches keys once and starts background refresh, stop it with Close
+func NewJWKSKeySource(url string, options *JWKSOptions) (*JWKSKeySource, error) {
+	source := &JWKSKeySource{
+		url:  url,
+		keys: map[string]*rsa.PublicKey{},
+		stop: make(chan struct{}),
+	}
+
+	if options != nil {
+		source.options = *options
+	}
+
+	if source.options.HTTPClient == nil {
+		source.options.HTTPClient = &http.Client{Timeout: fetchTimeout}
+	}
+
+	if source.options.RefreshInterval <= 0 {
+		source.options.RefreshInterval = defaultRefreshInterval
+	}
+
+	if source.options.MinRefreshInterval <= 0 {
+		source.options.MinRefreshInterval = defaultMinRefreshInterval
+	}
+
+	if err := source.fetch(context.Background()); err != nil {
+		return nil, err
+	}
+
+	go source.refreshLoop()
+
+	return source, nil
+}
+
+// Key returns cached key, keys are fetched again if kid is unknown
+func (s *JWKSKeySource) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if key, ok := s.cached(kid); ok {
+		return key, nil
+	}
+
+	s.fetchMutex.Lock()
+	defer s.fetchMutex.Unlock()
+
+	// fetched while this call waited
+	if key, ok := s.cached(kid); ok {
+		return key, nil
+	}
+
+	s.mutex.RLock()
+	fetchedAt := s.fetchedAt
+	s.mutex.RUnlock()
+
+	if time.Since(fetchedAt) < s.options.MinRefreshInterval {
+		return nil, ErrUnknownKey
+	}
+
+	if err := s.fetchLocked(ctx); err != nil {
+		log.Printf("tokenverifier: could not refresh keys from %v: %v\n", s.url, err)
+		return nil, ErrUnknownKey
+	}
+
+	if key, ok := s.cached(kid); ok {
+		return key, nil
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// Close stops background refresh
+func (s *JWKSKeySource) Close() {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// token without kid can only be matched when there is a single key
+func (s *JWKSKeySource) cached(kid string) (*rsa.PublicKey, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if kid == "" {
+		if len(s.keys) == 1 {
+			for _, key := range s.keys {
+				return key, true
+			}
+		}
+
+		return nil, false
+	}
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *JWKSKeySource) refreshLoop() {
+	ticker := time.NewTicker(s.options.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.fetch(context.Background()); err != nil {
+				// cached keys stay in use until endpoint is back
+				log.Printf("tokenverifier: could not refresh keys from %v: %v\n", s.url, err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *JWKSKeySource) fetch(ctx context.Context) error {
+	s.fetchMutex.Lock()
+	defer s.fetchMutex.Unlock()
+
+	return s.fetchLocked(ctx)
+}
+
+func (s *JWKSKeySource) fetchLocked(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("tokenverifier: invalid JWKS url: %w", err)
+	}
+
+	response, err := s.options.HTTPClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("tokenverifier: could not fetch JWKS: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("tokenverifier: JWKS endpoint responded with status %v", response.StatusCode)
+	}
+
+	keySet := &jsonWebKeySet{}
+	if err := json.NewDecoder(response.Body).Decode(keySet); err != nil {
+		return fmt.Errorf("tokenverifier: could not decode JWKS: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+
+	for _, jwk := range keySet.Keys {
+		if jwk.KeyType != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		key, err := publicKey(&jwk)
+		if err != nil {
+			log.Printf("tokenverifier: skipping key %v: %v\n", jwk.KeyID, err)
+			continue
+		}
+
+		keys[jwk.KeyID] = key
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("tokenverifier: JWKS has no RSA signing keys")
+	}
+
+	s.mutex.Lock()
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	s.mutex.Unlock()
+
+	return nil
+}
+
+func publicKey(jwk *jsonWebKey) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+
+	exponent, err := base64.RawURLEncoding.DecodeString(jwk.Exponent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	e := new(big.Int).SetBytes(exponent)
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(e.Int64()),
+	}, nil
+}
@@ -0,0 +1,98 @@
+package tokenverifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type contextKey struct{}
+
+// errorResponse has the shape of account service errors, so clients handle both alike
+type errorResponse struct {
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func unauthorized(code string, message string) *errorResponse {
+	return &errorResponse{
+		Type:    "AUTHORIZATION",
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Gin verifies bearer token and sets "user" and "claims" to the context, like the account service does
+// requests without valid token are aborted with 401
+func (v *Verifier) Gin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, err := v.verifyRequest(ctx.Request)
+
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"error": err,
+			})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set("user", claims.User)
+		ctx.Set("claims", claims)
+
+		ctx.Next()
+	}
+}
+
+// HTTP verifies bearer token and passes claims to next handler in request context
+// read them with UserFromContext or ClaimsFromContext
+func (v *Verifier) HTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := v.verifyRequest(r)
+
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": err,
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, claims)))
+	})
+}
+
+// ClaimsFromContext returns claims set by HTTP middleware
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok
+}
+
+// UserFromContext returns user set by HTTP middleware
+func UserFromContext(ctx context.Context) (*User, bool) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	return claims.User, true
+}
+
+func (v *Verifier) verifyRequest(r *http.Request) (*Claims, *errorResponse) {
+	header := r.Header.Get("Authorization")
+
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, unauthorized("MISSING_TOKEN", "Must provide Authorization header with format `Bearer {token}`")
+	}
+
+	claims, err := v.Verify(r.Context(), strings.TrimPrefix(header, "Bearer "))
+	if err != nil {
+		return nil, unauthorized("INVALID_TOKEN", "Provided token is invalid")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,126 @@
+// Package tokenverifier verifies access tokens issued by the account service.
+//
+// It is a module of its own, so services outside this repository can depend on it:
+//
+//	go get github.com/TheTenzou/memorize/account/tokenverifier
+//
+// Releases are tagged account/tokenverifier/vX.Y.Z in the repository, as Go expects for nested modules.
+//
+// Services that accept account users create one Verifier with a KeySource,
+// either the PEM public key of the account service or its JWKS endpoint,
+// and protect their routes with the gin or net/http middleware:
+//
+//	keys, err := tokenverifier.NewJWKSKeySource("http://account/api/account/jwks", nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer keys.Close()
+//
+//	verifier := tokenverifier.New(keys)
+//	router.GET("/notes", verifier.Gin(), listNotes)
+package tokenverifier
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed, expired or badly signed tokens
+	ErrInvalidToken = errors.New("tokenverifier: token is invalid")
+	// ErrNoUser is returned for service and id tokens, they are signed with the same key but carry no user
+	ErrNoUser = errors.New("tokenverifier: token carries no user")
+	// ErrUnknownKey is returned when no key matches key id of the token
+	ErrUnknownKey = errors.New("tokenverifier: no key for token")
+)
+
+// User is the account user an access token is issued for, claims the token was not granted are empty
+type User struct {
+	UID          uuid.UUID `json:"uid"`
+	Login        string    `json:"login"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	ImageURL     string    `json:"imageUrl"`
+	Website      string    `json:"website"`
+	SecondFactor bool      `json:"secondFactor"`
+	Locale       string    `json:"locale"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Claims of account service access token
+type Claims struct {
+	User *User `json:"user"`
+	// OAuth client token was issued to and scope it was granted,
+	// empty for tokens the account service issues to its own clients
+	AuthorizedParty string `json:"azp,omitempty"`
+	Scope           string `json:"scope,omitempty"`
+	jwt.StandardClaims
+}
+
+// KeySource provides public keys of the account service
+type KeySource interface {
+	// key that verifies tokens with key id, kid is empty for tokens without kid header
+	Key(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// Verifier checks access tokens against keys of its source, safe for concurrent use
+type Verifier struct {
+	keys KeySource
+}
+
+// New creates verifier using keys from source
+func New(keys KeySource) *Verifier {
+	return &Verifier{
+		keys: keys,
+	}
+}
+
+// Verify checks signature and expiry of access token and returns its claims
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	unverified, _, err := new(jwt.Parser).ParseUnverified(token, &Claims{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	kid, _ := unverified.Header["kid"].(string)
+
+	key, err := v.keys.Key(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseAccessToken(token, key)
+}
+
+// ParseAccessToken checks access token with key, used when key is already known
+func ParseAccessToken(token string, key *rsa.PublicKey) (*Claims, error) {
+	claims := &Claims{}
+
+	parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+
+		return key, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !parsed.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.User == nil {
+		return nil, ErrNoUser
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,270 @@
+package tokenverifier
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// serves JWKS of keys, counts requests
+type jwksServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	keys     map[string]*rsa.PublicKey
+	requests int
+}
+
+func newJWKSServer(keys map[string]*rsa.PublicKey) *jwksServer {
+	server := &jwksServer{keys: keys}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+
+		server.requests++
+		keySet := jsonWebKeySet{}
+
+		for kid, key := range server.keys {
+			keySet.Keys = append(keySet.Keys, jsonWebKey{
+				KeyType:  "RSA",
+				Use:      "sig",
+				KeyID:    kid,
+				Modulus:  base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				Exponent: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+
+		json.NewEncoder(w).Encode(keySet)
+	}))
+
+	return server
+}
+
+func (s *jwksServer) setKeys(keys map[string]*rsa.PublicKey) {
+	s.mutex.Lock()
+	s.keys = keys
+	s.mutex.Unlock()
+}
+
+func (s *jwksServer) requestCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests
+}
+
+func signToken(test *testing.T, key *rsa.PrivateKey, kid string, claims jwt.Claims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	assert.NoError(test, err)
+
+	return signed
+}
+
+func TestVerifier(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	uid, _ := uuid.NewRandom()
+	user := &User{UID: uid, Login: "alice"}
+
+	userClaims := func(expiresIn time.Duration) *Claims {
+		return &Claims{
+			User: user,
+			StandardClaims: jwt.StandardClaims{
+				IssuedAt:  time.Now().Unix(),
+				ExpiresAt: time.Now().Add(expiresIn).Unix(),
+			},
+		}
+	}
+
+	publicKeyDER, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+
+	test.Run("Static key", func(test *testing.T) {
+		keys, err := NewStaticKeySource(publicKeyPEM)
+		assert.NoError(test, err)
+
+		claims, err := New(keys).Verify(context.Background(), signToken(test, key, "", userClaims(time.Minute)))
+		assert.NoError(test, err)
+		assert.Equal(test, user, claims.User)
+	})
+
+	test.Run("Rejects invalid tokens", func(test *testing.T) {
+		keys, _ := NewStaticKeySource(publicKeyPEM)
+		verifier := New(keys)
+
+		_, err := verifier.Verify(context.Background(), signToken(test, key, "", userClaims(-time.Minute)))
+		assert.ErrorIs(test, err, ErrInvalidToken)
+
+		_, err = verifier.Verify(context.Background(), signToken(test, otherKey, "", userClaims(time.Minute)))
+		assert.ErrorIs(test, err, ErrInvalidToken)
+
+		_, err = verifier.Verify(context.Background(), "not a token")
+		assert.ErrorIs(test, err, ErrInvalidToken)
+
+		hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims(time.Minute)).SignedString(publicKeyPEM)
+		_, err = verifier.Verify(context.Background(), hmacToken)
+		assert.ErrorIs(test, err, ErrInvalidToken)
+	})
+
+	test.Run("Rejects tokens without user", func(test *testing.T) {
+		keys, _ := NewStaticKeySource(publicKeyPEM)
+
+		serviceToken := signToken(test, key, "", &jwt.StandardClaims{
+			Subject:   "client",
+			Audience:  "memorize-account",
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		})
+
+		_, err := New(keys).Verify(context.Background(), serviceToken)
+		assert.ErrorIs(test, err, ErrNoUser)
+	})
+
+	test.Run("JWKS", func(test *testing.T) {
+		server := newJWKSServer(map[string]*rsa.PublicKey{"first": &key.PublicKey})
+		defer server.Close()
+
+		keys, err := NewJWKSKeySource(server.URL, nil)
+		assert.NoError(test, err)
+		defer keys.Close()
+
+		verifier := New(keys)
+
+		for i := 0; i < 3; i++ {
+			claims, err := verifier.Verify(context.Background(), signToken(test, key, "first", userClaims(time.Minute)))
+			assert.NoError(test, err)
+			assert.Equal(test, user, claims.User)
+		}
+
+		// keys are cached
+		assert.Equal(test, 1, server.requestCount())
+	})
+
+	test.Run("JWKS picks up rotated key", func(test *testing.T) {
+		server := newJWKSServer(map[string]*rsa.PublicKey{"first": &key.PublicKey})
+		defer server.Close()
+
+		keys, err := NewJWKSKeySource(server.URL, &JWKSOptions{MinRefreshInterval: time.Nanosecond})
+		assert.NoError(test, err)
+		defer keys.Close()
+
+		server.setKeys(map[string]*rsa.PublicKey{"first": &key.PublicKey, "second": &otherKey.PublicKey})
+
+		claims, err := New(keys).Verify(context.Background(), signToken(test, otherKey, "second", userClaims(time.Minute)))
+		assert.NoError(test, err)
+		assert.Equal(test, user, claims.User)
+		assert.Equal(test, 2, server.requestCount())
+	})
+
+	test.Run("JWKS limits fetches for unknown keys", func(test *testing.T) {
+		server := newJWKSServer(map[string]*rsa.PublicKey{"first": &key.PublicKey})
+		defer server.Close()
+
+		keys, err := NewJWKSKeySource(server.URL, &JWKSOptions{MinRefreshInterval: time.Hour})
+		assert.NoError(test, err)
+		defer keys.Close()
+
+		for i := 0; i < 3; i++ {
+			_, err := New(keys).Verify(context.Background(), signToken(test, otherKey, "unknown", userClaims(time.Minute)))
+			assert.ErrorIs(test, err, ErrUnknownKey)
+		}
+
+		assert.Equal(test, 1, server.requestCount())
+	})
+
+	test.Run("JWKS refreshes in background", func(test *testing.T) {
+		server := newJWKSServer(map[string]*rsa.PublicKey{"first": &key.PublicKey})
+		defer server.Close()
+
+		keys, err := NewJWKSKeySource(server.URL, &JWKSOptions{RefreshInterval: 10 * time.Millisecond})
+		assert.NoError(test, err)
+
+		assert.Eventually(test, func() bool {
+			return server.requestCount() >= 3
+		}, time.Second, 10*time.Millisecond)
+
+		keys.Close()
+	})
+
+	test.Run("JWKS unavailable", func(test *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		keys, err := NewJWKSKeySource(server.URL, nil)
+		assert.Nil(test, keys)
+		assert.Error(test, err)
+	})
+
+	test.Run("Gin middleware", func(test *testing.T) {
+		keys, _ := NewStaticKeySource(publicKeyPEM)
+
+		router := gin.New()
+		router.GET("/me", New(keys).Gin(), func(ctx *gin.Context) {
+			ctx.JSON(http.StatusOK, ctx.MustGet("user"))
+		})
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/me", http.NoBody)
+		request.Header.Set("Authorization", "Bearer "+signToken(test, key, "", userClaims(time.Minute)))
+		router.ServeHTTP(recorder, request)
+
+		expectedBody, _ := json.Marshal(user)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Equal(test, expectedBody, recorder.Body.Bytes())
+
+		recorder = httptest.NewRecorder()
+		request, _ = http.NewRequest(http.MethodGet, "/me", http.NoBody)
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+	})
+
+	test.Run("net/http middleware", func(test *testing.T) {
+		keys, _ := NewStaticKeySource(publicKeyPEM)
+
+		handler := New(keys).HTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contextUser, ok := UserFromContext(r.Context())
+			assert.True(test, ok)
+			json.NewEncoder(w).Encode(contextUser)
+		}))
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/me", http.NoBody)
+		request.Header.Set("Authorization", "Bearer "+signToken(test, key, "", userClaims(time.Minute)))
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusOK, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), uid.String())
+
+		recorder = httptest.NewRecorder()
+		request, _ = http.NewRequest(http.MethodGet, "/me", http.NoBody)
+		request.Header.Set("Authorization", "Bearer "+signToken(test, otherKey, "", userClaims(time.Minute)))
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusUnauthorized, recorder.Code)
+		assert.Contains(test, recorder.Body.String(), `"type":"AUTHORIZATION"`)
+	})
+}
@@ -0,0 +1,146 @@
+// Package validation reports failed binding of requests as bad request errors
+// fields are named by their JSON path, so clients can point at them
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"memorize/i18n"
+	"memorize/models/apperrors"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Patterns of custom validators by tag, a string is valid if it matches
+var Patterns = map[string]*regexp.Regexp{
+	// letters and digits of any script, dot, dash and underscore
+	"login": regexp.MustCompile(`^[\p{L}\p{N}._-]+$`),
+}
+
+var setup sync.Once
+
+// InvalidArgument describes field that failed validation
+type InvalidArgument struct {
+	// JSON path of field, like "scopes[1]"
+	Field   string      `json:"field"`
+	Value   interface{} `json:"value"`
+	Tag     string      `json:"tag"`
+	Param   string      `json:"param"`
+	Message string      `json:"message"`
+}
+
+// Setup registers custom validators and naming of fields in the validator gin binds with
+// it must be called before requests are bound
+func Setup() {
+	setup.Do(func() {
+		engine, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			panic("validation: gin binding does not use go-playground validator")
+		}
+
+		engine.RegisterTagNameFunc(fieldName)
+
+		for tag, pattern := range Patterns {
+			if err := engine.RegisterValidation(tag, matches(pattern)); err != nil {
+				panic(fmt.Sprintf("validation: could not register %v: %v", tag, err))
+			}
+		}
+	})
+}
+
+func matches(pattern *regexp.Regexp) validator.Func {
+	return func(field validator.FieldLevel) bool {
+		return pattern.MatchString(field.Field().String())
+	}
+}
+
+// name of field in request, struct field name is used when it has no tag
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "header"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+
+		if name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// Error converts error of binding request into application error with messages in locale
+// errors that are not caused by request are internal
+func Error(err error, locale string) *apperrors.Error {
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		invalidArgs := make([]InvalidArgument, 0, len(validationErrs))
+
+		for _, fieldErr := range validationErrs {
+			field := path(fieldErr.Namespace())
+
+			invalidArgs = append(invalidArgs, InvalidArgument{
+				Field:   field,
+				Value:   fieldErr.Value(),
+				Tag:     fieldErr.Tag(),
+				Param:   fieldErr.Param(),
+				Message: i18n.ValidationMessage(locale, field, fieldErr.Tag(), fieldErr.Param()),
+			})
+		}
+
+		return invalid(invalidArgs)
+
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		param := typeErr.Type.String()
+
+		return invalid([]InvalidArgument{{
+			Field:   field,
+			Value:   typeErr.Value,
+			Tag:     "type",
+			Param:   param,
+			Message: i18n.ValidationMessage(locale, field, "type", param),
+		}})
+
+	case errors.As(err, &syntaxErr):
+		return apperrors.NewBadRequest("request body is not valid JSON").
+			Wrap(err).
+			WithCode(apperrors.MalformedJSON).
+			WithDetail("offset", syntaxErr.Offset)
+
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return apperrors.NewBadRequest("request body is not valid JSON").
+			Wrap(err).
+			WithCode(apperrors.MalformedJSON)
+
+	default:
+		return apperrors.NewInternal().Wrap(err)
+	}
+}
+
+func invalid(invalidArgs []InvalidArgument) *apperrors.Error {
+	return apperrors.NewBadRequest("Invalid request parameters. See details").
+		WithCode(apperrors.ValidationFailed).
+		WithDetail("invalidArgs", invalidArgs)
+}
+
+// namespace starts with name of request struct, it is not part of JSON path
+func path(namespace string) string {
+	if index := strings.Index(namespace, "."); index != -1 {
+		return namespace[index+1:]
+	}
+
+	return namespace
+}
@@ -0,0 +1,101 @@
+package validation
+
+import (
+	"errors"
+	"memorize/models/apperrors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `json:"city" binding:"required"`
+}
+
+type testRequest struct {
+	Login     string    `json:"login" binding:"required,login"`
+	Age       int       `json:"age" binding:"gte=18"`
+	Active    bool      `json:"active"`
+	Addresses []address `json:"addresses" binding:"dive"`
+}
+
+func bind(body string) error {
+	gin.SetMode(gin.TestMode)
+	Setup()
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	var request testRequest
+	return ctx.ShouldBindJSON(&request)
+}
+
+func invalidArgs(test *testing.T, err *apperrors.Error) []InvalidArgument {
+	assert.Equal(test, apperrors.ValidationFailed, err.Code)
+	assert.Equal(test, http.StatusBadRequest, err.Status())
+
+	return err.Details["invalidArgs"].([]InvalidArgument)
+}
+
+func TestError(test *testing.T) {
+	test.Run("Fields of any type by JSON path", func(test *testing.T) {
+		err := Error(bind(`{"login": "alice", "age": 17, "addresses": [{"city": "Berlin"}, {}]}`), "en")
+
+		args := invalidArgs(test, err)
+		assert.Len(test, args, 2)
+
+		assert.Equal(test, "age", args[0].Field)
+		assert.Equal(test, 17, args[0].Value)
+		assert.Equal(test, "gte", args[0].Tag)
+		assert.Equal(test, "18", args[0].Param)
+
+		assert.Equal(test, "addresses[1].city", args[1].Field)
+		assert.Equal(test, "addresses[1].city is required", args[1].Message)
+	})
+
+	test.Run("Login charset", func(test *testing.T) {
+		err := Error(bind(`{"login": "alice smith", "age": 20}`), "de")
+
+		args := invalidArgs(test, err)
+		assert.Equal(test, "login", args[0].Field)
+		assert.Equal(test, "login", args[0].Tag)
+		assert.Equal(test, "login darf nur Buchstaben, Ziffern, Punkte, Bindestriche und Unterstriche enthalten", args[0].Message)
+
+		assert.NoError(test, bind(`{"login": "jürgen.o-k_1", "age": 20}`))
+	})
+
+	test.Run("Type mismatch", func(test *testing.T) {
+		err := Error(bind(`{"login": "alice", "age": "twenty"}`), "en")
+
+		args := invalidArgs(test, err)
+		assert.Equal(test, "age", args[0].Field)
+		assert.Equal(test, "type", args[0].Tag)
+		assert.Equal(test, "int", args[0].Param)
+		assert.Equal(test, "age must be of type int", args[0].Message)
+	})
+
+	test.Run("Malformed JSON", func(test *testing.T) {
+		err := Error(bind(`{"login": "alice",}`), "en")
+
+		assert.Equal(test, apperrors.MalformedJSON, err.Code)
+		assert.Equal(test, http.StatusBadRequest, err.Status())
+		assert.Equal(test, int64(19), err.Details["offset"])
+	})
+
+	test.Run("Empty body", func(test *testing.T) {
+		err := Error(bind(``), "en")
+
+		assert.Equal(test, apperrors.MalformedJSON, err.Code)
+	})
+
+	test.Run("Other errors are internal", func(test *testing.T) {
+		err := Error(errors.New("read timeout"), "en")
+
+		assert.Equal(test, apperrors.Internal, err.Type)
+	})
+}
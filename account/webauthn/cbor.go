@@ -0,0 +1,161 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// CBOR major types used by authenticators
+const (
+	cborUnsigned = 0
+	cborNegative = 1
+	cborBytes    = 2
+	cborText     = 3
+	cborArray    = 4
+	cborMap      = 5
+	cborSimple   = 7
+)
+
+// deepest nesting accepted, attestation objects are only a few levels deep
+const cborMaxDepth = 16
+
+// decodeCBOR decodes one CBOR item and returns it with number of bytes read
+// Only definite lengths are supported, authenticators emit canonical CBOR.
+// Integers become int64, maps map[interface{}]interface{} with int64 or string keys.
+func decodeCBOR(data []byte) (interface{}, int, error) {
+	return decodeCBORItem(data, 0)
+}
+
+func decodeCBORItem(data []byte, depth int) (interface{}, int, error) {
+	if depth > cborMaxDepth {
+		return nil, 0, fmt.Errorf("cbor: nested too deep")
+	}
+
+	if len(data) == 0 {
+		return nil, 0, fmt.Errorf("cbor: unexpected end of data")
+	}
+
+	majorType := data[0] >> 5
+
+	if majorType == cborSimple {
+		switch data[0] & 0x1f {
+		case 20:
+			return false, 1, nil
+		case 21:
+			return true, 1, nil
+		case 22:
+			return nil, 1, nil
+		default:
+			return nil, 0, fmt.Errorf("cbor: unsupported simple value %v", data[0]&0x1f)
+		}
+	}
+
+	argument, offset, err := cborArgument(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch majorType {
+	case cborUnsigned:
+		if argument > 1<<63-1 {
+			return nil, 0, fmt.Errorf("cbor: integer overflow")
+		}
+		return int64(argument), offset, nil
+
+	case cborNegative:
+		if argument > 1<<63-1 {
+			return nil, 0, fmt.Errorf("cbor: integer overflow")
+		}
+		return -1 - int64(argument), offset, nil
+
+	case cborBytes, cborText:
+		if argument > uint64(len(data)-offset) {
+			return nil, 0, fmt.Errorf("cbor: unexpected end of data")
+		}
+
+		end := offset + int(argument)
+		if majorType == cborText {
+			return string(data[offset:end]), end, nil
+		}
+
+		value := make([]byte, argument)
+		copy(value, data[offset:end])
+
+		return value, end, nil
+
+	case cborArray:
+		if argument > uint64(len(data)) {
+			return nil, 0, fmt.Errorf("cbor: unexpected end of data")
+		}
+
+		items := make([]interface{}, 0, argument)
+
+		for i := uint64(0); i < argument; i++ {
+			item, n, err := decodeCBORItem(data[offset:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			items = append(items, item)
+			offset += n
+		}
+
+		return items, offset, nil
+
+	case cborMap:
+		if argument > uint64(len(data)) {
+			return nil, 0, fmt.Errorf("cbor: unexpected end of data")
+		}
+
+		items := make(map[interface{}]interface{}, argument)
+
+		for i := uint64(0); i < argument; i++ {
+			key, n, err := decodeCBORItem(data[offset:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			offset += n
+
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, 0, fmt.Errorf("cbor: unsupported map key %T", key)
+			}
+
+			value, n, err := decodeCBORItem(data[offset:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			offset += n
+
+			items[key] = value
+		}
+
+		return items, offset, nil
+
+	default:
+		return nil, 0, fmt.Errorf("cbor: unsupported major type %v", majorType)
+	}
+}
+
+// cborArgument reads the argument that follows initial byte
+func cborArgument(data []byte) (uint64, int, error) {
+	additional := data[0] & 0x1f
+
+	switch {
+	case additional < 24:
+		return uint64(additional), 1, nil
+	case additional == 24 && len(data) >= 2:
+		return uint64(data[1]), 2, nil
+	case additional == 25 && len(data) >= 3:
+		return uint64(binary.BigEndian.Uint16(data[1:3])), 3, nil
+	case additional == 26 && len(data) >= 5:
+		return uint64(binary.BigEndian.Uint32(data[1:5])), 5, nil
+	case additional == 27 && len(data) >= 9:
+		return binary.BigEndian.Uint64(data[1:9]), 9, nil
+	case additional > 27:
+		return 0, 0, fmt.Errorf("cbor: indefinite length is not supported")
+	default:
+		return 0, 0, fmt.Errorf("cbor: unexpected end of data")
+	}
+}
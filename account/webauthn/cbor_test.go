@@ -0,0 +1,84 @@
+package webauthn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCBOR(test *testing.T) {
+	test.Run("Scalars", func(test *testing.T) {
+		cases := []struct {
+			data     []byte
+			expected interface{}
+		}{
+			{[]byte{0x00}, int64(0)},
+			{[]byte{0x17}, int64(23)},
+			{[]byte{0x18, 0x18}, int64(24)},
+			{[]byte{0x19, 0x01, 0x00}, int64(256)},
+			{[]byte{0x20}, int64(-1)},
+			{[]byte{0x38, 0x18}, int64(-25)},
+			{[]byte{0x39, 0x01, 0x00}, int64(-257)},
+			{[]byte{0x43, 0x01, 0x02, 0x03}, []byte{0x01, 0x02, 0x03}},
+			{[]byte{0x64, 'n', 'o', 'n', 'e'}, "none"},
+			{[]byte{0xf4}, false},
+			{[]byte{0xf5}, true},
+		}
+
+		for _, c := range cases {
+			value, read, err := decodeCBOR(c.data)
+			assert.NoError(test, err)
+			assert.Equal(test, c.expected, value)
+			assert.Equal(test, len(c.data), read)
+		}
+	})
+
+	test.Run("Map with array", func(test *testing.T) {
+		// {"fmt": "none", 1: [2, -7]}
+		data := []byte{0xa2, 0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e', 0x01, 0x82, 0x02, 0x26}
+
+		value, read, err := decodeCBOR(data)
+		assert.NoError(test, err)
+		assert.Equal(test, len(data), read)
+		assert.Equal(test, map[interface{}]interface{}{
+			"fmt":    "none",
+			int64(1): []interface{}{int64(2), int64(-7)},
+		}, value)
+	})
+
+	test.Run("Trailing data is not read", func(test *testing.T) {
+		value, read, err := decodeCBOR([]byte{0x01, 0xff})
+		assert.NoError(test, err)
+		assert.Equal(test, int64(1), value)
+		assert.Equal(test, 1, read)
+	})
+
+	test.Run("Truncated", func(test *testing.T) {
+		for _, data := range [][]byte{
+			{},
+			{0x19, 0x01},
+			{0x45, 0x01, 0x02},
+			{0xa1, 0x01},
+			{0x82, 0x01},
+		} {
+			_, _, err := decodeCBOR(data)
+			assert.Error(test, err)
+		}
+	})
+
+	test.Run("Indefinite length", func(test *testing.T) {
+		_, _, err := decodeCBOR([]byte{0x5f, 0x41, 0x01, 0xff})
+		assert.Error(test, err)
+	})
+
+	test.Run("Nested too deep", func(test *testing.T) {
+		data := make([]byte, 0, 32)
+		for i := 0; i < 20; i++ {
+			data = append(data, 0x81)
+		}
+		data = append(data, 0x00)
+
+		_, _, err := decodeCBOR(data)
+		assert.Error(test, err)
+	})
+}
@@ -0,0 +1,108 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// COSE key parameters, RFC 8152
+const (
+	coseKeyType   = 1
+	coseAlgorithm = 3
+	coseCurve     = -1
+	coseX         = -2
+	coseY         = -3
+	coseModulus   = -1
+	coseExponent  = -2
+
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+	coseCurveP256  = 1
+)
+
+// parsePublicKey decodes COSE encoded public key
+func parsePublicKey(coseKey []byte) (crypto.PublicKey, error) {
+	decoded, n, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if n != len(coseKey) {
+		return nil, fmt.Errorf("trailing data after public key")
+	}
+
+	key, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("public key is not a map")
+	}
+
+	keyType, _ := key[int64(coseKeyType)].(int64)
+	algorithm, _ := key[int64(coseAlgorithm)].(int64)
+
+	switch {
+	case keyType == coseKeyTypeEC2 && algorithm == AlgorithmES256:
+		curve, _ := key[int64(coseCurve)].(int64)
+		x, _ := key[int64(coseX)].([]byte)
+		y, _ := key[int64(coseY)].([]byte)
+
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("invalid P-256 public key")
+		}
+
+		publicKey := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+
+		if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			return nil, fmt.Errorf("public key is not on curve")
+		}
+
+		return publicKey, nil
+
+	case keyType == coseKeyTypeRSA && algorithm == AlgorithmRS256:
+		modulus, _ := key[int64(coseModulus)].([]byte)
+		exponent, _ := key[int64(coseExponent)].([]byte)
+
+		if len(modulus) < 256 || len(exponent) == 0 || len(exponent) > 4 {
+			return nil, fmt.Errorf("invalid RSA public key")
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported public key type %v with algorithm %v", keyType, algorithm)
+	}
+}
+
+// verifySignature checks signature over data made with COSE encoded key
+func verifySignature(coseKey []byte, data []byte, signature []byte) error {
+	publicKey, err := parsePublicKey(coseKey)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(data)
+
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hash[:], signature) {
+			return fmt.Errorf("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+	}
+
+	return nil
+}
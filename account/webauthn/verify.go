@@ -0,0 +1,224 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// authenticator data flags
+const (
+	flagUserPresent          = 0x01
+	flagUserVerified         = 0x04
+	flagAttestedCredential   = 0x40
+	authenticatorDataMinSize = 37
+)
+
+// client data collected by browser
+type collectedClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+	publicKey    []byte
+}
+
+// ChallengeOf reads challenge from client data, so server can find the ceremony it started
+func ChallengeOf(clientDataJSON []byte) ([]byte, error) {
+	clientData := &collectedClientData{}
+	if err := json.Unmarshal(clientDataJSON, clientData); err != nil {
+		return nil, fmt.Errorf("invalid client data: %w", err)
+	}
+
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(clientData.Challenge, "="))
+}
+
+// VerifyRegistration checks response of navigator.credentials.create for challenge
+func (c *Config) VerifyRegistration(
+	response *AttestationResponse,
+	challenge []byte,
+	requireUserVerification bool,
+) (*Credential, error) {
+
+	if response.Type != "public-key" {
+		return nil, fmt.Errorf("unexpected credential type %v", response.Type)
+	}
+
+	if err := c.verifyClientData(response.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := decodeCBOR(response.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %w", err)
+	}
+
+	attestationObject, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("attestation object is not a map")
+	}
+
+	rawAuthData, ok := attestationObject["authData"].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("attestation object has no authenticator data")
+	}
+
+	authData, err := c.verifyAuthenticatorData(rawAuthData, requireUserVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	if authData.flags&flagAttestedCredential == 0 {
+		return nil, fmt.Errorf("authenticator data has no credential")
+	}
+
+	if len(response.RawID) != 0 && !bytes.Equal(response.RawID, authData.credentialID) {
+		return nil, fmt.Errorf("credential id does not match authenticator data")
+	}
+
+	if _, err := parsePublicKey(authData.publicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:        authData.credentialID,
+		PublicKey: authData.publicKey,
+		SignCount: authData.signCount,
+	}, nil
+}
+
+// VerifySignin checks response of navigator.credentials.get for challenge against stored credential
+// returns new signature counter to be stored
+func (c *Config) VerifySignin(
+	response *AssertionResponse,
+	challenge []byte,
+	publicKey []byte,
+	storedSignCount uint32,
+	requireUserVerification bool,
+) (uint32, error) {
+
+	if response.Type != "public-key" {
+		return 0, fmt.Errorf("unexpected credential type %v", response.Type)
+	}
+
+	if err := c.verifyClientData(response.Response.ClientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+
+	authData, err := c.verifyAuthenticatorData(response.Response.AuthenticatorData, requireUserVerification)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(response.Response.ClientDataJSON)
+	signed := append(append([]byte{}, response.Response.AuthenticatorData...), clientDataHash[:]...)
+
+	if err := verifySignature(publicKey, signed, response.Response.Signature); err != nil {
+		return 0, err
+	}
+
+	// authenticators without counter always send zero
+	if (authData.signCount != 0 || storedSignCount != 0) && authData.signCount <= storedSignCount {
+		return 0, fmt.Errorf("signature counter did not increase, credential may be cloned")
+	}
+
+	return authData.signCount, nil
+}
+
+func (c *Config) verifyClientData(clientDataJSON []byte, ceremony string, challenge []byte) error {
+	clientData := &collectedClientData{}
+	if err := json.Unmarshal(clientDataJSON, clientData); err != nil {
+		return fmt.Errorf("invalid client data: %w", err)
+	}
+
+	if clientData.Type != ceremony {
+		return fmt.Errorf("unexpected client data type %v", clientData.Type)
+	}
+
+	received, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(clientData.Challenge, "="))
+	if err != nil || subtle.ConstantTimeCompare(received, challenge) != 1 {
+		return fmt.Errorf("challenge does not match")
+	}
+
+	for _, origin := range c.Origins {
+		if clientData.Origin == origin {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unexpected origin %v", clientData.Origin)
+}
+
+func (c *Config) verifyAuthenticatorData(data []byte, requireUserVerification bool) (*authenticatorData, error) {
+	authData, err := parseAuthenticatorData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rpIDHash := sha256.Sum256([]byte(c.RPID))
+	if !bytes.Equal(authData.rpIDHash, rpIDHash[:]) {
+		return nil, fmt.Errorf("relying party id does not match")
+	}
+
+	if authData.flags&flagUserPresent == 0 {
+		return nil, fmt.Errorf("user was not present")
+	}
+
+	if requireUserVerification && authData.flags&flagUserVerified == 0 {
+		return nil, fmt.Errorf("user was not verified")
+	}
+
+	return authData, nil
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < authenticatorDataMinSize {
+		return nil, fmt.Errorf("authenticator data is too short")
+	}
+
+	authData := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	if authData.flags&flagAttestedCredential == 0 {
+		return authData, nil
+	}
+
+	// aaguid, credential id length, credential id and COSE key follow
+	rest := data[authenticatorDataMinSize:]
+	if len(rest) < 18 {
+		return nil, fmt.Errorf("attested credential data is too short")
+	}
+
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+
+	if len(rest) < idLength {
+		return nil, fmt.Errorf("attested credential data is too short")
+	}
+
+	authData.credentialID = rest[:idLength]
+	rest = rest[idLength:]
+
+	_, keyLength, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential public key: %w", err)
+	}
+
+	authData.publicKey = rest[:keyLength]
+
+	return authData, nil
+}
@@ -0,0 +1,204 @@
+// Package webauthn implements the relying party side of WebAuthn ceremonies
+// Attestation statements are not verified, options always ask for "none" attestation.
+package webauthn
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// COSE algorithms this relying party accepts
+const (
+	AlgorithmES256 = -7
+	AlgorithmRS256 = -257
+)
+
+// user verification requirements
+const (
+	UserVerificationRequired    = "required"
+	UserVerificationPreferred   = "preferred"
+	UserVerificationDiscouraged = "discouraged"
+)
+
+// Config describes the relying party
+type Config struct {
+	RPID    string
+	RPName  string
+	Origins []string
+	Timeout time.Duration
+}
+
+// URLEncodedBytes is binary value encoded as base64url in JSON, as browsers expect
+type URLEncodedBytes []byte
+
+func (b URLEncodedBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *URLEncodedBytes) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+	return nil
+}
+
+// String is base64url form used as credential id
+func (b URLEncodedBytes) String() string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntity struct {
+	ID          URLEncodedBytes `json:"id"`
+	Name        string          `json:"name"`
+	DisplayName string          `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type      string `json:"type"`
+	Algorithm int    `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type string          `json:"type"`
+	ID   URLEncodedBytes `json:"id"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+type PublicKeyCredentialCreationOptions struct {
+	Challenge              URLEncodedBytes        `json:"challenge"`
+	RelyingParty           RelyingParty           `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// CreationOptions are passed to navigator.credentials.create
+type CreationOptions struct {
+	PublicKey PublicKeyCredentialCreationOptions `json:"publicKey"`
+}
+
+type PublicKeyCredentialRequestOptions struct {
+	Challenge        URLEncodedBytes        `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RequestOptions are passed to navigator.credentials.get
+type RequestOptions struct {
+	PublicKey PublicKeyCredentialRequestOptions `json:"publicKey"`
+}
+
+type AuthenticatorAttestationResponse struct {
+	ClientDataJSON    URLEncodedBytes `json:"clientDataJSON"`
+	AttestationObject URLEncodedBytes `json:"attestationObject"`
+}
+
+// AttestationResponse is the credential returned by navigator.credentials.create
+type AttestationResponse struct {
+	ID       string                           `json:"id"`
+	RawID    URLEncodedBytes                  `json:"rawId"`
+	Type     string                           `json:"type"`
+	Response AuthenticatorAttestationResponse `json:"response"`
+}
+
+type AuthenticatorAssertionResponse struct {
+	ClientDataJSON    URLEncodedBytes `json:"clientDataJSON"`
+	AuthenticatorData URLEncodedBytes `json:"authenticatorData"`
+	Signature         URLEncodedBytes `json:"signature"`
+	UserHandle        URLEncodedBytes `json:"userHandle"`
+}
+
+// AssertionResponse is the credential returned by navigator.credentials.get
+type AssertionResponse struct {
+	ID       string                         `json:"id"`
+	RawID    URLEncodedBytes                `json:"rawId"`
+	Type     string                         `json:"type"`
+	Response AuthenticatorAssertionResponse `json:"response"`
+}
+
+// Credential is a verified public key credential to be stored for the user
+type Credential struct {
+	ID        []byte
+	PublicKey []byte
+	SignCount uint32
+}
+
+// NewChallenge returns random challenge for a ceremony
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// NewCreationOptions builds registration options, exclude lists credentials user already has
+func (c *Config) NewCreationOptions(challenge []byte, user UserEntity, exclude [][]byte) *CreationOptions {
+	return &CreationOptions{
+		PublicKey: PublicKeyCredentialCreationOptions{
+			Challenge:    challenge,
+			RelyingParty: RelyingParty{ID: c.RPID, Name: c.RPName},
+			User:         user,
+			PubKeyCredParams: []CredentialParameter{
+				{Type: "public-key", Algorithm: AlgorithmES256},
+				{Type: "public-key", Algorithm: AlgorithmRS256},
+			},
+			Timeout:            c.Timeout.Milliseconds(),
+			ExcludeCredentials: descriptors(exclude),
+			AuthenticatorSelection: AuthenticatorSelection{
+				ResidentKey:      "preferred",
+				UserVerification: UserVerificationPreferred,
+			},
+			Attestation: "none",
+		},
+	}
+}
+
+// NewRequestOptions builds signin options, empty allow lets authenticator offer discoverable credentials
+func (c *Config) NewRequestOptions(challenge []byte, allow [][]byte, userVerification string) *RequestOptions {
+	return &RequestOptions{
+		PublicKey: PublicKeyCredentialRequestOptions{
+			Challenge:        challenge,
+			Timeout:          c.Timeout.Milliseconds(),
+			RPID:             c.RPID,
+			AllowCredentials: descriptors(allow),
+			UserVerification: userVerification,
+		},
+	}
+}
+
+func descriptors(ids [][]byte) []CredentialDescriptor {
+	result := make([]CredentialDescriptor, 0, len(ids))
+
+	for _, id := range ids {
+		result = append(result, CredentialDescriptor{Type: "public-key", ID: id})
+	}
+
+	return result
+}